@@ -0,0 +1,501 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JWTConfig JWT imzalama anahtarlarının nereden yükleneceğini ve nasıl döndürüleceğini belirler.
+// Anahtar verilmezse her süreç kendi geçici anahtarını üretir ve JWKS'i farklı olur; bu yalnızca tek
+// örnekli kurulumlarda çalışır. Birden fazla örnek çalışıyorsa Instances ayarlanmalı ve tüm örneklere
+// aynı KeysDir (döndürme dizindeki dosyalarla yapılır) veya aynı PrivateKeyPEM verilmelidir.
+type JWTConfig struct {
+	Algorithm        string        // "RS256" veya "EdDSA"
+	KeysDir          string        // PEM formatında özel anahtarların bulunduğu dizin (dosya adı = kid)
+	PrivateKeyPEM    string        // Tek bir anahtarı ortam değişkeninden vermek için
+	RotationInterval time.Duration // 0 ise otomatik döndürme kapalı
+	MaxActiveKeys    int           // Aynı anda imza doğrulamada kullanılan anahtar sayısı
+	MaxTokenTTL      time.Duration // Emekliye ayrılan anahtar bu süre boyunca doğrulamada kalır
+	Issuer           string
+	Instances        int // Aynı anahtarlarla token imzalayan auth-service örneği sayısı
+}
+
+// BruteForceConfig giriş, aktivasyon ve şifre sıfırlama denemelerinin sınırlarını belirler
+type BruteForceConfig struct {
+	FailureWindow      time.Duration // Başarısız denemelerin sayıldığı kayan pencere
+	MaxAccountFailures int           // Bu sayıya ulaşan hesap geçici olarak kilitlenir
+	MaxIPFailures      int           // Bu sayıya ulaşan IP pencere boyunca engellenir
+	LockoutDuration    time.Duration
+	DelayAfterFailures int // Bu sayıdan sonra her denemede bekleme süresi ikiye katlanır
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+}
+
+// PasswordResetConfig şifre sıfırlama bağlantılarının adresini ve geçerlilik süresini belirler
+type PasswordResetConfig struct {
+	BaseURL  string // Tokenin "token" sorgu parametresi olarak ekleneceği sayfa adresi
+	TokenTTL time.Duration
+}
+
+// PasswordPolicyConfig kayıt, sıfırlama ve şifre değiştirmede uygulanan şifre kurallarını belirler
+type PasswordPolicyConfig struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	MinStrengthScore int  // 0 (çok zayıf) ile 4 (çok güçlü) arası
+	DisallowUserInfo bool // Şifre kullanıcı adını veya e-postayı içeremez
+	// SHA-1 özet listesi dosyası ("SHA1:SAYI" satırları) veya HIBP biçiminde 5 karakterlik önek dosyalarını içeren dizin
+	BreachedPasswordsPath string
+}
+
+// MagicLinkConfig e-posta ile gönderilen şifresiz giriş bağlantılarının ve kodlarının ayarlarını belirler
+type MagicLinkConfig struct {
+	BaseURL        string // Tokenin "token" sorgu parametresi olarak ekleneceği sayfa adresi
+	TokenTTL       time.Duration
+	ResendCooldown time.Duration // Aynı e-posta için iki istek arasında beklenecek süre
+	MaxAttempts    int           // Bu sayıda hatalı koddan sonra istek geçersiz olur
+}
+
+// WebAuthnConfig passkey kayıt ve girişinde kullanılan bağlı taraf (relying party) ayarlarını belirler
+type WebAuthnConfig struct {
+	RPID         string   // Passkeylerin bağlı olduğu alan adı; ön yüzün alan adı veya onun üst alan adı olmalı
+	RPName       string   // Kimlik doğrulayıcının kullanıcıya gösterdiği ad
+	Origins      []string // clientDataJSON içinde kabul edilen kökenler
+	ChallengeTTL time.Duration
+	// "required", "preferred" veya "discouraged"; doğrulama yapılmayan girişlerde 2FA ayrıca istenir
+	UserVerification string
+}
+
+// APITokenConfig kişisel erişim tokenlerinin geçerlilik sürelerini ve sayısını sınırlar
+type APITokenConfig struct {
+	DefaultTTL   time.Duration // Süre belirtilmezse kullanılır
+	MaxTTL       time.Duration
+	MaxPerUser   int
+	SyncInterval time.Duration // Redis önbelleğinin veritabanıyla eşitlenme sıklığı
+}
+
+// PresenceConfig websocket bağlantılarının çevrimiçi durumunu belirleyen kalp atışı sürelerini tutar
+type PresenceConfig struct {
+	HeartbeatInterval time.Duration // Sunucunun ping gönderme sıklığı
+	ConnectionTTL     time.Duration // Bu süre boyunca kalp atışı gelmeyen bağlantı düşmüş sayılır
+	CleanupInterval   time.Duration // Çökmüş servis örneklerinden kalan bağlantıların temizlenme sıklığı
+	// Kullanıcının durumunu takip edebileceği kişileri (sohbet arkadaşları) döner; "%s" kullanıcı ID'siyle değiştirilir
+	AudienceURL      string
+	AudienceCacheTTL time.Duration
+	MaxSubscriptions int // Bir bağlantının takip edebileceği en fazla kullanıcı sayısı
+}
+
+// ImpersonationConfig destek ekibinin kullanıcı adına açtığı oturumların süresini belirler
+type ImpersonationConfig struct {
+	DefaultTTL time.Duration // İstekte süre verilmezse kullanılır
+	MaxTTL     time.Duration
+}
+
+// AccountDeletionConfig kullanıcının kendi hesabını silme sürecinin sürelerini belirler
+type AccountDeletionConfig struct {
+	GracePeriod   time.Duration // Kullanıcı bu süre içinde hesabını geri alabilir
+	SweepInterval time.Duration // Bekleme süresi dolan hesapların aranma sıklığı
+	// Bu süre içinde tüm servisler onay vermezse "user_deleted" mesajı yeniden gönderilir
+	AckTimeout time.Duration
+	// Verisini sildiğini onaylaması beklenen servisler; hepsi onaylayınca silme tamamlanmış sayılır
+	RequiredServices []string
+}
+
+// DataExportConfig kullanıcının kişisel verilerini dışa aktarma isteklerinin ayarlarını belirler
+type DataExportConfig struct {
+	BaseURL     string        // Tokenin "token" sorgu parametresi olarak ekleneceği indirme adresi
+	LinkTTL     time.Duration // Arşiv bu süre sonunda silinir ve bağlantı geçersiz olur
+	PartTimeout time.Duration // Bu süre içinde tüm servisler verisini göndermezse istek başarısız olur
+	MinInterval time.Duration // Aynı kullanıcının iki isteği arasında beklenecek süre
+	// Verisini göndermesi beklenen servisler; auth-service kendi parçasını istek sırasında ekler
+	RequiredServices []string
+	SweepInterval    time.Duration // Zaman aşımına uğrayan isteklerin ve süresi dolan arşivlerin temizlenme sıklığı
+}
+
+// LoginHistoryConfig giriş geçmişinin ve yeni cihaz uyarılarının ayarlarını belirler
+type LoginHistoryConfig struct {
+	AlertBaseURL     string        // "Bu ben değilim" tokeninin "token" sorgu parametresi olarak ekleneceği sayfa adresi
+	HistoryRetention time.Duration // Giriş kayıtları bu süre sonunda silinir
+	DeviceRetention  time.Duration // Bu süre boyunca giriş yapılmayan cihaz tekrar yeni cihaz sayılır
+	DeviceCookieTTL  time.Duration // Tarayıcıyı tanımlayan device_id çerezinin süresi
+	AlertsEnabled    bool
+}
+
+// Kayıt modları: herkes kayıt olabilir, yalnızca davetle kayıt olunabilir veya yeni kayıt alınmaz
+const (
+	RegistrationOpen       = "open"
+	RegistrationInviteOnly = "invite"
+	RegistrationClosed     = "closed"
+)
+
+// RegistrationConfig yeni hesap oluşturma kurallarını ve davetlerin ayarlarını belirler
+type RegistrationConfig struct {
+	Mode             string // RegistrationOpen, RegistrationInviteOnly veya RegistrationClosed
+	InviteBaseURL    string // Davet kodunun "code" sorgu parametresi olarak ekleneceği kayıt sayfası
+	DefaultInviteTTL time.Duration
+	MaxInviteTTL     time.Duration
+	// Yönetici olmayan kullanıcılar da davet oluşturabilir; bu davetler varsayılan rolle ve sınırlı kullanımla oluşturulur
+	UsersCanInvite    bool
+	UserInviteMaxUses int // Kullanıcı davetlerinin en fazla kullanım sayısı
+	MaxInvitesPerUser int // Kullanıcının aynı anda geçerli olabilecek davet sayısı
+}
+
+// InternalConfig yalnızca diğer servislerin çağırdığı iç endpointlerin ayarlarını tutar
+type InternalConfig struct {
+	// Servislerin iç isteklerde gönderdiği ortak gizli anahtar; boşsa iç endpointler kapalıdır
+	Secret string
+}
+
+// OAuthConfig yerleşik OAuth2 / OpenID Connect sunucusunun token sürelerini belirler
+type OAuthConfig struct {
+	AccessTokenTTL       time.Duration
+	IDTokenTTL           time.Duration
+	RefreshTokenTTL      time.Duration
+	AuthorizationCodeTTL time.Duration
+	ConsentTTL           time.Duration // Onay ekranının cevaplanması için verilen süre
+	// Oturumu olmayan kullanıcıların yönlendirileceği giriş sayfası; dönüş adresi "return_to" parametresiyle eklenir
+	LoginURL string
+}
+
+// OIDCProviderConfig "Google ile giriş" gibi harici bir OpenID Connect sağlayıcısını tanımlar
+type OIDCProviderConfig struct {
+	Name         string // URL'de kullanılan kısa ad (ör. "google")
+	DisplayName  string
+	Issuer       string // Keşif belgesi Issuer + "/.well-known/openid-configuration" adresinden alınır
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// FederationConfig harici kimlik sağlayıcılarıyla girişin ayarlarını belirler
+type FederationConfig struct {
+	Providers []OIDCProviderConfig
+	// Sağlayıcıya bildirilen dönüş adresi: CallbackBaseURL + "/{provider}/callback"
+	CallbackBaseURL string
+	// Giriş tamamlandıktan sonra return_to verilmemişse yönlendirilecek sayfa; return_to da bu adresin kökenine sınırlıdır
+	SuccessURL string
+	StateTTL   time.Duration
+}
+
+// Config auth servisinin çalışma zamanı ayarlarını tutar
+type Config struct {
+	JWT             JWTConfig
+	BruteForce      BruteForceConfig
+	PasswordReset   PasswordResetConfig
+	PasswordPolicy  PasswordPolicyConfig
+	MagicLink       MagicLinkConfig
+	WebAuthn        WebAuthnConfig
+	APIToken        APITokenConfig
+	Presence        PresenceConfig
+	Impersonation   ImpersonationConfig
+	AccountDeletion AccountDeletionConfig
+	DataExport      DataExportConfig
+	LoginHistory    LoginHistoryConfig
+	Registration    RegistrationConfig
+	Internal        InternalConfig
+	OAuth           OAuthConfig
+	Federation      FederationConfig
+}
+
+// NewDefaultConfig varsayılan değerlerle bir Config oluşturur
+func NewDefaultConfig() Config {
+	return Config{
+		JWT: JWTConfig{
+			Algorithm:        "RS256",
+			RotationInterval: 30 * 24 * time.Hour,
+			MaxActiveKeys:    2,
+			MaxTokenTTL:      24 * time.Hour,
+			Issuer:           "http://localhost:8080",
+			Instances:        1,
+		},
+		BruteForce: BruteForceConfig{
+			FailureWindow:      15 * time.Minute,
+			MaxAccountFailures: 5,
+			MaxIPFailures:      50,
+			LockoutDuration:    15 * time.Minute,
+			DelayAfterFailures: 3,
+			BaseDelay:          1 * time.Second,
+			MaxDelay:           30 * time.Second,
+		},
+		PasswordReset: PasswordResetConfig{
+			BaseURL:  "http://localhost:8000/resetPassword",
+			TokenTTL: 1 * time.Hour,
+		},
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:        8,
+			RequireUppercase: true,
+			RequireLowercase: true,
+			RequireDigit:     true,
+			MinStrengthScore: 2,
+			DisallowUserInfo: true,
+		},
+		MagicLink: MagicLinkConfig{
+			BaseURL:        "http://localhost:8000/magicLogin",
+			TokenTTL:       10 * time.Minute,
+			ResendCooldown: 1 * time.Minute,
+			MaxAttempts:    5,
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:             "localhost",
+			RPName:           "GoMicroservice",
+			Origins:          []string{"http://localhost:8000"},
+			ChallengeTTL:     5 * time.Minute,
+			UserVerification: "preferred",
+		},
+		APIToken: APITokenConfig{
+			DefaultTTL:   90 * 24 * time.Hour,
+			MaxTTL:       365 * 24 * time.Hour,
+			MaxPerUser:   50,
+			SyncInterval: 5 * time.Minute,
+		},
+		Presence: PresenceConfig{
+			HeartbeatInterval: 25 * time.Second,
+			ConnectionTTL:     60 * time.Second,
+			CleanupInterval:   30 * time.Second,
+			AudienceURL:       "http://localhost:8083/internal/users/%s/coparticipants",
+			AudienceCacheTTL:  30 * time.Second,
+			MaxSubscriptions:  500,
+		},
+		Impersonation: ImpersonationConfig{
+			DefaultTTL: 15 * time.Minute,
+			MaxTTL:     2 * time.Hour,
+		},
+		AccountDeletion: AccountDeletionConfig{
+			GracePeriod:      30 * 24 * time.Hour,
+			SweepInterval:    10 * time.Minute,
+			AckTimeout:       1 * time.Hour,
+			RequiredServices: []string{"user", "chat"},
+		},
+		DataExport: DataExportConfig{
+			BaseURL:          "http://localhost:8080/auth/export/download",
+			LinkTTL:          48 * time.Hour,
+			PartTimeout:      30 * time.Minute,
+			MinInterval:      24 * time.Hour,
+			RequiredServices: []string{"user", "chat"},
+			SweepInterval:    5 * time.Minute,
+		},
+		LoginHistory: LoginHistoryConfig{
+			AlertBaseURL:     "http://localhost:8000/loginAlert",
+			HistoryRetention: 90 * 24 * time.Hour,
+			DeviceRetention:  180 * 24 * time.Hour,
+			DeviceCookieTTL:  365 * 24 * time.Hour,
+			AlertsEnabled:    true,
+		},
+		Registration: RegistrationConfig{
+			Mode:              RegistrationOpen,
+			InviteBaseURL:     "http://localhost:8000/signUp",
+			DefaultInviteTTL:  7 * 24 * time.Hour,
+			MaxInviteTTL:      30 * 24 * time.Hour,
+			UsersCanInvite:    false,
+			UserInviteMaxUses: 1,
+			MaxInvitesPerUser: 10,
+		},
+		OAuth: OAuthConfig{
+			AccessTokenTTL:       15 * time.Minute,
+			IDTokenTTL:           1 * time.Hour,
+			RefreshTokenTTL:      30 * 24 * time.Hour,
+			AuthorizationCodeTTL: 1 * time.Minute,
+			ConsentTTL:           10 * time.Minute,
+			LoginURL:             "http://localhost:8000/login",
+		},
+		Federation: FederationConfig{
+			CallbackBaseURL: "http://localhost:8080/auth/federated",
+			SuccessURL:      "http://localhost:8000/",
+			StateTTL:        10 * time.Minute,
+		},
+	}
+}
+
+// Load varsayılan ayarları ortam değişkenleriyle ezerek döner
+func Load() Config {
+	cfg := NewDefaultConfig()
+
+	cfg.JWT.Algorithm = getEnv("JWT_SIGNING_ALG", cfg.JWT.Algorithm)
+	cfg.JWT.KeysDir = getEnv("JWT_KEYS_DIR", cfg.JWT.KeysDir)
+	cfg.JWT.PrivateKeyPEM = getEnv("JWT_PRIVATE_KEY", cfg.JWT.PrivateKeyPEM)
+	cfg.JWT.RotationInterval = getEnvDuration("JWT_KEY_ROTATION_INTERVAL", cfg.JWT.RotationInterval)
+	cfg.JWT.MaxActiveKeys = getEnvInt("JWT_MAX_ACTIVE_KEYS", cfg.JWT.MaxActiveKeys)
+	cfg.JWT.MaxTokenTTL = getEnvDuration("JWT_MAX_TOKEN_TTL", cfg.JWT.MaxTokenTTL)
+	cfg.JWT.Issuer = getEnv("JWT_ISSUER", cfg.JWT.Issuer)
+	cfg.JWT.Instances = getEnvInt("AUTH_SERVICE_INSTANCES", cfg.JWT.Instances)
+
+	cfg.BruteForce.FailureWindow = getEnvDuration("BRUTE_FORCE_WINDOW", cfg.BruteForce.FailureWindow)
+	cfg.BruteForce.MaxAccountFailures = getEnvInt("BRUTE_FORCE_MAX_ACCOUNT_FAILURES", cfg.BruteForce.MaxAccountFailures)
+	cfg.BruteForce.MaxIPFailures = getEnvInt("BRUTE_FORCE_MAX_IP_FAILURES", cfg.BruteForce.MaxIPFailures)
+	cfg.BruteForce.LockoutDuration = getEnvDuration("BRUTE_FORCE_LOCKOUT_DURATION", cfg.BruteForce.LockoutDuration)
+	cfg.BruteForce.DelayAfterFailures = getEnvInt("BRUTE_FORCE_DELAY_AFTER", cfg.BruteForce.DelayAfterFailures)
+	cfg.BruteForce.BaseDelay = getEnvDuration("BRUTE_FORCE_BASE_DELAY", cfg.BruteForce.BaseDelay)
+	cfg.BruteForce.MaxDelay = getEnvDuration("BRUTE_FORCE_MAX_DELAY", cfg.BruteForce.MaxDelay)
+
+	cfg.PasswordReset.BaseURL = getEnv("PASSWORD_RESET_BASE_URL", cfg.PasswordReset.BaseURL)
+	cfg.PasswordReset.TokenTTL = getEnvDuration("PASSWORD_RESET_TOKEN_TTL", cfg.PasswordReset.TokenTTL)
+
+	cfg.PasswordPolicy.MinLength = getEnvInt("PASSWORD_MIN_LENGTH", cfg.PasswordPolicy.MinLength)
+	cfg.PasswordPolicy.RequireUppercase = getEnvBool("PASSWORD_REQUIRE_UPPERCASE", cfg.PasswordPolicy.RequireUppercase)
+	cfg.PasswordPolicy.RequireLowercase = getEnvBool("PASSWORD_REQUIRE_LOWERCASE", cfg.PasswordPolicy.RequireLowercase)
+	cfg.PasswordPolicy.RequireDigit = getEnvBool("PASSWORD_REQUIRE_DIGIT", cfg.PasswordPolicy.RequireDigit)
+	cfg.PasswordPolicy.RequireSymbol = getEnvBool("PASSWORD_REQUIRE_SYMBOL", cfg.PasswordPolicy.RequireSymbol)
+	cfg.PasswordPolicy.MinStrengthScore = getEnvInt("PASSWORD_MIN_STRENGTH", cfg.PasswordPolicy.MinStrengthScore)
+	cfg.PasswordPolicy.DisallowUserInfo = getEnvBool("PASSWORD_DISALLOW_USER_INFO", cfg.PasswordPolicy.DisallowUserInfo)
+	cfg.PasswordPolicy.BreachedPasswordsPath = getEnv("PASSWORD_BREACHED_LIST", cfg.PasswordPolicy.BreachedPasswordsPath)
+
+	cfg.MagicLink.BaseURL = getEnv("MAGIC_LINK_BASE_URL", cfg.MagicLink.BaseURL)
+	cfg.MagicLink.TokenTTL = getEnvDuration("MAGIC_LINK_TTL", cfg.MagicLink.TokenTTL)
+	cfg.MagicLink.ResendCooldown = getEnvDuration("MAGIC_LINK_RESEND_COOLDOWN", cfg.MagicLink.ResendCooldown)
+	cfg.MagicLink.MaxAttempts = getEnvInt("MAGIC_LINK_MAX_ATTEMPTS", cfg.MagicLink.MaxAttempts)
+
+	cfg.WebAuthn.RPID = getEnv("WEBAUTHN_RP_ID", cfg.WebAuthn.RPID)
+	cfg.WebAuthn.RPName = getEnv("WEBAUTHN_RP_NAME", cfg.WebAuthn.RPName)
+	if origins := getEnv("WEBAUTHN_ORIGINS", ""); origins != "" {
+		cfg.WebAuthn.Origins = strings.Fields(strings.ReplaceAll(origins, ",", " "))
+	}
+	cfg.WebAuthn.ChallengeTTL = getEnvDuration("WEBAUTHN_CHALLENGE_TTL", cfg.WebAuthn.ChallengeTTL)
+	cfg.WebAuthn.UserVerification = getEnv("WEBAUTHN_USER_VERIFICATION", cfg.WebAuthn.UserVerification)
+
+	cfg.APIToken.DefaultTTL = getEnvDuration("API_TOKEN_DEFAULT_TTL", cfg.APIToken.DefaultTTL)
+	cfg.APIToken.MaxTTL = getEnvDuration("API_TOKEN_MAX_TTL", cfg.APIToken.MaxTTL)
+	cfg.APIToken.MaxPerUser = getEnvInt("API_TOKEN_MAX_PER_USER", cfg.APIToken.MaxPerUser)
+	cfg.APIToken.SyncInterval = getEnvDuration("API_TOKEN_SYNC_INTERVAL", cfg.APIToken.SyncInterval)
+
+	cfg.Presence.HeartbeatInterval = getEnvDuration("PRESENCE_HEARTBEAT_INTERVAL", cfg.Presence.HeartbeatInterval)
+	cfg.Presence.ConnectionTTL = getEnvDuration("PRESENCE_CONNECTION_TTL", cfg.Presence.ConnectionTTL)
+	cfg.Presence.CleanupInterval = getEnvDuration("PRESENCE_CLEANUP_INTERVAL", cfg.Presence.CleanupInterval)
+	cfg.Presence.AudienceURL = getEnv("PRESENCE_AUDIENCE_URL", cfg.Presence.AudienceURL)
+	cfg.Presence.AudienceCacheTTL = getEnvDuration("PRESENCE_AUDIENCE_CACHE_TTL", cfg.Presence.AudienceCacheTTL)
+	cfg.Presence.MaxSubscriptions = getEnvInt("PRESENCE_MAX_SUBSCRIPTIONS", cfg.Presence.MaxSubscriptions)
+
+	cfg.Impersonation.DefaultTTL = getEnvDuration("IMPERSONATION_DEFAULT_TTL", cfg.Impersonation.DefaultTTL)
+	cfg.Impersonation.MaxTTL = getEnvDuration("IMPERSONATION_MAX_TTL", cfg.Impersonation.MaxTTL)
+
+	cfg.AccountDeletion.GracePeriod = getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", cfg.AccountDeletion.GracePeriod)
+	cfg.AccountDeletion.SweepInterval = getEnvDuration("ACCOUNT_DELETION_SWEEP_INTERVAL", cfg.AccountDeletion.SweepInterval)
+	cfg.AccountDeletion.AckTimeout = getEnvDuration("ACCOUNT_DELETION_ACK_TIMEOUT", cfg.AccountDeletion.AckTimeout)
+	if services := getEnv("ACCOUNT_DELETION_SERVICES", ""); services != "" {
+		cfg.AccountDeletion.RequiredServices = strings.Fields(strings.ReplaceAll(services, ",", " "))
+	}
+
+	cfg.DataExport.BaseURL = getEnv("DATA_EXPORT_BASE_URL", cfg.DataExport.BaseURL)
+	cfg.DataExport.LinkTTL = getEnvDuration("DATA_EXPORT_LINK_TTL", cfg.DataExport.LinkTTL)
+	cfg.DataExport.PartTimeout = getEnvDuration("DATA_EXPORT_PART_TIMEOUT", cfg.DataExport.PartTimeout)
+	cfg.DataExport.MinInterval = getEnvDuration("DATA_EXPORT_MIN_INTERVAL", cfg.DataExport.MinInterval)
+	if services := getEnv("DATA_EXPORT_SERVICES", ""); services != "" {
+		cfg.DataExport.RequiredServices = strings.Fields(strings.ReplaceAll(services, ",", " "))
+	}
+	cfg.DataExport.SweepInterval = getEnvDuration("DATA_EXPORT_SWEEP_INTERVAL", cfg.DataExport.SweepInterval)
+
+	cfg.LoginHistory.AlertBaseURL = getEnv("LOGIN_ALERT_BASE_URL", cfg.LoginHistory.AlertBaseURL)
+	cfg.LoginHistory.HistoryRetention = getEnvDuration("LOGIN_HISTORY_RETENTION", cfg.LoginHistory.HistoryRetention)
+	cfg.LoginHistory.DeviceRetention = getEnvDuration("LOGIN_DEVICE_RETENTION", cfg.LoginHistory.DeviceRetention)
+	cfg.LoginHistory.DeviceCookieTTL = getEnvDuration("LOGIN_DEVICE_COOKIE_TTL", cfg.LoginHistory.DeviceCookieTTL)
+	cfg.LoginHistory.AlertsEnabled = getEnvBool("LOGIN_ALERTS_ENABLED", cfg.LoginHistory.AlertsEnabled)
+
+	cfg.Registration.Mode = strings.ToLower(getEnv("REGISTRATION_MODE", cfg.Registration.Mode))
+	switch cfg.Registration.Mode {
+	case RegistrationOpen, RegistrationInviteOnly, RegistrationClosed:
+	default:
+		// Yanlış yazılmış bir mod kayıtları açık bırakmamalı
+		log.Printf("Geçersiz REGISTRATION_MODE değeri %q, kayıtlar kapatıldı", cfg.Registration.Mode)
+		cfg.Registration.Mode = RegistrationClosed
+	}
+	cfg.Registration.InviteBaseURL = getEnv("INVITE_BASE_URL", cfg.Registration.InviteBaseURL)
+	cfg.Registration.DefaultInviteTTL = getEnvDuration("INVITE_DEFAULT_TTL", cfg.Registration.DefaultInviteTTL)
+	cfg.Registration.MaxInviteTTL = getEnvDuration("INVITE_MAX_TTL", cfg.Registration.MaxInviteTTL)
+	cfg.Registration.UsersCanInvite = getEnvBool("USERS_CAN_INVITE", cfg.Registration.UsersCanInvite)
+	cfg.Registration.UserInviteMaxUses = getEnvInt("USER_INVITE_MAX_USES", cfg.Registration.UserInviteMaxUses)
+	cfg.Registration.MaxInvitesPerUser = getEnvInt("MAX_INVITES_PER_USER", cfg.Registration.MaxInvitesPerUser)
+
+	cfg.Internal.Secret = getEnv("INTERNAL_API_SECRET", cfg.Internal.Secret)
+
+	cfg.OAuth.AccessTokenTTL = getEnvDuration("OAUTH_ACCESS_TOKEN_TTL", cfg.OAuth.AccessTokenTTL)
+	cfg.OAuth.IDTokenTTL = getEnvDuration("OAUTH_ID_TOKEN_TTL", cfg.OAuth.IDTokenTTL)
+	cfg.OAuth.RefreshTokenTTL = getEnvDuration("OAUTH_REFRESH_TOKEN_TTL", cfg.OAuth.RefreshTokenTTL)
+	cfg.OAuth.AuthorizationCodeTTL = getEnvDuration("OAUTH_CODE_TTL", cfg.OAuth.AuthorizationCodeTTL)
+	cfg.OAuth.ConsentTTL = getEnvDuration("OAUTH_CONSENT_TTL", cfg.OAuth.ConsentTTL)
+	cfg.OAuth.LoginURL = getEnv("OAUTH_LOGIN_URL", cfg.OAuth.LoginURL)
+
+	cfg.Federation.CallbackBaseURL = getEnv("OIDC_CALLBACK_BASE_URL", cfg.Federation.CallbackBaseURL)
+	cfg.Federation.SuccessURL = getEnv("OIDC_SUCCESS_URL", cfg.Federation.SuccessURL)
+	cfg.Federation.StateTTL = getEnvDuration("OIDC_STATE_TTL", cfg.Federation.StateTTL)
+	cfg.Federation.Providers = loadOIDCProviders()
+
+	return cfg
+}
+
+// loadOIDCProviders OIDC_PROVIDERS="google,corp" listesindeki her sağlayıcıyı
+// OIDC_<AD>_ISSUER, OIDC_<AD>_CLIENT_ID, OIDC_<AD>_CLIENT_SECRET, OIDC_<AD>_SCOPES ve
+// OIDC_<AD>_DISPLAY_NAME değişkenlerinden okur
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			DisplayName:  getEnv(prefix+"DISPLAY_NAME", name),
+			Issuer:       strings.TrimSuffix(getEnv(prefix+"ISSUER", ""), "/"),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			log.Printf("%s sağlayıcısı için issuer veya client ID eksik, atlanıyor", name)
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("%s geçersiz, varsayılan değer kullanılıyor: %v", key, err)
+		return fallback
+	}
+	return parsed
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("%s geçersiz, varsayılan değer kullanılıyor: %v", key, err)
+		return fallback
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("%s geçersiz, varsayılan değer kullanılıyor: %v", key, err)
+		return fallback
+	}
+	return parsed
+}
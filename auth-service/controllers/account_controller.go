@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+)
+
+type AccountController struct {
+	accountService  *services.AccountService
+	deletionService *services.AccountDeletionService
+	rabbitMQ        *messaging.RabbitMQ
+	sessionRepo     *redisrepo.RedisRepository
+}
+
+func NewAccountController(rabbitMQ *messaging.RabbitMQ, sessionRepo *redisrepo.RedisRepository, passwordPolicy *services.PasswordPolicy, deletionService *services.AccountDeletionService) *AccountController {
+	return &AccountController{
+		accountService:  services.NewAccountService(passwordPolicy),
+		deletionService: deletionService,
+		rabbitMQ:        rabbitMQ,
+		sessionRepo:     sessionRepo,
+	}
+}
+
+func accountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidPassword),
+		errors.Is(err, services.ErrInvalidEmailChangeCode):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrEmailChangeAttemptsExceed):
+		return http.StatusTooManyRequests
+	case errors.Is(err, services.ErrEmailInUse),
+		errors.Is(err, services.ErrDeletionAlreadyScheduled):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrPendingEmailChangeNotFound),
+		errors.Is(err, services.ErrDeletionNotScheduled):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrSamePassword),
+		errors.Is(err, services.ErrSameEmail):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary      Şifre Değiştir
+// @Description  Mevcut şifre doğrulandıktan sonra şifreyi değiştirir, istenirse diğer oturumları kapatır
+// @Tags         Account
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ChangePasswordDto true "Şifre değiştirme modeli"
+// @Success      200  {object}  LogoutResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/password [post]
+func (ctrl *AccountController) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var input dto.ChangePasswordDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	if err := ctrl.accountService.ChangePassword(userData["id"], input.CurrentPassword, input.NewPassword); err != nil {
+		if policyErr, ok := services.IsPasswordPolicyError(err); ok {
+			respondWithPasswordPolicy(w, policyErr)
+			return
+		}
+		status := accountErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("Şifre değiştirilemedi:", err)
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	revoked := 0
+	if input.SignOutOtherSessions {
+		var err error
+		revoked, err = ctrl.sessionRepo.RevokeUserSessions(userData["id"], userData["session_id"])
+		if err != nil {
+			log.Println("Diğer oturumlar kapatılamadı:", err)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message":         "Şifreniz başarıyla değiştirildi",
+		"revokedSessions": revoked,
+	})
+}
+
+// @Summary      E-posta Değiştir
+// @Description  Yeni e-posta adresine doğrulama kodu gönderir; adres kod onaylanana kadar değişmez
+// @Tags         Account
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ChangeEmailDto true "E-posta değiştirme modeli"
+// @Success      200  {object}  LogoutResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Router       /auth/email [post]
+func (ctrl *AccountController) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	var input dto.ChangeEmailDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	if err := validate.Var(input.NewEmail, "required,email"); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz e-posta adresi")
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	code, user, err := ctrl.accountService.RequestEmailChange(userData["id"], input.NewEmail, input.Password)
+	if err != nil {
+		status := accountErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("E-posta değişikliği başlatılamadı:", err)
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	// Kod yalnızca yeni adrese gönderilir, böylece adresin sahibi olduğu kanıtlanır
+	emailMessage := messaging.Message{
+		Type:      "verify_email_change",
+		ToService: messaging.EmailService,
+		Data: map[string]interface{}{
+			"email":           input.NewEmail,
+			"activation_code": code,
+			"template_name":   "email_change.html",
+			"userName":        user.Username,
+		},
+	}
+	if err := ctrl.rabbitMQ.PublishMessage(context.Background(), emailMessage); err != nil {
+		log.Printf("E-posta doğrulama mesajı gönderilemedi: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Doğrulama e-postası gönderilemedi")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Doğrulama kodu yeni e-posta adresinize gönderildi",
+	})
+}
+
+// @Summary      E-posta Değişikliğini Onayla
+// @Description  Yeni adrese gönderilen kodu doğrular ve e-posta adresini günceller
+// @Tags         Account
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ConfirmEmailChangeDto true "Doğrulama kodu"
+// @Success      200  {object}  ActivationResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/email/confirm [post]
+func (ctrl *AccountController) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var input dto.ConfirmEmailChangeDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	user, oldEmail, err := ctrl.accountService.ConfirmEmailChange(userData["id"], input.Code)
+	if err != nil {
+		status := accountErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("E-posta değiştirilemedi:", err)
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	// Açık oturumlarda eski adres kalmasın
+	if err := ctrl.sessionRepo.UpdateUserSessions(user.ID.Hex(), map[string]string{"email": user.Email}); err != nil {
+		log.Println("Oturum verileri güncellenemedi:", err)
+	}
+	if err := ctrl.sessionRepo.UpdateUserAPITokens(user.ID.Hex(), map[string]string{"email": user.Email}); err != nil {
+		log.Println("API token verileri güncellenemedi:", err)
+	}
+
+	// user-service ve chat-service'teki kullanıcı kopyaları ile eski adrese bildirim için tüm servislere yayınlanır
+	emailChangedMessage := messaging.Message{
+		Type: "user_email_changed",
+		Data: map[string]interface{}{
+			"user_id":       user.ID.Hex(),
+			"email":         user.Email,
+			"old_email":     oldEmail,
+			"userName":      user.Username,
+			"template_name": "email_changed.html",
+			"updatedAt":     user.UpdatedAt,
+		},
+	}
+	if err := ctrl.rabbitMQ.PublishMessage(context.Background(), emailChangedMessage); err != nil {
+		log.Printf("E-posta değişikliği mesajı gönderilemedi: %v", err)
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "E-posta adresiniz güncellendi",
+		"user":    dto.NewUserResponse(user),
+	})
+}
+
+// @Summary      Hesabı Sil
+// @Description  Şifre doğrulandıktan sonra hesabı bekleme süresi sonunda silinmek üzere planlar ve tüm oturumları kapatır; kullanıcı bu süre içinde tekrar giriş yapıp hesabını geri alabilir
+// @Tags         Account
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.DeleteAccountDto true "Şifre"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      401  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Router       /auth/account [delete]
+func (ctrl *AccountController) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	var input dto.DeleteAccountDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Password == "" {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	user, err := ctrl.deletionService.Schedule(userData["id"], input.Password)
+	if err != nil {
+		status := accountErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("Hesap silme isteği kaydedilemedi:", err)
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	emailMessage := messaging.Message{
+		Type:      "account_deletion_scheduled",
+		ToService: messaging.EmailService,
+		Data: map[string]interface{}{
+			"email":         user.Email,
+			"userName":      user.Username,
+			"template_name": "account_deletion_scheduled.html",
+			"scheduledFor":  user.Deletion.ScheduledFor,
+		},
+	}
+	if err := ctrl.rabbitMQ.PublishMessage(context.Background(), emailMessage); err != nil {
+		log.Printf("Hesap silme bildirimi gönderilemedi: %v", err)
+	}
+
+	// Tüm oturumlar kapatıldığı için bu oturumun çerezi de silinir
+	setSessionCookie(w, "", -time.Second)
+	respondWithJSON(w, http.StatusAccepted, map[string]interface{}{
+		"message":      "Hesabınız silinmek üzere planlandı, bu tarihe kadar giriş yaparak geri alabilirsiniz",
+		"scheduledFor": user.Deletion.ScheduledFor,
+	})
+}
+
+// @Summary      Hesabı Geri Al
+// @Description  Bekleme süresindeki hesap silme isteğini iptal eder
+// @Tags         Account
+// @Produce      json
+// @Success      200  {object}  LogoutResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/account/restore [post]
+func (ctrl *AccountController) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	if err := ctrl.deletionService.Restore(userData["id"]); err != nil {
+		status := accountErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("Hesap silme isteği iptal edilemedi:", err)
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Hesap silme isteğiniz iptal edildi",
+	})
+}
@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminController struct {
+	authorizer       *middlewares.Authorizer
+	loginGuard       *services.LoginGuard
+	userAdminService *services.UserAdminService
+	rabbitMQ         *messaging.RabbitMQ
+}
+
+func NewAdminController(authorizer *middlewares.Authorizer, loginGuard *services.LoginGuard, userAdminService *services.UserAdminService, rabbitMQ *messaging.RabbitMQ) *AdminController {
+	return &AdminController{
+		authorizer:       authorizer,
+		loginGuard:       loginGuard,
+		userAdminService: userAdminService,
+		rabbitMQ:         rabbitMQ,
+	}
+}
+
+func userAdminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrCannotModifySelf),
+		errors.Is(err, services.ErrNotRestricted),
+		errors.Is(err, services.ErrUnknownRole):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func respondWithUserAdminError(w http.ResponseWriter, err error) {
+	status := userAdminErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		log.Println("Kullanıcı yönetimi hatası:", err)
+		respondWithError(w, status, "İşlem tamamlanamadı")
+		return
+	}
+	respondWithError(w, status, err.Error())
+}
+
+// publishUserEvent yöneticinin hesap üzerindeki işlemini tüm servislere yayınlar
+// (ör. chat-service yasaklanan kullanıcının mesajlarını gizler)
+func (ctrl *AdminController) publishUserEvent(eventType, userID string, r *http.Request, data map[string]interface{}) {
+	data["user_id"] = userID
+	if userData, ok := middlewares.GetUserData(r); ok {
+		data["performed_by"] = userData["id"]
+	}
+	message := messaging.Message{
+		Type: eventType,
+		Data: data,
+	}
+	if err := ctrl.rabbitMQ.PublishMessage(context.Background(), message); err != nil {
+		log.Printf("%s mesajı gönderilemedi: %v", eventType, err)
+	}
+}
+
+// @Summary      Rol Yetkileri
+// @Description  Geçerli rol-yetki eşlemesini döner
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {object}  map[string][]string
+// @Failure      403  {object}  ErrorResponse
+// @Router       /auth/admin/permissions [get]
+func (ctrl *AdminController) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, ctrl.authorizer.RolePermissions())
+}
+
+// @Summary      Rol Yetkilerini Yeniden Yükle
+// @Description  Rol-yetki eşlemesini yapılandırma dosyasından hemen yeniden okur
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {object}  LogoutResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /auth/admin/permissions/reload [post]
+func (ctrl *AdminController) ReloadPermissions(w http.ResponseWriter, r *http.Request) {
+	if err := ctrl.authorizer.Reload(); err != nil {
+		log.Println("Yetki dosyası yeniden yüklenemedi:", err)
+		respondWithError(w, http.StatusInternalServerError, "Yetki dosyası yüklenemedi")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Yetkiler yeniden yüklendi",
+	})
+}
+
+// @Summary      Hesap Kilidini Kaldır
+// @Description  Hatalı denemeler nedeniyle kilitlenen hesabın kilidini ve deneme sayaçlarını kaldırır
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.UnlockAccountDto true "Kilidi kaldırılacak hesap"
+// @Success      200  {object}  LogoutResponse
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/admin/users/unlock [post]
+func (ctrl *AdminController) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	var input dto.UnlockAccountDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Email == "" {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+
+	if err := ctrl.loginGuard.Unlock(input.Email); err != nil {
+		log.Println("Hesap kilidi kaldırılamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "Hesap kilidi kaldırılamadı")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Hesap kilidi kaldırıldı",
+	})
+}
+
+// @Summary      Kullanıcı Ara
+// @Description  Kullanıcıları ad, e-posta veya kullanıcı adına, role ve hesap durumuna göre sayfalayarak listeler
+// @Tags         Admin
+// @Produce      json
+// @Param        q       query  string  false  "Arama metni"
+// @Param        role    query  string  false  "Rol"
+// @Param        status  query  string  false  "Hesap durumu (active, suspended, banned, deleted)"
+// @Param        page    query  int     false  "Sayfa (varsayılan 1)"
+// @Param        limit   query  int     false  "Sayfa başına kullanıcı (varsayılan 20, en fazla 100)"
+// @Success      200  {object}  dto.AdminUserListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/admin/users [get]
+func (ctrl *AdminController) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	input := dto.AdminUserSearchDto{
+		Query:  query.Get("q"),
+		Role:   query.Get("role"),
+		Status: query.Get("status"),
+	}
+	for name, target := range map[string]*int{"page": &input.Page, "limit": &input.Limit} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "Geçersiz sayfalama değeri")
+				return
+			}
+			*target = parsed
+		}
+	}
+	input.SetDefaults()
+	if err := validate.Struct(input); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := ctrl.userAdminService.SearchUsers(&input)
+	if err != nil {
+		respondWithUserAdminError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// @Summary      Kullanıcı Detayı
+// @Description  Kullanıcının hesap bilgilerini, kısıtlamasını ve aktif oturumlarını döner
+// @Tags         Admin
+// @Produce      json
+// @Param        userID  path  string  true  "Kullanıcı ID"
+// @Success      200  {object}  dto.AdminUserDetailResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/users/{userID} [get]
+func (ctrl *AdminController) GetUser(w http.ResponseWriter, r *http.Request) {
+	response, err := ctrl.userAdminService.GetUser(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithUserAdminError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// @Summary      Hesabı Askıya Al veya Yasakla
+// @Description  Hesabı gerekçe ve isteğe bağlı süreyle askıya alır veya yasaklar; kullanıcının tüm oturumları sonlandırılır
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        userID  path  string  true  "Kullanıcı ID"
+// @Param        request body dto.RestrictUserDto true "Kısıtlama türü, gerekçesi ve süresi"
+// @Success      200  {object}  dto.AdminUserResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/users/{userID}/restriction [post]
+func (ctrl *AdminController) RestrictUser(w http.ResponseWriter, r *http.Request) {
+	var input dto.RestrictUserDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	if err := validate.Struct(input); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+	user, err := ctrl.userAdminService.RestrictUser(userData["id"], userID, &input)
+	if err != nil {
+		respondWithUserAdminError(w, err)
+		return
+	}
+
+	ctrl.publishUserEvent("user_"+string(input.Status), userID, r, map[string]interface{}{
+		"restriction": user.Restriction,
+	})
+	respondWithJSON(w, http.StatusOK, dto.NewAdminUserResponse(user))
+}
+
+// @Summary      Hesap Kısıtlamasını Kaldır
+// @Description  Askıya alınmış veya yasaklanmış hesabı yeniden etkinleştirir
+// @Tags         Admin
+// @Produce      json
+// @Param        userID  path  string  true  "Kullanıcı ID"
+// @Success      200  {object}  dto.AdminUserResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/users/{userID}/restriction [delete]
+func (ctrl *AdminController) LiftRestriction(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	user, previous, err := ctrl.userAdminService.LiftRestriction(userID)
+	if err != nil {
+		respondWithUserAdminError(w, err)
+		return
+	}
+
+	ctrl.publishUserEvent("user_reinstated", userID, r, map[string]interface{}{
+		"previous_status": previous.Status,
+	})
+	respondWithJSON(w, http.StatusOK, dto.NewAdminUserResponse(user))
+}
+
+// @Summary      Kullanıcı Rollerini Değiştir
+// @Description  Kullanıcının rollerini verilen rollerle değiştirir; açık oturumlar ve tokenler hemen güncellenir
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        userID  path  string  true  "Kullanıcı ID"
+// @Param        request body dto.UpdateUserRolesDto true "Yeni roller"
+// @Success      200  {object}  dto.AdminUserResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/users/{userID}/roles [put]
+func (ctrl *AdminController) UpdateUserRoles(w http.ResponseWriter, r *http.Request) {
+	var input dto.UpdateUserRolesDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	if err := validate.Struct(input); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+	user, err := ctrl.userAdminService.UpdateRoles(userData["id"], userID, input.Roles, ctrl.authorizer.RolePermissions())
+	event := audit.Event{
+		Type:       audit.RoleChanged,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+		Details:    map[string]interface{}{"roles": input.Roles},
+	}
+	if err != nil {
+		event.Result = audit.ResultFailure
+		event.Reason = err.Error()
+	}
+	audit.Record(r, event)
+	if err != nil {
+		respondWithUserAdminError(w, err)
+		return
+	}
+
+	ctrl.publishUserEvent("user_roles_changed", userID, r, map[string]interface{}{
+		"roles": user.Roles,
+	})
+	respondWithJSON(w, http.StatusOK, dto.NewAdminUserResponse(user))
+}
+
+// @Summary      Kullanıcının Oturumlarını Sonlandır
+// @Description  Kullanıcının tüm cihazlardaki oturumlarını sonlandırır
+// @Tags         Admin
+// @Produce      json
+// @Param        userID  path  string  true  "Kullanıcı ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/users/{userID}/logout [post]
+func (ctrl *AdminController) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userID")
+	revoked, err := ctrl.userAdminService.ForceLogout(userID)
+	if err != nil {
+		respondWithUserAdminError(w, err)
+		return
+	}
+	audit.Record(r, audit.Event{
+		Type:       audit.SessionRevoked,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+		Details:    map[string]interface{}{"scope": "all", "revoked": revoked},
+	})
+
+	ctrl.publishUserEvent("user_sessions_revoked", userID, r, map[string]interface{}{})
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Kullanıcının oturumları sonlandırıldı",
+		"revoked": revoked,
+	})
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// APITokenCreatedResponse düz metin tokeni içerir; token daha sonra tekrar gösterilmez
+type APITokenCreatedResponse struct {
+	Token    string           `json:"token"`
+	APIToken *models.APIToken `json:"apiToken"`
+}
+
+type APITokenListResponse struct {
+	Tokens []models.APIToken `json:"tokens"`
+}
+
+type APITokenController struct {
+	apiTokenService *services.APITokenService
+}
+
+func NewAPITokenController(apiTokenService *services.APITokenService) *APITokenController {
+	return &APITokenController{
+		apiTokenService: apiTokenService,
+	}
+}
+
+func apiTokenErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidTokenScope),
+		errors.Is(err, services.ErrAPITokenTTLTooLong):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrAPITokenLimit):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrAPITokenNotFound),
+		errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (ctrl *APITokenController) createToken(w http.ResponseWriter, r *http.Request, userID, createdBy string) {
+	var input dto.CreateAPITokenDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	if err := validate.Struct(input); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, plainToken, err := ctrl.apiTokenService.CreateToken(userID, createdBy, &input)
+	if err != nil {
+		status := apiTokenErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("API tokeni oluşturulamadı:", err)
+			respondWithError(w, status, "Token oluşturulamadı")
+			return
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, APITokenCreatedResponse{Token: plainToken, APIToken: token})
+}
+
+func (ctrl *APITokenController) listTokens(w http.ResponseWriter, userID string) {
+	tokens, err := ctrl.apiTokenService.ListTokens(userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Println("API tokenleri alınamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "Tokenler alınamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, APITokenListResponse{Tokens: tokens})
+}
+
+func (ctrl *APITokenController) revokeToken(w http.ResponseWriter, tokenID, ownerID string) {
+	if err := ctrl.apiTokenService.RevokeToken(tokenID, ownerID); err != nil {
+		if errors.Is(err, services.ErrAPITokenNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Println("API tokeni iptal edilemedi:", err)
+		respondWithError(w, http.StatusInternalServerError, "Token iptal edilemedi")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Token iptal edildi",
+	})
+}
+
+// @Summary      API Tokeni Oluştur
+// @Description  Script ve botlar için "Authorization: Bearer" ile kullanılacak kişisel erişim tokeni oluşturur; token yalnızca bu yanıtta gösterilir
+// @Tags         API Tokens
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateAPITokenDto true "Token adı, kapsamları ve süresi"
+// @Success      201  {object}  APITokenCreatedResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Router       /auth/tokens [post]
+func (ctrl *APITokenController) CreateToken(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+	ctrl.createToken(w, r, userData["id"], userData["id"])
+}
+
+// @Summary      API Tokenlerini Listele
+// @Description  Kullanıcının etkin tokenlerini son kullanım zamanlarıyla döner
+// @Tags         API Tokens
+// @Produce      json
+// @Success      200  {object}  APITokenListResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/tokens [get]
+func (ctrl *APITokenController) ListTokens(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+	ctrl.listTokens(w, userData["id"])
+}
+
+// @Summary      API Tokenini İptal Et
+// @Description  Kullanıcının tokenini iptal eder; token bir sonraki istekte reddedilir
+// @Tags         API Tokens
+// @Produce      json
+// @Param        tokenID path string true "Token ID"
+// @Success      200  {object}  LogoutResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/tokens/{tokenID} [delete]
+func (ctrl *APITokenController) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+	ctrl.revokeToken(w, chi.URLParam(r, "tokenID"), userData["id"])
+}
+
+// @Summary      Kullanıcı İçin API Tokeni Oluştur
+// @Description  Servis hesabı gibi bir kullanıcı adına token oluşturur
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        userID  path  string  true  "Kullanıcı ID"
+// @Param        request body dto.CreateAPITokenDto true "Token adı, kapsamları ve süresi"
+// @Success      201  {object}  APITokenCreatedResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/users/{userID}/tokens [post]
+func (ctrl *APITokenController) AdminCreateToken(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+	ctrl.createToken(w, r, chi.URLParam(r, "userID"), userData["id"])
+}
+
+// @Summary      Kullanıcının API Tokenleri
+// @Description  Verilen kullanıcının etkin tokenlerini döner
+// @Tags         Admin
+// @Produce      json
+// @Param        userID  path  string  true  "Kullanıcı ID"
+// @Success      200  {object}  APITokenListResponse
+// @Failure      403  {object}  ErrorResponse
+// @Router       /auth/admin/users/{userID}/tokens [get]
+func (ctrl *APITokenController) AdminListTokens(w http.ResponseWriter, r *http.Request) {
+	ctrl.listTokens(w, chi.URLParam(r, "userID"))
+}
+
+// @Summary      API Tokenini İptal Et (Yönetici)
+// @Description  Herhangi bir kullanıcının tokenini iptal eder
+// @Tags         Admin
+// @Produce      json
+// @Param        tokenID path string true "Token ID"
+// @Success      200  {object}  LogoutResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/tokens/{tokenID} [delete]
+func (ctrl *APITokenController) AdminRevokeToken(w http.ResponseWriter, r *http.Request) {
+	ctrl.revokeToken(w, chi.URLParam(r, "tokenID"), "")
+}
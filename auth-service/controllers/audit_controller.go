@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditEventListResponse struct {
+	Events []audit.Event `json:"events"`
+	// NextBefore sonraki sayfa için before parametresine verilecek değerdir; son sayfada boştur
+	NextBefore string `json:"nextBefore,omitempty"`
+}
+
+type AuditController struct {
+	store *audit.Store
+}
+
+func NewAuditController(store *audit.Store) *AuditController {
+	return &AuditController{store: store}
+}
+
+// parseAuditFilter sorgu parametrelerini denetim kaydı filtresine çevirir
+func parseAuditFilter(r *http.Request, maxLimit int64) (audit.Filter, error) {
+	query := r.URL.Query()
+	filter := audit.Filter{
+		ActorID:  query.Get("actor"),
+		TargetID: query.Get("target"),
+		Result:   audit.Result(query.Get("result")),
+		Service:  query.Get("service"),
+	}
+	if filter.Result != "" && filter.Result != audit.ResultSuccess && filter.Result != audit.ResultFailure {
+		return filter, errors.New("Geçersiz result değeri")
+	}
+	for _, value := range query["type"] {
+		for _, eventType := range strings.Split(value, ",") {
+			if eventType = strings.TrimSpace(eventType); eventType != "" {
+				filter.Types = append(filter.Types, audit.EventType(eventType))
+			}
+		}
+	}
+
+	var err error
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("Geçersiz from değeri, RFC3339 biçiminde olmalı")
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("Geçersiz to değeri, RFC3339 biçiminde olmalı")
+		}
+	}
+	if value := query.Get("before"); value != "" {
+		if filter.Before, err = primitive.ObjectIDFromHex(value); err != nil {
+			return filter, errors.New("Geçersiz before değeri")
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || filter.Limit < 1 || (maxLimit > 0 && filter.Limit > maxLimit) {
+			return filter, errors.New("Geçersiz limit değeri")
+		}
+	}
+	return filter, nil
+}
+
+// @Summary      Denetim Kayıtları
+// @Description  Güvenlik denetim kayıtlarını en yeniden başlayarak işlemi yapana, hedefe, türe, sonuca ve zaman aralığına göre filtreler
+// @Tags         Admin
+// @Produce      json
+// @Param        actor    query  string  false  "İşlemi yapan kullanıcı ID"
+// @Param        target   query  string  false  "Hedef ID (kullanıcı, oturum, sohbet veya e-posta)"
+// @Param        type     query  string  false  "Kayıt türleri, virgülle ayrılmış (örn. auth.sign_in,auth.role_changed)"
+// @Param        result   query  string  false  "success veya failure"
+// @Param        service  query  string  false  "Kaydı yazan servis (auth, chat)"
+// @Param        from     query  string  false  "Başlangıç zamanı (RFC3339)"
+// @Param        to       query  string  false  "Bitiş zamanı (RFC3339, hariç)"
+// @Param        before   query  string  false  "Sayfalama için önceki yanıttaki nextBefore değeri"
+// @Param        limit    query  int     false  "En fazla kayıt (varsayılan 100, en fazla 1000)"
+// @Success      200  {object}  AuditEventListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/admin/audit [get]
+func (ctrl *AuditController) ListEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r, audit.MaxLimit)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = audit.DefaultLimit
+	}
+
+	events, err := ctrl.store.Find(filter)
+	if err != nil {
+		log.Println("Denetim kayıtları okunamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "Denetim kayıtları alınamadı")
+		return
+	}
+
+	response := AuditEventListResponse{Events: events}
+	if int64(len(events)) == filter.Limit {
+		response.NextBefore = events[len(events)-1].ID.Hex()
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// @Summary      Denetim Kayıtlarını Dışa Aktar
+// @Description  Filtreye uyan denetim kayıtlarını eskiden yeniye JSON Lines (her satırda bir kayıt) dosyası olarak indirir; limit verilmezse tüm kayıtlar yazılır
+// @Tags         Admin
+// @Produce      application/x-ndjson
+// @Param        actor    query  string  false  "İşlemi yapan kullanıcı ID"
+// @Param        target   query  string  false  "Hedef ID"
+// @Param        type     query  string  false  "Kayıt türleri, virgülle ayrılmış"
+// @Param        result   query  string  false  "success veya failure"
+// @Param        service  query  string  false  "Kaydı yazan servis"
+// @Param        from     query  string  false  "Başlangıç zamanı (RFC3339)"
+// @Param        to       query  string  false  "Bitiş zamanı (RFC3339, hariç)"
+// @Param        limit    query  int     false  "En fazla kayıt"
+// @Success      200
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/admin/audit/export [get]
+func (ctrl *AuditController) ExportEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r, 0)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-`+time.Now().UTC().Format("20060102T150405Z")+`.jsonl"`)
+	w.WriteHeader(http.StatusOK)
+
+	// Yanıt başladıktan sonra oluşan hatalar istemciye bildirilemez, yalnızca loglanır
+	count, err := ctrl.store.Export(r.Context(), filter, w)
+	if err != nil {
+		log.Printf("Denetim kayıtları dışa aktarılamadı (%d kayıt yazıldı): %v", count, err)
+	}
+}
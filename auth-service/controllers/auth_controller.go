@@ -3,19 +3,24 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
 	_ "github.com/MKMuhammetKaradag/go-microservice/auth-service/docs"
 	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
 	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/authclient"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
-	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -35,7 +40,7 @@ type SignUpResponse struct {
 }
 type ActivationResponse struct {
 	Message string `json:"message"`
-	user    string `json:"user"`
+	User    string `json:"user"`
 }
 
 type LogoutResponse struct {
@@ -47,16 +52,22 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 type AuthController struct {
-	authService *services.AuthService
-	rabbitMQ    *messaging.RabbitMQ
-	sessionRepo *redisrepo.RedisRepository
+	authService      *services.AuthService
+	twoFactorService *services.TwoFactorService
+	loginGuard       *services.LoginGuard
+	rabbitMQ         *messaging.RabbitMQ
+	sessionRepo      *redisrepo.RedisRepository
+	loginHistory     *services.LoginHistoryService
 }
 
-func NewAuthController(rabbitMQ *messaging.RabbitMQ, sessionRepo *redisrepo.RedisRepository) *AuthController {
+func NewAuthController(rabbitMQ *messaging.RabbitMQ, sessionRepo *redisrepo.RedisRepository, loginHistory *services.LoginHistoryService, loginGuard *services.LoginGuard, passwordPolicy *services.PasswordPolicy, inviteService *services.InviteService, cfg config.Config) *AuthController {
 	return &AuthController{
-		authService: services.NewAuthService(),
-		rabbitMQ:    rabbitMQ,
-		sessionRepo: sessionRepo,
+		authService:      services.NewAuthService(cfg, passwordPolicy, inviteService),
+		twoFactorService: services.NewTwoFactorService(),
+		loginGuard:       loginGuard,
+		rabbitMQ:         rabbitMQ,
+		sessionRepo:      sessionRepo,
+		loginHistory:     loginHistory,
 	}
 }
 
@@ -77,23 +88,113 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	json.NewEncoder(w).Encode(payload)
 }
 
+// respondWithPasswordPolicy şifrenin ihlal ettiği tüm kuralları liste halinde döner
+func respondWithPasswordPolicy(w http.ResponseWriter, policyErr *services.PasswordPolicyError) {
+	respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error":      "Şifre, şifre politikasına uygun değil",
+		"violations": policyErr.Violations,
+	})
+}
+
+// respondWithAttemptLimit deneme sınırı hatasını Retry-After başlığıyla döner
+func respondWithAttemptLimit(w http.ResponseWriter, err error) {
+	var limitErr *services.AttemptLimitError
+	if !errors.As(err, &limitErr) {
+		respondWithError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+
+	if limitErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limitErr.RetryAfter.Seconds()))))
+	}
+	status := http.StatusTooManyRequests
+	if limitErr.Locked {
+		status = http.StatusLocked
+	}
+	respondWithError(w, status, limitErr.Message)
+}
+
+// registerFailure başarısız denemeyi kaydeder ve hesap kilitlendiyse sahibine bildirim gönderir
+func (ctrl *AuthController) registerFailure(attempt *services.GuardAttempt, scope, account string) {
+	locked, err := ctrl.loginGuard.RegisterFailure(attempt)
+	if err != nil {
+		log.Printf("Başarısız deneme kaydedilemedi: %v", err)
+		return
+	}
+	if locked && scope == services.GuardSignIn {
+		ctrl.notifyAccountLocked(account)
+	}
+}
+
+// recordSignInFailure başarısız giriş denemesini denetim kaydına yazar; hesap henüz doğrulanmadığı
+// için hedef, denenen e-posta adresidir
+func recordSignInFailure(r *http.Request, account, method string, err error) {
+	audit.Record(r, audit.Event{
+		Type:       audit.SignIn,
+		TargetType: audit.TargetAccount,
+		TargetID:   account,
+		Result:     audit.ResultFailure,
+		Reason:     err.Error(),
+		Details:    map[string]interface{}{"method": method},
+	})
+}
+
+// notifyAccountLocked kilitlenen hesap gerçekten varsa email-service'e user_locked mesajı gönderir
+func (ctrl *AuthController) notifyAccountLocked(email string) {
+	user, err := ctrl.authService.FindUser(email, "")
+	if err != nil {
+		return
+	}
+
+	remaining, err := ctrl.loginGuard.LockRemaining(email)
+	if err != nil {
+		log.Printf("Hesap kilidi süresi alınamadı: %v", err)
+	}
+
+	lockedMessage := messaging.Message{
+		Type:      "user_locked",
+		ToService: messaging.EmailService,
+		Data: map[string]interface{}{
+			"user_id":       user.ID.Hex(),
+			"email":         user.Email,
+			"userName":      user.Username,
+			"template_name": "account_locked.html",
+			"locked_until":  time.Now().Add(remaining).Format("02.01.2006 15:04"),
+		},
+	}
+	if err := ctrl.rabbitMQ.PublishMessage(context.Background(), lockedMessage); err != nil {
+		log.Printf("Hesap kilitleme bildirimi gönderilemedi: %v", err)
+	}
+}
+
+// publishUserCreated yeni kullanıcıyı diğer servislere bildirir.
+// Mesaj gönderme başarısız olursa hata loglanır ancak işlem devam eder.
+func publishUserCreated(rabbitMQ *messaging.RabbitMQ, user *models.User) {
+	userCreatedMessage := services.UserCreatedMessage(user)
+	if err := rabbitMQ.PublishMessage(context.Background(), userCreatedMessage); err != nil {
+		log.Printf("Kullanıcı oluşturma mesajı gönderilemedi: %v", err)
+	}
+}
+
 // @Summary      Kullanıcı Kaydı
-// @Description  Yeni bir kullanıcı oluşturur
+// @Description  Yeni bir kullanıcı oluşturur; davetle kayıt modunda inviteCode zorunludur, kayıtlar kapalıysa 403 döner
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
-// @Param        request body models.User true "Kullanıcı Kayıt Modeli"
+// @Param        request body dto.SignUpDto true "Kullanıcı Kayıt Modeli"
 // @Success      200  {object}  SignUpResponse
 // @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Router       /auth/signUp [post]
 func (ctrl *AuthController) SignUp(w http.ResponseWriter, r *http.Request) {
-	var user = models.NewUser()
+	var input = dto.SignUpDto{User: models.NewUser()}
 
 	// Kullanıcı verisini JSON'dan çözümle
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
 		return
 	}
+	user := input.User
 
 	// Kullanıcı verisini doğrula
 	if err := validateUser(&user); err != nil {
@@ -102,7 +203,17 @@ func (ctrl *AuthController) SignUp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Kullanıcıyı kaydet ve aktivasyon bilgilerini al
-	activationCode, activationToken, err := ctrl.authService.SignUp(&user)
+	activationCode, activationToken, err := ctrl.authService.SignUp(&user, input.InviteCode)
+	if policyErr, ok := services.IsPasswordPolicyError(err); ok {
+		respondWithPasswordPolicy(w, policyErr)
+		return
+	}
+	if errors.Is(err, services.ErrRegistrationClosed) ||
+		errors.Is(err, services.ErrInviteRequired) ||
+		errors.Is(err, services.ErrInviteInvalid) {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusConflict, err.Error())
 		return
@@ -152,31 +263,33 @@ func (ctrl *AuthController) ActivationUser(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	clientIP := middlewares.ClientIP(r)
+	attempt, err := ctrl.loginGuard.Check(services.GuardActivation, activationRequest.ActivationToken, clientIP)
+	if err != nil {
+		respondWithAttemptLimit(w, err)
+		return
+	}
+
 	// Aktivasyon işlemini gerçekleştir
 	activatedUser, err := ctrl.authService.ActivationUser(activationRequest.ActivationCode, activationRequest.ActivationToken)
+	if errors.Is(err, services.ErrInviteInvalid) {
+		// Kod doğruydu; davet artık kullanılamadığı için kayıt tamamlanamadı
+		ctrl.loginGuard.Cancel(attempt)
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
+		ctrl.registerFailure(attempt, services.GuardActivation, activationRequest.ActivationToken)
 		respondWithError(w, http.StatusConflict, err.Error())
 		return
 	}
+	ctrl.loginGuard.RegisterSuccess(attempt)
 
 	// Kullanıcı oluşturulduğunda RabbitMQ'ya mesaj gönder
-	userCreatedMessage := messaging.Message{
-		Type:      "user_created",
-		ToService: messaging.UserService, // Eğer belirli bir servis varsa burada belirtin
-		Data: map[string]interface{}{
-			"user_id":   activatedUser.ID,
-			"email":     activatedUser.Email,
-			"firstName": activatedUser.FirstName,
-			"age":       activatedUser.Age,
-			"createdAt": activatedUser.CreatedAt,
-			"username":  activatedUser.Username,
-		},
-	}
+	publishUserCreated(ctrl.rabbitMQ, activatedUser)
 
-	// Mesaj gönderme başarısız olursa hata loglanır ancak işlem devam eder
-	if err := ctrl.rabbitMQ.PublishMessage(context.Background(), userCreatedMessage); err != nil {
-		log.Printf("Kullanıcı oluşturma mesajı gönderilemedi: %v", err)
-	}
+	// Şifre hash'i yanıtta dönülmez
+	activatedUser.Password = ""
 
 	// Başarılı yanıt dön
 	respondWithJSON(w, http.StatusCreated, map[string]interface{}{
@@ -185,6 +298,55 @@ func (ctrl *AuthController) ActivationUser(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// @Summary      Aktivasyon Kodunu Yeniden Gönder
+// @Description  Aktivasyon bekleyen kayıt için yeni bir kod üretir ve e-posta ile gönderir
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ResendActivationRequest true "Aktivasyon token modeli"
+// @Success      200  {object}  LogoutResponse
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/resendActivationCode [post]
+func (ctrl *AuthController) ResendActivationCode(w http.ResponseWriter, r *http.Request) {
+	var input dto.ResendActivationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.ActivationToken == "" {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+
+	activationCode, registration, err := ctrl.authService.ResendActivationCode(input.ActivationToken)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrActivationResendTooSoon) || errors.Is(err, services.ErrActivationResendLimit) {
+			status = http.StatusTooManyRequests
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	emailMessage := messaging.Message{
+		Type:      "active_user",
+		ToService: messaging.EmailService,
+		Data: map[string]interface{}{
+			"email":           registration.Email,
+			"activation_code": activationCode,
+			"template_name":   "activation_email.html",
+			"userName":        registration.Username,
+		},
+	}
+
+	if err := ctrl.rabbitMQ.PublishMessage(context.Background(), emailMessage); err != nil {
+		log.Printf("Kullanıcı aktivasyon mesajı gönderilemedi: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Aktivasyon e-postası gönderilemedi")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Yeni aktivasyon kodu e-posta adresinize gönderildi",
+	})
+}
+
 // @Summary      Kullanıcı Giriş
 // @Description   kullanıcı giriş
 // @Tags         Auth
@@ -204,47 +366,47 @@ func (ctrl *AuthController) SignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Kullanıcıyı kimlik doğrulama servisine gönder
-	user, err := ctrl.authService.SignIn(&input)
+	// Kilitli hesaplar ve çok fazla hatalı deneme yapan IP'ler şifre kontrolüne ulaşmaz
+	clientIP := middlewares.ClientIP(r)
+	attempt, err := ctrl.loginGuard.Check(services.GuardSignIn, input.Email, clientIP)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, err.Error())
+		recordSignInFailure(r, input.Email, "password", err)
+		respondWithAttemptLimit(w, err)
 		return
 	}
 
-	// Kullanıcı rollerini JSON formatına çevir
-	rolesJSON, err := json.Marshal(user.Roles)
+	// Kullanıcıyı kimlik doğrulama servisine gönder
+	user, err := ctrl.authService.SignIn(&input)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Rol bilgisi dönüştürülemedi")
-		log.Println("Rol JSON hatası:", err)
+		recordSignInFailure(r, input.Email, "password", err)
+		ctrl.registerFailure(attempt, services.GuardSignIn, input.Email)
+		respondWithError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
-
-	// Redis'te oturum oluştur
-	sessionKey := "session:" + user.ID
-	userData := map[string]string{
-		"id":       user.ID,
-		"email":    user.Email,
-		"roles":    string(rolesJSON),
-		"username": user.Username,
+	ctrl.loginGuard.RegisterSuccess(attempt)
+
+	// 2FA etkinse oturum açmadan önce ikinci adımı iste
+	if user.TwoFactorEnabled {
+		challengeToken, err := ctrl.twoFactorService.CreateChallenge(user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Doğrulama isteği oluşturulamadı")
+			log.Println("2FA challenge hatası:", err)
+			return
+		}
+		respondWithJSON(w, http.StatusAccepted, map[string]interface{}{
+			"message":           "İki adımlı doğrulama gerekli",
+			"twoFactorRequired": true,
+			"challengeToken":    challengeToken,
+		})
+		return
 	}
 
-	if err := ctrl.sessionRepo.SetSession(sessionKey, userData, 24*time.Hour); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Oturum kaydedilemedi")
-		log.Println("Redis oturum hatası:", err)
+	// Redis'te oturum oluştur ve çerezi yaz
+	if _, err := startSession(w, r, ctrl.sessionRepo, ctrl.loginHistory, user, "password"); err != nil {
+		respondWithSessionError(w, err)
 		return
 	}
 
-	// Kullanıcı için çerez oluştur
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session_id",
-		Value:    user.ID,
-		Path:     "/",
-		MaxAge:   60 * 60 * 24, // 1 gün
-		HttpOnly: true,
-		Secure:   false, // HTTPS kullanılıyorsa true yapılmalı
-		SameSite: http.SameSiteLaxMode,
-	})
-
 	// Başarılı yanıt dön
 	respondWithJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "Giriş başarılı",
@@ -261,16 +423,14 @@ func (ctrl *AuthController) SignIn(w http.ResponseWriter, r *http.Request) {
 // @Failure      400  {object}  ErrorResponse
 // @Router       /auth/logout [post]
 func (ctrl *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
-	// Çerezden session_id al
-	cookieSessionId, err := r.Cookie("session_id")
-	if err != nil {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
 		respondWithError(w, http.StatusUnauthorized, "Giriş yapılmamış")
 		return
 	}
 
 	// Redis'ten oturumu sil
-	sessionKey := "session:" + cookieSessionId.Value
-	if err := ctrl.sessionRepo.DeleteSession(sessionKey); err != nil {
+	if err := ctrl.sessionRepo.RevokeSession(userData["id"], userData["session_id"]); err != nil {
 		log.Println("Redis oturum silme hatası:", err)
 	}
 
@@ -292,6 +452,39 @@ func (ctrl *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// @Summary      Oturumdaki Kullanıcı
+// @Description  Oturum açmış kullanıcının bilgisini döner; oturum bir yönetici tarafından kullanıcı adına açıldıysa impersonating alanı true olur
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object}  dto.MeResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/me [get]
+func (ctrl *AuthController) Me(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	var roles []string
+	json.Unmarshal([]byte(userData["roles"]), &roles)
+	authMethod := userData["auth_method"]
+	if authMethod == "" {
+		authMethod = authclient.CredentialSession
+	}
+
+	impersonation := services.ImpersonationInfoFromSession(userData)
+	respondWithJSON(w, http.StatusOK, dto.MeResponse{
+		ID:            userData["id"],
+		Username:      userData["username"],
+		Email:         userData["email"],
+		Roles:         roles,
+		AuthMethod:    authMethod,
+		Impersonating: impersonation != nil,
+		Impersonation: impersonation,
+	})
+}
+
 // @Summary      Protected   router
 // @Description  otum açmış kullanıcının bilgiyi doner
 // @Tags         Auth
@@ -333,10 +526,24 @@ func (ctrl *AuthController) ForgotPassword(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// E-posta kayıtlı olsa da olmasa da aynı yanıt dönülür, böylece hesaplar tespit edilemez
+	const forgotPasswordMessage = "E-posta adresi kayıtlıysa şifre sıfırlama talimatları gönderildi"
+
 	// Şifre sıfırlama tokeni oluştur
-	token, userName, err := ctrl.authService.ForgotPassword(input.Email)
+	link, userName, err := ctrl.authService.ForgotPassword(input.Email)
+	event := audit.Event{Type: audit.PasswordResetRequested, TargetType: audit.TargetAccount, TargetID: input.Email}
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "E-posta adresi kayıtlı değil")
+		event.Result = audit.ResultFailure
+		event.Reason = err.Error()
+	}
+	audit.Record(r, event)
+	if err != nil {
+		if !errors.Is(err, services.ErrUserNotFound) {
+			log.Printf("Şifre sıfırlama bağlantısı oluşturulamadı: %v", err)
+		}
+		respondWithJSON(w, http.StatusOK, map[string]string{
+			"message": forgotPasswordMessage,
+		})
 		return
 	}
 
@@ -345,10 +552,10 @@ func (ctrl *AuthController) ForgotPassword(w http.ResponseWriter, r *http.Reques
 		Type:      "forgot_password",
 		ToService: messaging.EmailService,
 		Data: map[string]interface{}{
-			"email":           input.Email,
-			"activation_code": token,
-			"template_name":   "forgot_password.html",
-			"userName":        userName,
+			"email":         input.Email,
+			"reset_url":     link,
+			"template_name": "forgot_password.html",
+			"userName":      userName,
 		},
 	}
 
@@ -359,7 +566,7 @@ func (ctrl *AuthController) ForgotPassword(w http.ResponseWriter, r *http.Reques
 
 	// Başarı yanıtı döndür
 	respondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "Şifre sıfırlama talimatları e-posta adresinize gönderildi",
+		"message": forgotPasswordMessage,
 	})
 }
 
@@ -381,48 +588,47 @@ func (ctrl *AuthController) ResetPassword(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Şifre sıfırlama işlemini gerçekleştir
-	message, err := ctrl.authService.ResetPassword(&input)
+	// Geçersiz tokenler yalnızca IP bazında sayılır, token kendisi bir hesabı tanımlamaz
+	clientIP := middlewares.ClientIP(r)
+	attempt, err := ctrl.loginGuard.Check(services.GuardResetPassword, "", clientIP)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Geçersiz ya da süresi dolmuş token")
+		respondWithAttemptLimit(w, err)
 		return
 	}
 
-	// Başarı yanıtı döndür
-	respondWithJSON(w, http.StatusOK, map[string]interface{}{
-		"message": message,
-	})
-}
-
-func (ctrl *AuthController) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	var input dto.UpdateStatusDto
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Geçersiz veri")
+	// Şifre sıfırlama işlemini gerçekleştir
+	userID, err := ctrl.authService.ResetPassword(&input)
+	if policyErr, ok := services.IsPasswordPolicyError(err); ok {
+		ctrl.loginGuard.Cancel(attempt)
+		respondWithPasswordPolicy(w, policyErr)
 		return
 	}
-	userData, ok := middlewares.GetUserData(r)
-	if !ok {
-		respondWithError(w, http.StatusInternalServerError, "Kullanıcı bilgisi bulunamadı")
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidResetToken) {
+			log.Printf("Şifre sıfırlanamadı: %v", err)
+		}
+		audit.Record(r, audit.Event{Type: audit.PasswordReset, Result: audit.ResultFailure, Reason: err.Error()})
+		ctrl.registerFailure(attempt, services.GuardResetPassword, "")
+		respondWithError(w, http.StatusUnauthorized, "Geçersiz ya da süresi dolmuş token")
 		return
 	}
+	ctrl.loginGuard.RegisterSuccess(attempt)
 
-	id, exists := userData["id"]
-	if !exists {
-		fmt.Println("id not found in userData")
-	} else {
-		fmt.Println("User ID:", id)
-	}
-	fmt.Println(id, input.Status)
-	err := ctrl.authService.UpdateStatus(id, input.Status)
+	// Şifre değiştiği için tüm cihazlardaki oturumlar kapatılır
+	revoked, err := ctrl.sessionRepo.RevokeUserSessions(userID.Hex(), "")
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, err.Error())
-		return
+		log.Printf("Kullanıcı oturumları kapatılamadı: %v", err)
 	}
-
-	ctrl.sessionRepo.PublishStatus(id, "away")
-	w.WriteHeader(http.StatusOK)
-	render.JSON(w, r, map[string]interface{}{
-		"message": "ok",
+	audit.Record(r, audit.Event{
+		Type:       audit.PasswordReset,
+		ActorID:    userID.Hex(),
+		TargetType: audit.TargetUser,
+		TargetID:   userID.Hex(),
+		Details:    map[string]interface{}{"revokedSessions": revoked},
 	})
 
+	// Başarı yanıtı döndür
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Şifreniz başarıyla sıfırlandı, lütfen tekrar giriş yapın",
+	})
 }
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+)
+
+type DataExportListResponse struct {
+	Exports []models.DataExport `json:"exports"`
+}
+
+type DataExportController struct {
+	dataExportService *services.DataExportService
+}
+
+func NewDataExportController(dataExportService *services.DataExportService) *DataExportController {
+	return &DataExportController{
+		dataExportService: dataExportService,
+	}
+}
+
+func dataExportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrDataExportInProgress):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrDataExportTooSoon):
+		return http.StatusTooManyRequests
+	case errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, repository.ErrDataExportNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary      Verilerimi Dışa Aktar
+// @Description  Kullanıcının tüm servislerdeki verilerinin dışa aktarılmasını başlatır; arşiv hazır olunca süreli indirme bağlantısı e-postayla gönderilir
+// @Tags         Account
+// @Produce      json
+// @Success      202  {object}  models.DataExport
+// @Failure      409  {object}  ErrorResponse
+// @Failure      429  {object}  ErrorResponse
+// @Router       /auth/export [post]
+func (ctrl *DataExportController) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	export, err := ctrl.dataExportService.Request(userData["id"])
+	if err != nil {
+		status := dataExportErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("Dışa aktarma isteği oluşturulamadı:", err)
+			respondWithError(w, status, "Dışa aktarma isteği oluşturulamadı")
+			return
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusAccepted, export)
+}
+
+// @Summary      Dışa Aktarma İsteklerim
+// @Description  Kullanıcının son dışa aktarma isteklerini ve durumlarını listeler
+// @Tags         Account
+// @Produce      json
+// @Success      200  {object}  DataExportListResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/export [get]
+func (ctrl *DataExportController) ListExports(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	exports, err := ctrl.dataExportService.ListExports(userData["id"])
+	if err != nil {
+		status := dataExportErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("Dışa aktarma istekleri listelenemedi:", err)
+			respondWithError(w, status, "Dışa aktarma istekleri listelenemedi")
+			return
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusOK, DataExportListResponse{Exports: exports})
+}
+
+// @Summary      Dışa Aktarma Arşivini İndir
+// @Description  E-postayla gönderilen süreli bağlantıdaki tokenle zip arşivini indirir
+// @Tags         Account
+// @Produce      application/zip
+// @Param        token query string true "İndirme tokeni"
+// @Success      200
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/export/download [get]
+func (ctrl *DataExportController) Download(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		respondWithError(w, http.StatusBadRequest, "İndirme tokeni gerekli")
+		return
+	}
+
+	export, bundle, err := ctrl.dataExportService.OpenDownload(token)
+	if err != nil {
+		status := dataExportErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("Dışa aktarma arşivi açılamadı:", err)
+			respondWithError(w, status, "Arşiv indirilemedi")
+			return
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+	defer bundle.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="export-`+export.ID.Hex()+`.zip"`)
+	w.Header().Set("Cache-Control", "no-store")
+	if export.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(export.Size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, bundle); err != nil {
+		log.Println("Dışa aktarma arşivi gönderilemedi:", err)
+	}
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	federationStateCookie     = "oidc_state"
+	federationStateCookiePath = "/auth/federated"
+)
+
+type FederationController struct {
+	federationService *services.FederationService
+	twoFactorService  *services.TwoFactorService
+	sessionRepo       *redisrepo.RedisRepository
+	loginHistory      *services.LoginHistoryService
+}
+
+func NewFederationController(federationService *services.FederationService, sessionRepo *redisrepo.RedisRepository, loginHistory *services.LoginHistoryService) *FederationController {
+	return &FederationController{
+		federationService: federationService,
+		twoFactorService:  services.NewTwoFactorService(),
+		sessionRepo:       sessionRepo,
+		loginHistory:      loginHistory,
+	}
+}
+
+func federationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUnknownProvider):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrFederationStateInvalid),
+		errors.Is(err, services.ErrFederatedEmailNotVerified):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrFederatedTokenInvalid),
+		errors.Is(err, services.ErrUserNotFound):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrRegistrationClosed):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// @Summary      Harici Kimlik Sağlayıcılar
+// @Description  "... ile giriş yap" için yapılandırılmış OIDC sağlayıcılarını listeler
+// @Tags         Federation
+// @Produce      json
+// @Success      200  {array}  services.FederatedProvider
+// @Router       /auth/federated/providers [get]
+func (ctrl *FederationController) Providers(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, ctrl.federationService.Providers())
+}
+
+// @Summary      Harici Sağlayıcı ile Giriş
+// @Description  Kullanıcıyı PKCE ve nonce ile birlikte sağlayıcının giriş sayfasına yönlendirir
+// @Tags         Federation
+// @Param        provider   path   string  true   "Sağlayıcı adı"
+// @Param        return_to  query  string  false  "Giriş sonrası dönülecek adres"
+// @Success      302
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/federated/{provider}/login [get]
+func (ctrl *FederationController) Login(w http.ResponseWriter, r *http.Request) {
+	authURL, state, err := ctrl.federationService.BeginLogin(chi.URLParam(r, "provider"), r.URL.Query().Get("return_to"))
+	if err != nil {
+		log.Println("Harici giriş başlatılamadı:", err)
+		respondWithError(w, federationErrorStatus(err), err.Error())
+		return
+	}
+
+	// State tarayıcıya bağlanır; başka bir tarayıcıda başlatılan akışın geri dönüşü kabul edilmez.
+	// Sağlayıcıdan dönüş üst düzey bir GET olduğu için Lax yeterlidir.
+	http.SetCookie(w, &http.Cookie{
+		Name:     federationStateCookie,
+		Value:    state,
+		Path:     federationStateCookiePath,
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   false, // HTTPS kullanılıyorsa true yapılmalı
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// @Summary      Harici Sağlayıcı Dönüşü
+// @Description  Sağlayıcıdan dönen kodu doğrular, hesabı bağlar veya kayıtlar açıksa oluşturur ve oturum açar
+// @Tags         Federation
+// @Param        provider  path   string  true  "Sağlayıcı adı"
+// @Param        code      query  string  true  "Yetkilendirme kodu"
+// @Param        state     query  string  true  "Giriş isteği"
+// @Success      302
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Router       /auth/federated/{provider}/callback [get]
+func (ctrl *FederationController) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	state := query.Get("state")
+
+	cookie, err := r.Cookie(federationStateCookie)
+	// State çerezi tek kullanımlıktır
+	http.SetCookie(w, &http.Cookie{Name: federationStateCookie, Value: "", Path: federationStateCookiePath, MaxAge: -1, HttpOnly: true})
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		respondWithError(w, http.StatusBadRequest, services.ErrFederationStateInvalid.Error())
+		return
+	}
+
+	if providerError := query.Get("error"); providerError != "" {
+		respondWithError(w, http.StatusUnauthorized, "Kimlik sağlayıcı girişi reddetti: "+providerError)
+		return
+	}
+
+	user, returnTo, err := ctrl.federationService.CompleteLogin(chi.URLParam(r, "provider"), state, query.Get("code"))
+	if err != nil {
+		log.Println("Harici giriş tamamlanamadı:", err)
+		respondWithError(w, federationErrorStatus(err), err.Error())
+		return
+	}
+
+	target, err := url.Parse(returnTo)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Geçersiz dönüş adresi")
+		return
+	}
+
+	// 2FA etkinse harici giriş ikinci adımı atlatmaz; istemci challengeToken ile /auth/2fa/verify çağırır
+	if user.TwoFactor != nil && user.TwoFactor.Enabled {
+		challengeToken, err := ctrl.twoFactorService.CreateChallenge(user.ID.Hex())
+		if err != nil {
+			log.Println("2FA challenge hatası:", err)
+			respondWithError(w, http.StatusInternalServerError, "Doğrulama isteği oluşturulamadı")
+			return
+		}
+		targetQuery := target.Query()
+		targetQuery.Set("challengeToken", challengeToken)
+		target.RawQuery = targetQuery.Encode()
+		http.Redirect(w, r, target.String(), http.StatusFound)
+		return
+	}
+
+	if _, err := startSession(w, r, ctrl.sessionRepo, ctrl.loginHistory, dto.NewUserResponse(user), "federated:"+chi.URLParam(r, "provider")); err != nil {
+		respondWithSessionError(w, err)
+		return
+	}
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
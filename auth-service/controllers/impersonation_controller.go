@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-chi/chi/v5"
+)
+
+const maxImpersonationAuditLimit = 500
+
+type ImpersonationAuditResponse struct {
+	Entries []redisrepo.ImpersonationAuditEntry `json:"entries"`
+}
+
+type ImpersonationController struct {
+	impersonationService *services.ImpersonationService
+}
+
+func NewImpersonationController(impersonationService *services.ImpersonationService) *ImpersonationController {
+	return &ImpersonationController{impersonationService: impersonationService}
+}
+
+func impersonationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrImpersonationNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrCannotModifySelf),
+		errors.Is(err, services.ErrImpersonationTTLTooLong),
+		errors.Is(err, services.ErrNotImpersonating):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary      Kullanıcı Adına Oturum Aç
+// @Description  Destek ekibinin uygulamayı verilen kullanıcı gibi görmesi için süreli bir oturum açar ve oturum çerezini bu oturumla değiştirir. Oturumdaki her istek denetim kaydına yazılır; şifre değişikliği gibi işlemler engellenir.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        userID  path  string  true  "Kullanıcı ID"
+// @Param        request body dto.StartImpersonationDto true "Gerekçe ve süre"
+// @Success      200  {object}  dto.ImpersonationResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/users/{userID}/impersonate [post]
+func (ctrl *ImpersonationController) Start(w http.ResponseWriter, r *http.Request) {
+	var input dto.StartImpersonationDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	if err := validate.Struct(input); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	admin, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	meta := redisrepo.SessionMeta{Device: r.UserAgent(), IP: middlewares.ClientIP(r)}
+	sessionID, response, err := ctrl.impersonationService.Start(admin, chi.URLParam(r, "userID"), &input, meta)
+	if err != nil {
+		status := impersonationErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("Kullanıcı adına oturum açılamadı:", err)
+			respondWithError(w, status, "Oturum açılamadı")
+			return
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	ctrl.recordAudit(r, admin["id"], response.User.ID, sessionID, http.StatusOK)
+	setSessionCookie(w, sessionID, time.Until(response.Impersonation.ExpiresAt))
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// @Summary      Kullanıcı Adına Oturumu Kapat
+// @Description  Kullanıcı adına açılmış oturumu sonlandırır; yöneticinin kendi oturumu hâlâ geçerliyse oturum çerezi ona geri döner
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/impersonation/stop [post]
+func (ctrl *ImpersonationController) Stop(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	adminSessionID, err := ctrl.impersonationService.Stop(userData)
+	if err != nil {
+		status := impersonationErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("Kullanıcı adına açılmış oturum kapatılamadı:", err)
+			respondWithError(w, status, "Oturum kapatılamadı")
+			return
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	if adminSessionID != "" {
+		setSessionCookie(w, adminSessionID, sessionDuration)
+	} else {
+		setSessionCookie(w, "", -time.Second)
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message":         "Kullanıcı adına açılmış oturum kapatıldı",
+		"sessionRestored": adminSessionID != "",
+	})
+}
+
+// @Summary      Kullanıcı Adına Yapılan İşlemler
+// @Description  Yöneticilerin kullanıcılar adına yaptığı isteklerin denetim kayıtlarını en yeniden başlayarak döner
+// @Tags         Admin
+// @Produce      json
+// @Param        impersonatorId  query  string  false  "Yönetici ID"
+// @Param        userId          query  string  false  "Kullanıcı ID"
+// @Param        limit           query  int     false  "En fazla kayıt (varsayılan 100, en fazla 500)"
+// @Success      200  {object}  ImpersonationAuditResponse
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/admin/impersonation/audit [get]
+func (ctrl *ImpersonationController) ListAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 100
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxImpersonationAuditLimit {
+			respondWithError(w, http.StatusBadRequest, "Geçersiz limit değeri")
+			return
+		}
+		limit = parsed
+	}
+
+	entries, err := ctrl.impersonationService.ListAudit(query.Get("impersonatorId"), query.Get("userId"), limit)
+	if err != nil {
+		log.Println("Denetim kayıtları okunamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "Denetim kayıtları alınamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, ImpersonationAuditResponse{Entries: entries})
+}
+
+// recordAudit oturumun açılmasını denetim kaydına yazar; oturumdaki sonraki istekler middleware tarafından yazılır
+func (ctrl *ImpersonationController) recordAudit(r *http.Request, impersonatorID, userID, sessionID string, status int) {
+	entry := &redisrepo.ImpersonationAuditEntry{
+		ImpersonatorID: impersonatorID,
+		UserID:         userID,
+		SessionID:      sessionID,
+		Method:         r.Method,
+		Path:           r.URL.Path,
+		Status:         status,
+		IP:             middlewares.ClientIP(r),
+		UserAgent:      r.UserAgent(),
+	}
+	if err := ctrl.impersonationService.RecordAudit(entry); err != nil {
+		log.Printf("Kullanıcı adına oturum açma kaydedilemedi: %v", err)
+	}
+}
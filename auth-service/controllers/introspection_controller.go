@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/authclient"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+)
+
+// IntrospectionController diğer servislerin oturum ve API tokenlerini Redis'e doğrudan
+// erişmeden doğrulayabilmesi için iç endpoint sağlar
+type IntrospectionController struct {
+	sessionRepo *redisrepo.RedisRepository
+}
+
+func NewIntrospectionController(sessionRepo *redisrepo.RedisRepository) *IntrospectionController {
+	return &IntrospectionController{sessionRepo: sessionRepo}
+}
+
+// @Summary      Oturum Introspection (iç)
+// @Description  Oturum kimliğinin veya API tokeninin geçerliliğini, kullanıcı ID'sini ve rollerini döner; yalnızca servisler arası kullanım içindir
+// @Tags         Internal
+// @Accept       json
+// @Produce      json
+// @Param        X-Internal-Secret  header  string                         true  "Servisler arası gizli anahtar"
+// @Param        request            body    authclient.IntrospectRequest   true  "Kimlik bilgisi"
+// @Success      200  {object}  authclient.Identity
+// @Failure      401  {object}  ErrorResponse
+// @Router       /internal/introspect [post]
+func (ctrl *IntrospectionController) Introspect(w http.ResponseWriter, r *http.Request) {
+	var input authclient.IntrospectRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+
+	identity, err := authclient.IntrospectRedis(ctrl.sessionRepo, input.Type, input.Token)
+	if err != nil {
+		log.Println("Introspection hatası:", err)
+		respondWithError(w, http.StatusInternalServerError, "Oturum doğrulanamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, identity)
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// InviteCreatedResponse düz metin davet kodunu ve kayıt bağlantısını içerir; kod daha sonra tekrar gösterilmez
+type InviteCreatedResponse struct {
+	Code   string         `json:"code"`
+	URL    string         `json:"url"`
+	Invite *models.Invite `json:"invite"`
+}
+
+type InviteListResponse struct {
+	Invites []models.Invite `json:"invites"`
+}
+
+type InviteController struct {
+	inviteService *services.InviteService
+	authorizer    *middlewares.Authorizer
+}
+
+func NewInviteController(inviteService *services.InviteService, authorizer *middlewares.Authorizer) *InviteController {
+	return &InviteController{
+		inviteService: inviteService,
+		authorizer:    authorizer,
+	}
+}
+
+func inviteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInviteTTLTooLong),
+		errors.Is(err, services.ErrInviteTooManyUses),
+		errors.Is(err, services.ErrUnknownRole):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInviteNotAllowed),
+		errors.Is(err, services.ErrInviteRolesNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInviteLimit):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrInviteNotFound),
+		errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (ctrl *InviteController) listInvites(w http.ResponseWriter, userID string) {
+	invites, err := ctrl.inviteService.ListInvites(userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Println("Davetler alınamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "Davetler alınamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, InviteListResponse{Invites: invites})
+}
+
+func (ctrl *InviteController) revokeInvite(w http.ResponseWriter, r *http.Request, ownerID string) {
+	inviteID := chi.URLParam(r, "inviteID")
+	if err := ctrl.inviteService.RevokeInvite(inviteID, ownerID); err != nil {
+		if errors.Is(err, repository.ErrInviteNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Println("Davet iptal edilemedi:", err)
+		respondWithError(w, http.StatusInternalServerError, "Davet iptal edilemedi")
+		return
+	}
+
+	audit.Record(r, audit.Event{
+		Type:       audit.InviteRevoked,
+		TargetType: audit.TargetInvite,
+		TargetID:   inviteID,
+	})
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Davet iptal edildi",
+	})
+}
+
+// @Summary      Kayıt Modu
+// @Description  Kayıtların açık, davetle veya kapalı olduğunu döner; kayıt sayfası davet kodu alanını buna göre gösterir
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object}  services.RegistrationInfo
+// @Router       /auth/registration [get]
+func (ctrl *InviteController) RegistrationInfo(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, ctrl.inviteService.Info())
+}
+
+// @Summary      Davet Oluştur
+// @Description  Kayıt daveti oluşturur; e-posta verilirse davet bağlantısı o adrese gönderilir. Rol atamak user:manage-roles, sınırsız davet oluşturmak invite:manage yetkisi gerektirir; kod yalnızca bu yanıtta gösterilir
+// @Tags         Invites
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateInviteDto true "Davet e-postası, kullanım sayısı, süresi ve rolleri"
+// @Success      201  {object}  InviteCreatedResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Router       /auth/invites [post]
+func (ctrl *InviteController) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	var input dto.CreateInviteDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	if err := validate.Struct(input); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	creator := services.InviteCreator{
+		UserID:         userData["id"],
+		Username:       userData["username"],
+		CanManage:      middlewares.HasPermission(r, models.PermInvitesManage),
+		CanAssignRoles: middlewares.HasPermission(r, models.PermUserManageRoles),
+	}
+	invite, code, err := ctrl.inviteService.CreateInvite(creator, &input, ctrl.authorizer.RolePermissions())
+	if err != nil {
+		status := inviteErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.Println("Davet oluşturulamadı:", err)
+			respondWithError(w, status, "Davet oluşturulamadı")
+			return
+		}
+		respondWithError(w, status, err.Error())
+		return
+	}
+
+	audit.Record(r, audit.Event{
+		Type:       audit.InviteCreated,
+		TargetType: audit.TargetInvite,
+		TargetID:   invite.ID.Hex(),
+		Details: map[string]interface{}{
+			"roles":   invite.Roles,
+			"maxUses": invite.MaxUses,
+			"emailed": invite.Email != "",
+		},
+	})
+	respondWithJSON(w, http.StatusCreated, InviteCreatedResponse{
+		Code:   code,
+		URL:    ctrl.inviteService.InviteURL(code),
+		Invite: invite,
+	})
+}
+
+// @Summary      Davetlerim
+// @Description  Kullanıcının oluşturduğu davetleri kullanım sayılarıyla döner
+// @Tags         Invites
+// @Produce      json
+// @Success      200  {object}  InviteListResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/invites [get]
+func (ctrl *InviteController) ListInvites(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+	ctrl.listInvites(w, userData["id"])
+}
+
+// @Summary      Daveti İptal Et
+// @Description  Kullanıcının oluşturduğu daveti iptal eder; davetle başlamış ancak aktivasyonu tamamlanmamış kayıtlar da tamamlanamaz
+// @Tags         Invites
+// @Produce      json
+// @Param        inviteID path string true "Davet ID"
+// @Success      200  {object}  LogoutResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/invites/{inviteID} [delete]
+func (ctrl *InviteController) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+	ctrl.revokeInvite(w, r, userData["id"])
+}
+
+// @Summary      Tüm Davetler
+// @Description  Tüm kullanıcıların oluşturduğu davetleri en yeniden başlayarak döner
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {object}  InviteListResponse
+// @Failure      403  {object}  ErrorResponse
+// @Router       /auth/admin/invites [get]
+func (ctrl *InviteController) AdminListInvites(w http.ResponseWriter, r *http.Request) {
+	ctrl.listInvites(w, "")
+}
+
+// @Summary      Daveti İptal Et (Yönetici)
+// @Description  Herhangi bir kullanıcının davetini iptal eder
+// @Tags         Admin
+// @Produce      json
+// @Param        inviteID path string true "Davet ID"
+// @Success      200  {object}  LogoutResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/invites/{inviteID} [delete]
+func (ctrl *InviteController) AdminRevokeInvite(w http.ResponseWriter, r *http.Request) {
+	ctrl.revokeInvite(w, r, "")
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+)
+
+type LoginHistoryResponse struct {
+	Logins []models.LoginRecord `json:"logins"`
+}
+
+type LoginHistoryController struct {
+	loginHistory *services.LoginHistoryService
+}
+
+func NewLoginHistoryController(loginHistory *services.LoginHistoryService) *LoginHistoryController {
+	return &LoginHistoryController{loginHistory: loginHistory}
+}
+
+// @Summary      Giriş Geçmişi
+// @Description  Kullanıcının son girişlerini cihaz, IP ve giriş yöntemiyle birlikte en yeniden başlayarak listeler
+// @Tags         Session
+// @Produce      json
+// @Param        limit  query  int  false  "En fazla kayıt (varsayılan 20, en fazla 100)"
+// @Success      200  {object}  LoginHistoryResponse
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/logins [get]
+func (ctrl *LoginHistoryController) ListLogins(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	limit := services.DefaultLoginHistoryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > services.MaxLoginHistoryLimit {
+			respondWithError(w, http.StatusBadRequest, "Geçersiz limit değeri")
+			return
+		}
+		limit = parsed
+	}
+
+	logins, err := ctrl.loginHistory.ListLogins(userData["id"], limit)
+	if err != nil {
+		log.Println("Giriş geçmişi okunamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "Giriş geçmişi alınamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, LoginHistoryResponse{Logins: logins})
+}
+
+// @Summary      Bu Ben Değilim
+// @Description  Yeni cihaz uyarısı e-postasındaki tek kullanımlık tokenle, uyarıya konu olan girişte açılan oturumu sonlandırır
+// @Tags         Session
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.RevokeLoginDto true "Uyarı e-postasındaki token"
+// @Success      200  {object}  LogoutResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/logins/revoke [post]
+func (ctrl *LoginHistoryController) RevokeLogin(w http.ResponseWriter, r *http.Request) {
+	var input dto.RevokeLoginDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Token == "" {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+
+	userID, err := ctrl.loginHistory.RevokeFromAlert(input.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrLoginAlertInvalid) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Println("Uyarıdaki oturum sonlandırılamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "Oturum sonlandırılamadı")
+		return
+	}
+
+	audit.Record(r, audit.Event{
+		Type:       audit.SessionRevoked,
+		ActorID:    userID,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+		Details:    map[string]interface{}{"scope": "login_alert"},
+	})
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Oturum sonlandırıldı. Hesabınızın güvenliği için şifrenizi değiştirmenizi öneririz",
+	})
+}
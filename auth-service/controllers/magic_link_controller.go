@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+)
+
+const (
+	magicLoginCookie     = "magic_login"
+	magicLoginCookiePath = "/auth/magic"
+)
+
+// Kayıtlı olsun olmasın aynı yanıt dönülür, böylece hesaplar tespit edilemez
+const magicLoginRequestMessage = "E-posta adresi kayıtlıysa giriş bağlantısı ve kodu gönderildi"
+
+type MagicLinkController struct {
+	magicLinkService *services.MagicLinkService
+	twoFactorService *services.TwoFactorService
+	loginGuard       *services.LoginGuard
+	rabbitMQ         *messaging.RabbitMQ
+	sessionRepo      *redisrepo.RedisRepository
+	loginHistory     *services.LoginHistoryService
+	tokenTTL         int
+}
+
+func NewMagicLinkController(rabbitMQ *messaging.RabbitMQ, sessionRepo *redisrepo.RedisRepository, loginHistory *services.LoginHistoryService, loginGuard *services.LoginGuard, cfg config.MagicLinkConfig) *MagicLinkController {
+	return &MagicLinkController{
+		magicLinkService: services.NewMagicLinkService(cfg),
+		twoFactorService: services.NewTwoFactorService(),
+		loginGuard:       loginGuard,
+		rabbitMQ:         rabbitMQ,
+		sessionRepo:      sessionRepo,
+		loginHistory:     loginHistory,
+		tokenTTL:         int(cfg.TokenTTL.Seconds()),
+	}
+}
+
+func clearMagicLoginCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{Name: magicLoginCookie, Value: "", Path: magicLoginCookiePath, MaxAge: -1, HttpOnly: true})
+}
+
+// @Summary      Şifresiz Giriş İste
+// @Description  E-posta adresine tek kullanımlık giriş bağlantısı ve 6 haneli kod gönderir; istek bu tarayıcıya bağlanır
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.MagicLoginRequestDto true "Şifresiz giriş isteği"
+// @Success      200  {object}  LogoutResponse
+// @Failure      429  {object}  ErrorResponse
+// @Router       /auth/magic/request [post]
+func (ctrl *MagicLinkController) Request(w http.ResponseWriter, r *http.Request) {
+	var input dto.MagicLoginRequestDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Email == "" {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+
+	login, browserToken, err := ctrl.magicLinkService.RequestLogin(input.Email)
+	if err != nil {
+		if errors.Is(err, services.ErrMagicLoginTooSoon) {
+			respondWithError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		log.Printf("Şifresiz giriş isteği oluşturulamadı: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Giriş isteği oluşturulamadı")
+		return
+	}
+
+	// Bağlantı ve kod yalnızca isteği başlatan tarayıcıda kullanılabilir
+	http.SetCookie(w, &http.Cookie{
+		Name:     magicLoginCookie,
+		Value:    browserToken,
+		Path:     magicLoginCookiePath,
+		MaxAge:   ctrl.tokenTTL,
+		HttpOnly: true,
+		Secure:   false, // HTTPS kullanılıyorsa true yapılmalı
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if login != nil {
+		emailMessage := messaging.Message{
+			Type:      "magic_login",
+			ToService: messaging.EmailService,
+			Data: map[string]interface{}{
+				"email":         login.Email,
+				"login_url":     login.Link,
+				"login_code":    login.Code,
+				"template_name": "magic_login.html",
+				"userName":      login.Username,
+			},
+		}
+		if err := ctrl.rabbitMQ.PublishMessage(context.Background(), emailMessage); err != nil {
+			log.Printf("Şifresiz giriş e-postası gönderilemedi: %v", err)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": magicLoginRequestMessage,
+	})
+}
+
+// @Summary      Şifresiz Giriş Doğrula
+// @Description  E-postadaki bağlantı tokeni veya kod ile, isteği başlatan tarayıcıda oturum açar
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.MagicLoginVerifyDto true "Bağlantı tokeni veya kod"
+// @Success      200  {object}  ActivationResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/magic/verify [post]
+func (ctrl *MagicLinkController) Verify(w http.ResponseWriter, r *http.Request) {
+	var input dto.MagicLoginVerifyDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || (input.Token == "" && input.Code == "") {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+
+	cookie, err := r.Cookie(magicLoginCookie)
+	if err != nil || cookie.Value == "" {
+		respondWithError(w, http.StatusUnauthorized, services.ErrMagicLoginInvalid.Error())
+		return
+	}
+
+	// Kilitli hesaplar şifresiz girişle de açılamaz
+	clientIP := middlewares.ClientIP(r)
+	email, _ := ctrl.magicLinkService.PendingEmail(cookie.Value)
+	attempt, err := ctrl.loginGuard.Check(services.GuardMagicLogin, email, clientIP)
+	if err != nil {
+		respondWithAttemptLimit(w, err)
+		return
+	}
+
+	user, err := ctrl.magicLinkService.Redeem(cookie.Value, input.Token, input.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrMagicLoginInvalid) || errors.Is(err, services.ErrUserNotFound) {
+			recordSignInFailure(r, email, "magic_link", err)
+			// Hatalı kodlar istek başına ayrıca sınırlandığı için yalnızca IP sayacı artırılır
+			ctrl.loginGuard.RegisterIPFailure(attempt)
+			respondWithError(w, http.StatusUnauthorized, services.ErrMagicLoginInvalid.Error())
+			return
+		}
+		ctrl.loginGuard.Cancel(attempt)
+		log.Printf("Şifresiz giriş doğrulanamadı: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Giriş doğrulanamadı")
+		return
+	}
+	ctrl.loginGuard.RegisterSuccess(attempt)
+	clearMagicLoginCookie(w)
+
+	// 2FA etkinse şifresiz giriş ikinci adımı atlatmaz
+	response := dto.NewUserResponse(user)
+	if response.TwoFactorEnabled {
+		challengeToken, err := ctrl.twoFactorService.CreateChallenge(response.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Doğrulama isteği oluşturulamadı")
+			log.Println("2FA challenge hatası:", err)
+			return
+		}
+		respondWithJSON(w, http.StatusAccepted, map[string]interface{}{
+			"message":           "İki adımlı doğrulama gerekli",
+			"twoFactorRequired": true,
+			"challengeToken":    challengeToken,
+		})
+		return
+	}
+
+	if _, err := startSession(w, r, ctrl.sessionRepo, ctrl.loginHistory, response, "magic_link"); err != nil {
+		respondWithSessionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Giriş başarılı",
+		"user":    response,
+	})
+}
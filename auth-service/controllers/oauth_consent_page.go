@@ -0,0 +1,70 @@
+package controllers
+
+import "html/template"
+
+// consentPageData onay ekranında gösterilecek bilgileri taşır
+type consentPageData struct {
+	ClientName string
+	Username   string
+	Scopes     []consentScope
+	ConsentID  string
+	ActionURL  string
+}
+
+type consentScope struct {
+	Name        string
+	Description string
+}
+
+// Bilinen kapsamların kullanıcıya gösterilecek açıklamaları; diğer kapsamlar adıyla gösterilir
+var scopeDescriptions = map[string]string{
+	"openid":  "Kimliğinizi doğrulama",
+	"profile": "Kullanıcı adınızı, adınızı ve profil fotoğrafınızı görme",
+	"email":   "E-posta adresinizi görme",
+}
+
+func describeScopes(scopes []string) []consentScope {
+	described := make([]consentScope, 0, len(scopes))
+	for _, scope := range scopes {
+		description, ok := scopeDescriptions[scope]
+		if !ok {
+			description = scope
+		}
+		described = append(described, consentScope{Name: scope, Description: description})
+	}
+	return described
+}
+
+var consentPage = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html lang="tr">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Erişim İzni</title>
+	<style>
+		body { font-family: sans-serif; background: #f4f4f7; display: flex; justify-content: center; padding-top: 60px; }
+		.card { background: #fff; border-radius: 8px; padding: 32px; max-width: 420px; box-shadow: 0 2px 8px rgba(0,0,0,.1); }
+		ul { padding-left: 20px; }
+		.actions { display: flex; gap: 12px; margin-top: 24px; }
+		button { flex: 1; padding: 10px; border-radius: 4px; border: 1px solid #ccc; cursor: pointer; font-size: 15px; }
+		button.approve { background: #2563eb; color: #fff; border-color: #2563eb; }
+	</style>
+</head>
+<body>
+	<div class="card">
+		<h2>{{.ClientName}}</h2>
+		<p><strong>{{.Username}}</strong> hesabınıza erişmek için aşağıdaki izinleri istiyor:</p>
+		<ul>
+			{{range .Scopes}}<li>{{.Description}}</li>{{end}}
+		</ul>
+		<form method="post" action="{{.ActionURL}}">
+			<input type="hidden" name="consent_id" value="{{.ConsentID}}">
+			<div class="actions">
+				<button type="submit" name="decision" value="deny">Reddet</button>
+				<button type="submit" name="decision" value="approve" class="approve">İzin Ver</button>
+			</div>
+		</form>
+	</div>
+</body>
+</html>
+`))
@@ -0,0 +1,540 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-chi/chi/v5"
+)
+
+// OAuthErrorResponse RFC 6749 hata yanıtıdır
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type OAuthClientCreatedResponse struct {
+	Client       *models.OAuthClient `json:"client"`
+	ClientSecret string              `json:"clientSecret,omitempty"`
+}
+
+type OAuthController struct {
+	oauthService  *services.OAuthService
+	clientService *services.OAuthClientService
+	sessionRepo   *redisrepo.RedisRepository
+	loginURL      string
+}
+
+func NewOAuthController(oauthService *services.OAuthService, clientService *services.OAuthClientService, sessionRepo *redisrepo.RedisRepository, loginURL string) *OAuthController {
+	return &OAuthController{
+		oauthService:  oauthService,
+		clientService: clientService,
+		sessionRepo:   sessionRepo,
+		loginURL:      loginURL,
+	}
+}
+
+// respondWithOAuthError hatayı RFC 6749'daki biçimde döner
+func respondWithOAuthError(w http.ResponseWriter, err error) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		log.Println("OAuth hatası:", err)
+		oauthErr = &services.OAuthError{Code: "server_error", Description: "beklenmeyen bir hata oluştu"}
+	}
+	if oauthErr.Code == "invalid_client" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, oauthErr.Status(), OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+}
+
+// redirectToClient sonucu istemcinin yönlendirme adresine sorgu parametreleri olarak iletir
+func redirectToClient(w http.ResponseWriter, r *http.Request, request *models.OAuthAuthorizationRequest, params url.Values) {
+	target, err := url.Parse(request.RedirectURI)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz yönlendirme adresi")
+		return
+	}
+	query := target.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	if request.State != "" {
+		query.Set("state", request.State)
+	}
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func redirectWithOAuthError(w http.ResponseWriter, r *http.Request, request *models.OAuthAuthorizationRequest, err error) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		log.Println("OAuth hatası:", err)
+		oauthErr = &services.OAuthError{Code: "server_error", Description: "beklenmeyen bir hata oluştu"}
+	}
+	redirectToClient(w, r, request, url.Values{
+		"error":             {oauthErr.Code},
+		"error_description": {oauthErr.Description},
+	})
+}
+
+// sessionAuthTime kullanıcının mevcut oturumu açtığı zamanı (OIDC auth_time) döner
+func (ctrl *OAuthController) sessionAuthTime(userData map[string]string) time.Time {
+	sessions, err := ctrl.sessionRepo.ListUserSessions(userData["id"])
+	if err == nil {
+		for _, session := range sessions {
+			if session.ID == userData["session_id"] && !session.CreatedAt.IsZero() {
+				return session.CreatedAt
+			}
+		}
+	}
+	return time.Now()
+}
+
+// @Summary      OAuth2 Yetkilendirme
+// @Description  Authorization code (PKCE) akışını başlatır. Oturum yoksa giriş sayfasına, onay gerekiyorsa onay ekranına yönlendirir.
+// @Tags         OAuth
+// @Produce      html
+// @Param        response_type          query  string  true   "code"
+// @Param        client_id              query  string  true   "İstemci kimliği"
+// @Param        redirect_uri           query  string  false  "Kayıtlı yönlendirme adresi"
+// @Param        scope                  query  string  true   "Boşlukla ayrılmış kapsamlar"
+// @Param        state                  query  string  false  "İstemci durumu"
+// @Param        nonce                  query  string  false  "ID token nonce değeri"
+// @Param        code_challenge         query  string  false  "PKCE code_challenge"
+// @Param        code_challenge_method  query  string  false  "S256"
+// @Param        prompt                 query  string  false  "none veya consent"
+// @Success      302
+// @Failure      400  {object}  OAuthErrorResponse
+// @Router       /oauth/authorize [get]
+func (ctrl *OAuthController) Authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	input := dto.OAuthAuthorizeDto{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		Nonce:               query.Get("nonce"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+		Prompt:              query.Get("prompt"),
+	}
+
+	client, request, err := ctrl.oauthService.ValidateAuthorizeRequest(&input)
+	if err != nil {
+		// İstemci veya yönlendirme adresi doğrulanamadıysa kullanıcı bilinmeyen bir adrese gönderilmez
+		if request == nil {
+			respondWithOAuthError(w, err)
+			return
+		}
+		redirectWithOAuthError(w, r, request, err)
+		return
+	}
+
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		if input.Prompt == "none" {
+			redirectWithOAuthError(w, r, request, &services.OAuthError{Code: "login_required", Description: "oturum açılmamış"})
+			return
+		}
+		loginURL, err := url.Parse(ctrl.loginURL)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Giriş sayfası yapılandırılmamış")
+			return
+		}
+		loginQuery := loginURL.Query()
+		loginQuery.Set("return_to", ctrl.oauthService.Issuer()+r.URL.RequestURI())
+		loginURL.RawQuery = loginQuery.Encode()
+		http.Redirect(w, r, loginURL.String(), http.StatusFound)
+		return
+	}
+	request.UserID = userData["id"]
+	request.AuthTime = ctrl.sessionAuthTime(userData)
+
+	needsConsent, err := ctrl.oauthService.NeedsConsent(client, request.UserID, request.Scopes)
+	if err != nil {
+		redirectWithOAuthError(w, r, request, err)
+		return
+	}
+	if input.Prompt == "consent" {
+		needsConsent = true
+	}
+
+	if needsConsent {
+		if input.Prompt == "none" {
+			redirectWithOAuthError(w, r, request, &services.OAuthError{Code: "consent_required", Description: "kullanıcı onayı gerekli"})
+			return
+		}
+		consentID, err := ctrl.oauthService.CreateConsentRequest(request)
+		if err != nil {
+			redirectWithOAuthError(w, r, request, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		// Onay ekranı başka bir sitenin çerçevesi içinde gösterilemez (clickjacking)
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+		err = consentPage.Execute(w, consentPageData{
+			ClientName: client.Name,
+			Username:   userData["username"],
+			Scopes:     describeScopes(request.Scopes),
+			ConsentID:  consentID,
+			ActionURL:  "/oauth/authorize/consent",
+		})
+		if err != nil {
+			log.Println("Onay ekranı oluşturulamadı:", err)
+		}
+		return
+	}
+
+	code, err := ctrl.oauthService.IssueCode(request)
+	if err != nil {
+		redirectWithOAuthError(w, r, request, err)
+		return
+	}
+	redirectToClient(w, r, request, url.Values{"code": {code}})
+}
+
+// @Summary      OAuth2 Onay Kararı
+// @Description  Onay ekranındaki kararı uygular ve istemciye kod veya access_denied hatasıyla döner
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Param        consent_id  formData  string  true  "Onay isteği"
+// @Param        decision    formData  string  true  "approve veya deny"
+// @Success      302
+// @Failure      400  {object}  ErrorResponse
+// @Router       /oauth/authorize/consent [post]
+func (ctrl *OAuthController) Consent(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+
+	approved := r.PostForm.Get("decision") == "approve"
+	request, code, err := ctrl.oauthService.CompleteConsent(r.PostForm.Get("consent_id"), userData["id"], approved)
+	if err != nil {
+		if errors.Is(err, repository.ErrConsentRequestNotFound) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Println("Onay kaydedilemedi:", err)
+		respondWithError(w, http.StatusInternalServerError, "Onay kaydedilemedi")
+		return
+	}
+
+	if !approved {
+		redirectWithOAuthError(w, r, request, &services.OAuthError{Code: "access_denied", Description: "kullanıcı izni reddetti"})
+		return
+	}
+	redirectToClient(w, r, request, url.Values{"code": {code}})
+}
+
+// clientCredentials istemci kimlik bilgilerini HTTP Basic başlığından veya form alanlarından okur
+func clientCredentials(r *http.Request) (string, string) {
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		// RFC 6749 2.3.1: Basic başlığındaki değerler form-urlencoded olarak kodlanır
+		if decoded, err := url.QueryUnescape(clientID); err == nil {
+			clientID = decoded
+		}
+		if decoded, err := url.QueryUnescape(clientSecret); err == nil {
+			clientSecret = decoded
+		}
+		return clientID, clientSecret
+	}
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+}
+
+// @Summary      OAuth2 Token
+// @Description  authorization_code, refresh_token ve client_credentials grant'leri için token üretir
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type     formData  string  true   "authorization_code, refresh_token veya client_credentials"
+// @Param        code           formData  string  false  "Yetkilendirme kodu"
+// @Param        redirect_uri   formData  string  false  "Yetkilendirme isteğindeki yönlendirme adresi"
+// @Param        code_verifier  formData  string  false  "PKCE code_verifier"
+// @Param        refresh_token  formData  string  false  "Yenileme tokeni"
+// @Param        scope          formData  string  false  "İstenen kapsamlar"
+// @Success      200  {object}  dto.OAuthTokenResponse
+// @Failure      400  {object}  OAuthErrorResponse
+// @Failure      401  {object}  OAuthErrorResponse
+// @Router       /oauth/token [post]
+func (ctrl *OAuthController) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, &services.OAuthError{Code: "invalid_request", Description: "geçersiz form verisi"})
+		return
+	}
+
+	client, err := ctrl.clientService.AuthenticateClient(clientCredentials(r))
+	if err != nil {
+		respondWithOAuthError(w, err)
+		return
+	}
+
+	var response *dto.OAuthTokenResponse
+	switch r.PostForm.Get("grant_type") {
+	case models.GrantAuthorizationCode:
+		response, err = ctrl.oauthService.ExchangeCode(client, r.PostForm.Get("code"), r.PostForm.Get("redirect_uri"), r.PostForm.Get("code_verifier"))
+	case models.GrantRefreshToken:
+		response, err = ctrl.oauthService.RefreshTokens(client, r.PostForm.Get("refresh_token"), r.PostForm.Get("scope"))
+	case models.GrantClientCredentials:
+		response, err = ctrl.oauthService.ClientCredentials(client, r.PostForm.Get("scope"))
+	default:
+		err = &services.OAuthError{Code: "unsupported_grant_type", Description: "desteklenmeyen grant_type"}
+	}
+	if err != nil {
+		respondWithOAuthError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// @Summary      OIDC UserInfo
+// @Description  Bearer erişim tokeninin kapsamlarına göre kullanıcı bilgilerini döner
+// @Tags         OAuth
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer <access_token>"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  OAuthErrorResponse
+// @Router       /oauth/userinfo [get]
+func (ctrl *OAuthController) UserInfo(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" || token == r.Header.Get("Authorization") {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oauth"`)
+		respondWithJSON(w, http.StatusUnauthorized, OAuthErrorResponse{Error: "invalid_token", ErrorDescription: "erişim tokeni eksik"})
+		return
+	}
+
+	claims, err := ctrl.oauthService.VerifyAccessToken(token)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		respondWithJSON(w, http.StatusUnauthorized, OAuthErrorResponse{Error: "invalid_token", ErrorDescription: err.Error()})
+		return
+	}
+
+	info, err := ctrl.oauthService.UserInfo(claims)
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if errors.As(err, &oauthErr) {
+			status := http.StatusUnauthorized
+			if oauthErr.Code == "insufficient_scope" {
+				status = http.StatusForbidden
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer error="`+oauthErr.Code+`"`)
+			respondWithJSON(w, status, OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+			return
+		}
+		respondWithOAuthError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, info)
+}
+
+// @Summary      Token İptali
+// @Description  RFC 7009'a göre erişim veya yenileme tokenini iptal eder. Bilinmeyen tokenler için de 200 döner.
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Param        token            formData  string  true   "İptal edilecek token"
+// @Param        token_type_hint  formData  string  false  "access_token veya refresh_token"
+// @Success      200
+// @Failure      401  {object}  OAuthErrorResponse
+// @Router       /oauth/revoke [post]
+func (ctrl *OAuthController) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, &services.OAuthError{Code: "invalid_request", Description: "geçersiz form verisi"})
+		return
+	}
+	client, err := ctrl.clientService.AuthenticateClient(clientCredentials(r))
+	if err != nil {
+		respondWithOAuthError(w, err)
+		return
+	}
+	token := r.PostForm.Get("token")
+	if token == "" {
+		respondWithOAuthError(w, &services.OAuthError{Code: "invalid_request", Description: "token eksik"})
+		return
+	}
+
+	if err := ctrl.oauthService.Revoke(client, token, r.PostForm.Get("token_type_hint")); err != nil {
+		respondWithOAuthError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary      Token Introspection
+// @Description  RFC 7662'ye göre tokenin geçerliliğini ve bilgilerini döner; yalnızca gizli anahtarı olan istemciler kullanabilir
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token            formData  string  true   "Sorgulanan token"
+// @Param        token_type_hint  formData  string  false  "access_token veya refresh_token"
+// @Success      200  {object}  dto.OAuthIntrospectionResponse
+// @Failure      401  {object}  OAuthErrorResponse
+// @Router       /oauth/introspect [post]
+func (ctrl *OAuthController) Introspect(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, &services.OAuthError{Code: "invalid_request", Description: "geçersiz form verisi"})
+		return
+	}
+	client, err := ctrl.clientService.AuthenticateClient(clientCredentials(r))
+	if err != nil {
+		respondWithOAuthError(w, err)
+		return
+	}
+	if client.Public {
+		respondWithOAuthError(w, &services.OAuthError{Code: "invalid_client", Description: "public istemciler introspection kullanamaz"})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, http.StatusOK, ctrl.oauthService.Introspect(client, r.PostForm.Get("token"), r.PostForm.Get("token_type_hint")))
+}
+
+// @Summary      Verilen OAuth İzinleri
+// @Description  Kullanıcının erişim izni verdiği istemcileri listeler
+// @Tags         OAuth
+// @Produce      json
+// @Success      200  {array}   models.OAuthConsent
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/oauth/consents [get]
+func (ctrl *OAuthController) ListConsents(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	consents, err := ctrl.oauthService.ListConsents(userData["id"])
+	if err != nil {
+		log.Println("OAuth izinleri alınamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "İzinler alınamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, consents)
+}
+
+// @Summary      OAuth İznini Geri Al
+// @Description  İstemciye verilen izni ve istemcinin yenileme tokenlerini iptal eder
+// @Tags         OAuth
+// @Produce      json
+// @Param        clientID  path  string  true  "İstemci kimliği"
+// @Success      200  {object}  LogoutResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/oauth/consents/{clientID} [delete]
+func (ctrl *OAuthController) RevokeConsent(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	if err := ctrl.oauthService.RevokeConsent(userData["id"], chi.URLParam(r, "clientID")); err != nil {
+		log.Println("OAuth izni geri alınamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "İzin geri alınamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "İzin geri alındı"})
+}
+
+// @Summary      OAuth İstemcisi Kaydet
+// @Description  Yeni bir OAuth2 istemcisi oluşturur; gizli anahtar yalnızca bu yanıtta gösterilir
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateOAuthClientDto true "İstemci bilgileri"
+// @Success      201  {object}  OAuthClientCreatedResponse
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/admin/oauth/clients [post]
+func (ctrl *OAuthController) CreateClient(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	var input dto.CreateOAuthClientDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	if err := validate.Struct(input); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	client, secret, err := ctrl.clientService.CreateClient(&input, userData["id"])
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if errors.As(err, &oauthErr) {
+			respondWithError(w, http.StatusBadRequest, oauthErr.Description)
+			return
+		}
+		log.Println("OAuth istemcisi oluşturulamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "İstemci oluşturulamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, OAuthClientCreatedResponse{Client: client, ClientSecret: secret})
+}
+
+// @Summary      OAuth İstemcileri
+// @Description  Kayıtlı OAuth2 istemcilerini listeler
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {array}   models.OAuthClient
+// @Failure      403  {object}  ErrorResponse
+// @Router       /auth/admin/oauth/clients [get]
+func (ctrl *OAuthController) ListClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := ctrl.clientService.ListClients()
+	if err != nil {
+		log.Println("OAuth istemcileri alınamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "İstemciler alınamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, clients)
+}
+
+// @Summary      OAuth İstemcisini Sil
+// @Description  İstemciyi siler; istemci artık token alamaz
+// @Tags         Admin
+// @Produce      json
+// @Param        clientID  path  string  true  "İstemci kimliği"
+// @Success      200  {object}  LogoutResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/admin/oauth/clients/{clientID} [delete]
+func (ctrl *OAuthController) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	if err := ctrl.clientService.DeleteClient(chi.URLParam(r, "clientID")); err != nil {
+		if errors.Is(err, services.ErrOAuthClientNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Println("OAuth istemcisi silinemedi:", err)
+		respondWithError(w, http.StatusInternalServerError, "İstemci silinemedi")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "İstemci silindi"})
+}
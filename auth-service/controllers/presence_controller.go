@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+)
+
+type PresenceController struct {
+	presenceService *services.PresenceService
+}
+
+func NewPresenceController(presenceService *services.PresenceService) *PresenceController {
+	return &PresenceController{presenceService: presenceService}
+}
+
+// @Summary      Durum Güncelle
+// @Description  Kullanıcının durumunu (online, away, dnd, invisible) ve isteğe bağlı süreli özel durum metnini ayarlar
+// @Tags         Presence
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.UpdateStatusDto true "Durum bilgisi"
+// @Success      200  {object}  redisrepo.Presence
+// @Failure      400  {object}  ErrorResponse
+// @Router       /auth/updateStatus [post]
+func (ctrl *PresenceController) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	var input dto.UpdateStatusDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri")
+		return
+	}
+	if err := validate.Struct(input); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "Kullanıcı bilgisi bulunamadı")
+		return
+	}
+
+	presence, err := ctrl.presenceService.SetStatus(userData["id"], &input)
+	if err != nil || presence == nil {
+		log.Printf("Durum güncellenemedi: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Durum güncellenemedi")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, presence)
+}
+
+// @Summary      Durumum
+// @Description  Kullanıcının kendi durumunu, açık bağlantı sayısını ve son görülme zamanını döner
+// @Tags         Presence
+// @Produce      json
+// @Success      200  {object}  redisrepo.Presence
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/presence [get]
+func (ctrl *PresenceController) GetPresence(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	presence, err := ctrl.presenceService.GetPresence(userData["id"])
+	if err != nil {
+		log.Printf("Durum okunamadı: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Durum alınamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, presence)
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	sessionDuration = 24 * time.Hour
+	// deviceIDCookie giriş geçmişinde tarayıcıyı tanımak için kullanılan uzun ömürlü çerezdir
+	deviceIDCookie = "device_id"
+)
+
+type SessionListResponse struct {
+	Sessions []redisrepo.SessionMeta `json:"sessions"`
+}
+
+type SessionController struct {
+	sessionRepo *redisrepo.RedisRepository
+}
+
+func NewSessionController(sessionRepo *redisrepo.RedisRepository) *SessionController {
+	return &SessionController{sessionRepo: sessionRepo}
+}
+
+// startSession kullanıcı için Redis'te yeni bir oturum açar ve oturum çerezini yazar.
+// Tüm giriş yöntemleri buradan geçtiği için askıya alınmış veya yasaklanmış hesaplar burada reddedilir,
+// giriş, method ile belirtilen yöntemle birlikte denetim kaydına ve giriş geçmişine yazılır.
+func startSession(w http.ResponseWriter, r *http.Request, sessionRepo *redisrepo.RedisRepository, loginHistory *services.LoginHistoryService, user *dto.UserResponse, method string) (string, error) {
+	sessionID, err := createSession(w, r, sessionRepo, user)
+	if err == nil {
+		recordLogin(w, r, loginHistory, user, sessionID, method)
+	}
+	event := audit.Event{
+		Type:       audit.SignIn,
+		ActorID:    user.ID,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+		Details:    map[string]interface{}{"method": method},
+	}
+	if err != nil {
+		event.Result = audit.ResultFailure
+		event.Reason = err.Error()
+	}
+	audit.Record(r, event)
+	return sessionID, err
+}
+
+func createSession(w http.ResponseWriter, r *http.Request, sessionRepo *redisrepo.RedisRepository, user *dto.UserResponse) (string, error) {
+	if err := services.CheckAccountAccess(user.Restriction); err != nil {
+		return "", err
+	}
+
+	// Kullanıcı rollerini JSON formatına çevir
+	rolesJSON, err := json.Marshal(user.Roles)
+	if err != nil {
+		return "", err
+	}
+
+	userData := map[string]string{
+		"id":       user.ID,
+		"email":    user.Email,
+		"roles":    string(rolesJSON),
+		"username": user.Username,
+	}
+	meta := redisrepo.SessionMeta{
+		UserID: user.ID,
+		Device: r.UserAgent(),
+		IP:     middlewares.ClientIP(r),
+	}
+
+	sessionID, err := sessionRepo.CreateSession(userData, meta, sessionDuration)
+	if err != nil {
+		return "", err
+	}
+
+	setSessionCookie(w, sessionID, sessionDuration)
+	return sessionID, nil
+}
+
+// recordLogin girişi tarayıcının device_id çereziyle giriş geçmişine yazar; çerez yoksa oluşturulur.
+// Giriş geçmişi yazılamazsa oturum yine de açılır.
+func recordLogin(w http.ResponseWriter, r *http.Request, loginHistory *services.LoginHistoryService, user *dto.UserResponse, sessionID, method string) {
+	deviceID := ""
+	if cookie, err := r.Cookie(deviceIDCookie); err == nil {
+		deviceID = cookie.Value
+	}
+	if deviceID == "" || len(deviceID) > 128 {
+		var err error
+		if deviceID, err = services.NewDeviceID(); err != nil {
+			log.Println("Cihaz kimliği oluşturulamadı:", err)
+			return
+		}
+	}
+	// Çerez her girişte yenilenir, böylece düzenli kullanılan tarayıcılar tanınmaya devam eder
+	http.SetCookie(w, &http.Cookie{
+		Name:     deviceIDCookie,
+		Value:    deviceID,
+		Path:     "/",
+		MaxAge:   int(loginHistory.DeviceCookieTTL().Seconds()),
+		HttpOnly: true,
+		Secure:   false, // HTTPS kullanılıyorsa true yapılmalı
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	_, err := loginHistory.RecordLogin(services.LoginAttempt{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		SessionID: sessionID,
+		DeviceID:  deviceID,
+		UserAgent: r.UserAgent(),
+		IP:        middlewares.ClientIP(r),
+		Method:    method,
+	})
+	if err != nil {
+		log.Println("Giriş geçmişi kaydedilemedi:", err)
+	}
+}
+
+// setSessionCookie oturum çerezini verilen süreyle yazar
+func setSessionCookie(w http.ResponseWriter, sessionID string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session_id",
+		Value:    sessionID,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   false, // HTTPS kullanılıyorsa true yapılmalı
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// respondWithSessionError startSession hatasını istemciye döner
+func respondWithSessionError(w http.ResponseWriter, err error) {
+	var restricted *services.AccountRestrictedError
+	if errors.As(err, &restricted) {
+		respondWithError(w, http.StatusForbidden, restricted.Error())
+		return
+	}
+	log.Println("Redis oturum hatası:", err)
+	respondWithError(w, http.StatusInternalServerError, "Oturum kaydedilemedi")
+}
+
+// @Summary      Aktif Oturumlar
+// @Description  Kullanıcının tüm cihazlardaki aktif oturumlarını listeler
+// @Tags         Session
+// @Produce      json
+// @Success      200  {object}  SessionListResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/sessions [get]
+func (ctrl *SessionController) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	sessions, err := ctrl.sessionRepo.ListUserSessions(userData["id"])
+	if err != nil {
+		log.Println("Oturum listeleme hatası:", err)
+		respondWithError(w, http.StatusInternalServerError, "Oturumlar alınamadı")
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == userData["session_id"]
+	}
+
+	respondWithJSON(w, http.StatusOK, SessionListResponse{Sessions: sessions})
+}
+
+// @Summary      Oturum Sonlandır
+// @Description  Kullanıcının seçtiği bir oturumu sonlandırır
+// @Tags         Session
+// @Produce      json
+// @Param        sessionID path string true "Oturum ID"
+// @Success      200  {object}  LogoutResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/sessions/{sessionID} [delete]
+func (ctrl *SessionController) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	if err := ctrl.sessionRepo.RevokeSession(userData["id"], sessionID); err != nil {
+		if errors.Is(err, redisrepo.ErrSessionNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Println("Oturum sonlandırma hatası:", err)
+		respondWithError(w, http.StatusInternalServerError, "Oturum sonlandırılamadı")
+		return
+	}
+	audit.Record(r, audit.Event{
+		Type:       audit.SessionRevoked,
+		TargetType: audit.TargetSession,
+		TargetID:   sessionID,
+		Details:    map[string]interface{}{"userId": userData["id"]},
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Oturum sonlandırıldı",
+	})
+}
+
+// @Summary      Diğer Oturumları Sonlandır
+// @Description  Mevcut oturum dışındaki tüm oturumları sonlandırır
+// @Tags         Session
+// @Produce      json
+// @Success      200  {object}  LogoutResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/sessions/revokeOthers [post]
+func (ctrl *SessionController) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	count, err := ctrl.sessionRepo.RevokeUserSessions(userData["id"], userData["session_id"])
+	if err != nil {
+		log.Println("Oturum sonlandırma hatası:", err)
+		respondWithError(w, http.StatusInternalServerError, "Oturumlar sonlandırılamadı")
+		return
+	}
+	audit.Record(r, audit.Event{
+		Type:       audit.SessionRevoked,
+		TargetType: audit.TargetUser,
+		TargetID:   userData["id"],
+		Details:    map[string]interface{}{"scope": "others", "revoked": count},
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Diğer oturumlar sonlandırıldı",
+		"revoked": count,
+	})
+}
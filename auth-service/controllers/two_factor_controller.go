@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+)
+
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+type RecoveryCodesResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type TwoFactorController struct {
+	twoFactorService *services.TwoFactorService
+	sessionRepo      *redisrepo.RedisRepository
+	loginHistory     *services.LoginHistoryService
+}
+
+func NewTwoFactorController(sessionRepo *redisrepo.RedisRepository, loginHistory *services.LoginHistoryService) *TwoFactorController {
+	return &TwoFactorController{
+		twoFactorService: services.NewTwoFactorService(),
+		sessionRepo:      sessionRepo,
+		loginHistory:     loginHistory,
+	}
+}
+
+func twoFactorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidPassword),
+		errors.Is(err, services.ErrInvalidTwoFactorCode),
+		errors.Is(err, services.ErrChallengeNotFound):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, services.ErrTwoFactorNotEnabled),
+		errors.Is(err, services.ErrTwoFactorNotEnrolled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary      2FA Kurulumu Başlat
+// @Description  Yeni bir TOTP anahtarı ve otpauth:// adresi üretir
+// @Tags         TwoFactor
+// @Produce      json
+// @Success      200  {object}  TwoFactorEnrollResponse
+// @Failure      409  {object}  ErrorResponse
+// @Router       /auth/2fa/enroll [post]
+func (ctrl *TwoFactorController) Enroll(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	secret, uri, err := ctrl.twoFactorService.BeginEnrollment(userData["id"])
+	if err != nil {
+		respondWithError(w, twoFactorErrorStatus(err), err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, TwoFactorEnrollResponse{Secret: secret, OtpauthURI: uri})
+}
+
+// @Summary      2FA Kurulumunu Onayla
+// @Description  Authenticator uygulamasındaki ilk kod ile 2FA'yı etkinleştirir ve kurtarma kodlarını döner
+// @Tags         TwoFactor
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.TwoFactorCodeDto true "TOTP kodu"
+// @Success      200  {object}  RecoveryCodesResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/2fa/confirm [post]
+func (ctrl *TwoFactorController) Confirm(w http.ResponseWriter, r *http.Request) {
+	var input dto.TwoFactorCodeDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	codes, err := ctrl.twoFactorService.ConfirmEnrollment(userData["id"], input.Code)
+	if err != nil {
+		respondWithError(w, twoFactorErrorStatus(err), err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, RecoveryCodesResponse{
+		Message:       "İki adımlı doğrulama etkinleştirildi. Kurtarma kodlarını güvenli bir yerde saklayın",
+		RecoveryCodes: codes,
+	})
+}
+
+// @Summary      2FA Doğrula
+// @Description  Giriş sırasında dönen challenge tokeni ile TOTP veya kurtarma kodunu doğrular ve oturum açar
+// @Tags         TwoFactor
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.TwoFactorVerifyDto true "2FA doğrulama modeli"
+// @Success      200  {object}  ActivationResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/2fa/verify [post]
+func (ctrl *TwoFactorController) Verify(w http.ResponseWriter, r *http.Request) {
+	var input dto.TwoFactorVerifyDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.ChallengeToken == "" {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+
+	user, err := ctrl.twoFactorService.VerifyChallenge(input.ChallengeToken, input.Code, input.RecoveryCode)
+	if err != nil {
+		audit.Record(r, audit.Event{
+			Type:       audit.SignIn,
+			TargetType: audit.TargetAccount,
+			Result:     audit.ResultFailure,
+			Reason:     err.Error(),
+			Details:    map[string]interface{}{"method": "two_factor"},
+		})
+		respondWithError(w, twoFactorErrorStatus(err), err.Error())
+		return
+	}
+
+	response := dto.NewUserResponse(user)
+	if _, err := startSession(w, r, ctrl.sessionRepo, ctrl.loginHistory, response, "two_factor"); err != nil {
+		respondWithSessionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Giriş başarılı",
+		"user":    response,
+	})
+}
+
+// @Summary      2FA Kapat
+// @Description  Şifre tekrar girildikten sonra iki adımlı doğrulamayı kapatır
+// @Tags         TwoFactor
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.PasswordConfirmDto true "Şifre onayı"
+// @Success      200  {object}  LogoutResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/2fa/disable [post]
+func (ctrl *TwoFactorController) Disable(w http.ResponseWriter, r *http.Request) {
+	var input dto.PasswordConfirmDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	if err := ctrl.twoFactorService.Disable(userData["id"], input.Password); err != nil {
+		respondWithError(w, twoFactorErrorStatus(err), err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "İki adımlı doğrulama kapatıldı",
+	})
+}
+
+// @Summary      Kurtarma Kodlarını Yenile
+// @Description  Şifre tekrar girildikten sonra yeni kurtarma kodları üretir, eskileri geçersiz olur
+// @Tags         TwoFactor
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.PasswordConfirmDto true "Şifre onayı"
+// @Success      200  {object}  RecoveryCodesResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/2fa/recoveryCodes [post]
+func (ctrl *TwoFactorController) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var input dto.PasswordConfirmDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	codes, err := ctrl.twoFactorService.RegenerateRecoveryCodes(userData["id"], input.Password)
+	if err != nil {
+		respondWithError(w, twoFactorErrorStatus(err), err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, RecoveryCodesResponse{
+		Message:       "Yeni kurtarma kodları oluşturuldu",
+		RecoveryCodes: codes,
+	})
+}
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-chi/chi/v5"
+)
+
+type WebAuthnCredentialListResponse struct {
+	Credentials []models.WebAuthnCredential `json:"credentials"`
+}
+
+type WebAuthnController struct {
+	webauthnService  *services.WebAuthnService
+	twoFactorService *services.TwoFactorService
+	sessionRepo      *redisrepo.RedisRepository
+	loginHistory     *services.LoginHistoryService
+}
+
+func NewWebAuthnController(sessionRepo *redisrepo.RedisRepository, loginHistory *services.LoginHistoryService, cfg config.WebAuthnConfig) *WebAuthnController {
+	return &WebAuthnController{
+		webauthnService:  services.NewWebAuthnService(cfg),
+		twoFactorService: services.NewTwoFactorService(),
+		sessionRepo:      sessionRepo,
+		loginHistory:     loginHistory,
+	}
+}
+
+func webauthnErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrWebAuthnInvalid),
+		errors.Is(err, services.ErrWebAuthnCloned),
+		errors.Is(err, services.ErrWebAuthnCredentialNotFound),
+		errors.Is(err, repository.ErrWebAuthnSessionNotFound):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrWebAuthnUnsupportedKey):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrWebAuthnCredentialExists):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary      Passkey Kaydı Başlat
+// @Description  navigator.credentials.create() için seçenekleri ve tören kimliğini döner
+// @Tags         WebAuthn
+// @Produce      json
+// @Success      200  {object}  dto.WebAuthnCreationResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/webauthn/register/begin [post]
+func (ctrl *WebAuthnController) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	options, err := ctrl.webauthnService.BeginRegistration(userData["id"])
+	if err != nil {
+		log.Println("Passkey kaydı başlatılamadı:", err)
+		respondWithError(w, webauthnErrorStatus(err), err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusOK, options)
+}
+
+// @Summary      Passkey Kaydını Tamamla
+// @Description  Kimlik doğrulayıcının yanıtını doğrular ve passkey'i hesaba ekler
+// @Tags         WebAuthn
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.WebAuthnRegisterFinishDto true "Kayıt yanıtı"
+// @Success      201  {object}  models.WebAuthnCredential
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/webauthn/register/finish [post]
+func (ctrl *WebAuthnController) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	var input dto.WebAuthnRegisterFinishDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.SessionID == "" {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	credential, err := ctrl.webauthnService.FinishRegistration(userData["id"], &input)
+	if err != nil {
+		log.Println("Passkey kaydı doğrulanamadı:", err)
+		respondWithError(w, webauthnErrorStatus(err), err.Error())
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, credential)
+}
+
+// @Summary      Passkey ile Giriş Başlat
+// @Description  navigator.credentials.get() için seçenekleri ve tören kimliğini döner
+// @Tags         WebAuthn
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.WebAuthnLoginBeginDto false "İsteğe bağlı e-posta"
+// @Success      200  {object}  dto.WebAuthnRequestResponse
+// @Router       /auth/webauthn/login/begin [post]
+func (ctrl *WebAuthnController) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	var input dto.WebAuthnLoginBeginDto
+	// Gövde boş olabilir; bu durumda keşfedilebilir passkeyler kullanılır
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+			return
+		}
+	}
+
+	options, err := ctrl.webauthnService.BeginLogin(input.Email)
+	if err != nil {
+		log.Println("Passkey girişi başlatılamadı:", err)
+		respondWithError(w, http.StatusInternalServerError, "Passkey isteği oluşturulamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, options)
+}
+
+// @Summary      Passkey ile Giriş Tamamla
+// @Description  İmzayı doğrular ve oturum açar; PIN/biyometri doğrulaması yapılmadıysa 2FA ayrıca istenir
+// @Tags         WebAuthn
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.WebAuthnLoginFinishDto true "Giriş yanıtı"
+// @Success      200  {object}  ActivationResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/webauthn/login/finish [post]
+func (ctrl *WebAuthnController) FinishLogin(w http.ResponseWriter, r *http.Request) {
+	var input dto.WebAuthnLoginFinishDto
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.SessionID == "" {
+		respondWithError(w, http.StatusBadRequest, "Geçersiz veri formatı")
+		return
+	}
+
+	user, userVerified, err := ctrl.webauthnService.FinishLogin(&input)
+	if err != nil {
+		log.Println("Passkey girişi doğrulanamadı:", err)
+		status := webauthnErrorStatus(err)
+		if status == http.StatusNotFound {
+			status = http.StatusUnauthorized
+		}
+		respondWithError(w, status, services.ErrWebAuthnInvalid.Error())
+		return
+	}
+
+	// Kullanıcı doğrulaması yapılmış bir passkey iki faktörü birlikte sağlar;
+	// yalnızca varlık doğrulaması yapıldıysa etkin 2FA atlanmaz
+	response := dto.NewUserResponse(user)
+	if response.TwoFactorEnabled && !userVerified {
+		challengeToken, err := ctrl.twoFactorService.CreateChallenge(response.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Doğrulama isteği oluşturulamadı")
+			log.Println("2FA challenge hatası:", err)
+			return
+		}
+		respondWithJSON(w, http.StatusAccepted, map[string]interface{}{
+			"message":           "İki adımlı doğrulama gerekli",
+			"twoFactorRequired": true,
+			"challengeToken":    challengeToken,
+		})
+		return
+	}
+
+	if _, err := startSession(w, r, ctrl.sessionRepo, ctrl.loginHistory, response, "webauthn"); err != nil {
+		respondWithSessionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Giriş başarılı",
+		"user":    response,
+	})
+}
+
+// @Summary      Passkeyleri Listele
+// @Description  Kullanıcının kayıtlı passkeylerini döner
+// @Tags         WebAuthn
+// @Produce      json
+// @Success      200  {object}  WebAuthnCredentialListResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /auth/webauthn/credentials [get]
+func (ctrl *WebAuthnController) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	credentials, err := ctrl.webauthnService.ListCredentials(userData["id"])
+	if err != nil {
+		log.Println("Passkey listeleme hatası:", err)
+		respondWithError(w, http.StatusInternalServerError, "Passkeyler alınamadı")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, WebAuthnCredentialListResponse{Credentials: credentials})
+}
+
+// @Summary      Passkey Sil
+// @Description  Kullanıcının seçtiği passkey'i siler
+// @Tags         WebAuthn
+// @Produce      json
+// @Param        credentialID path string true "Passkey ID"
+// @Success      200  {object}  LogoutResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /auth/webauthn/credentials/{credentialID} [delete]
+func (ctrl *WebAuthnController) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	userData, ok := middlewares.GetUserData(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+		return
+	}
+
+	if err := ctrl.webauthnService.DeleteCredential(userData["id"], chi.URLParam(r, "credentialID")); err != nil {
+		if errors.Is(err, services.ErrWebAuthnCredentialNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Println("Passkey silme hatası:", err)
+		respondWithError(w, http.StatusInternalServerError, "Passkey silinemedi")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Passkey silindi",
+	})
+}
@@ -2,13 +2,14 @@
 package controllers
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
+	"time"
 
-	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
 	myWebsocket "github.com/MKMuhammetKaradag/go-microservice/auth-service/websocket"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
-	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
 	"github.com/gorilla/websocket"
 )
 
@@ -17,16 +18,14 @@ var upgrader = websocket.Upgrader{
 }
 
 type WebSocketController struct {
-	Hub       *myWebsocket.Hub
-	UserRepo  *repository.UserRepository
-	RedisRepo *redisrepo.RedisRepository
+	Hub      *myWebsocket.Hub
+	Presence *services.PresenceService
 }
 
-func NewWebSocketController(hub *myWebsocket.Hub, userRepo *repository.UserRepository, redisRepo *redisrepo.RedisRepository) *WebSocketController {
+func NewWebSocketController(hub *myWebsocket.Hub, presence *services.PresenceService) *WebSocketController {
 	return &WebSocketController{
-		Hub:       hub,
-		UserRepo:  userRepo,
-		RedisRepo: redisRepo,
+		Hub:      hub,
+		Presence: presence,
 	}
 }
 
@@ -52,41 +51,110 @@ func (wc *WebSocketController) HandleWebSocket(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Bağlantıyı kaydet; kullanıcının ilk bağlantısıysa kullanıcı çevrimiçi olur
+	connectionID, err := wc.Presence.Connect(userID)
+	if err != nil {
+		log.Printf("Bağlantı kaydedilemedi: %v", err)
+		conn.Close()
+		return
+	}
+
 	// Client'i hub'a kaydet
-	client := &myWebsocket.Client{UserID: userID, Conn: conn}
+	client := &myWebsocket.Client{ID: connectionID, UserID: userID, Conn: conn}
 	wc.Hub.Register <- client
 
-	// Kullanıcıyı online olarak işaretle
-	wc.setUserStatus(userID, "online")
-	defer wc.setUserStatus(userID, "offline")
-
-	// Bağlantı kapatıldığında kullanıcıyı offline yap
+	done := make(chan struct{})
 	defer func() {
+		close(done)
 		wc.Hub.Unregister <- client
-		conn.Close()
+		// Diğer cihazlardan bağlantı varsa kullanıcı çevrimiçi kalır
+		if err := wc.Presence.Disconnect(userID, connectionID); err != nil {
+			log.Printf("Bağlantı silinemedi: %v", err)
+		}
 	}()
 
+	// Pong veya mesaj gelmeyen bağlantı ConnectionTTL sonunda kapanır
+	ttl := wc.Presence.ConnectionTTL()
+	conn.SetReadDeadline(time.Now().Add(ttl))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(ttl))
+		if err := wc.Presence.Heartbeat(userID, connectionID); err != nil {
+			log.Printf("Kalp atışı kaydedilemedi: %v", err)
+		}
+		return nil
+	})
+	go wc.ping(client, done)
+
 	// Mesaj dinleme döngüsü
 	for {
-		_, _, err := conn.ReadMessage()
+		_, data, err := conn.ReadMessage()
 		if err != nil {
-			log.Println("WebSocket message error:", err)
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Println("WebSocket message error:", err)
+			}
 			break
 		}
+		conn.SetReadDeadline(time.Now().Add(ttl))
+
+		var message clientMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			continue
+		}
+		switch message.Type {
+		case "presence_subscribe":
+			wc.subscribe(client, message.UserIDs)
+		case "presence_unsubscribe":
+			if len(message.UserIDs) > 0 {
+				wc.Hub.Unwatch(client, message.UserIDs)
+			}
+		}
 	}
 }
 
-// Kullanıcı durumu güncelleme ve Redis ile senkronize etme
-func (wc *WebSocketController) setUserStatus(userID, status string) {
-	// Kullanıcı durumunu güncelle
-	err := wc.UserRepo.UpdateUserStatus(userID, status)
+// clientMessage istemcinin websocket üzerinden gönderdiği mesajdır
+type clientMessage struct {
+	Type    string   `json:"type"`
+	UserIDs []string `json:"userIds"`
+}
+
+// subscribe bağlantıyı istenen sohbet arkadaşlarının durumlarına abone eder ve güncel durumlarını gönderir;
+// userIds boşsa tüm sohbet arkadaşlarına abone olunur. Sohbet paylaşılmayan kullanıcılar sessizce atlanır.
+func (wc *WebSocketController) subscribe(client *myWebsocket.Client, userIDs []string) {
+	allowed, err := wc.Presence.Subscribable(client.UserID, userIDs, wc.Hub.WatchCount(client))
 	if err != nil {
-		log.Printf("Kullanıcı durumu güncellenemedi: %v", err)
+		log.Printf("Takip edilebilecek kullanıcılar alınamadı: %v", err)
+		client.WriteJSON(map[string]interface{}{
+			"event": "presence_error",
+			"error": "Durum aboneliği şu anda yapılamıyor",
+		})
+		return
 	}
 
-	// Redis'e durumu yayınla
-	err = wc.RedisRepo.PublishStatus(userID, status)
+	// Abonelik önce yapılır ki anlık görüntü ile ilk güncelleme arasında değişiklik kaçırılmasın
+	wc.Hub.Watch(client, allowed)
+	presences, err := wc.Presence.Snapshot(allowed)
 	if err != nil {
-		log.Printf("Redis durumu güncellenemedi: %v", err)
+		log.Printf("Kullanıcı durumları okunamadı: %v", err)
+		return
+	}
+	client.WriteJSON(map[string]interface{}{
+		"event":     "presence_snapshot",
+		"presences": presences,
+	})
+}
+
+// ping bağlantı kapanana kadar düzenli aralıklarla ping gönderir
+func (wc *WebSocketController) ping(client *myWebsocket.Client, done <-chan struct{}) {
+	ticker := time.NewTicker(wc.Presence.HeartbeatInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := client.WritePing(); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
 	}
 }
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+)
+
+type WellKnownController struct {
+	keyManager *services.KeyManager
+	issuer     string
+}
+
+func NewWellKnownController(keyManager *services.KeyManager, issuer string) *WellKnownController {
+	return &WellKnownController{keyManager: keyManager, issuer: issuer}
+}
+
+// OpenIDConfiguration OpenID Connect Discovery 1.0 belgesidir
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksURI                           string   `json:"jwks_uri"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// @Summary      JWKS
+// @Description  Tokenleri doğrulamak için kullanılan açık anahtarları yayınlar
+// @Tags         WellKnown
+// @Produce      json
+// @Success      200  {object}  services.JWKSet
+// @Router       /.well-known/jwks.json [get]
+func (ctrl *WellKnownController) JWKS(w http.ResponseWriter, r *http.Request) {
+	// Anahtarlar döndürülebildiği için kısa süreli önbelleğe izin ver
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, http.StatusOK, ctrl.keyManager.JWKS())
+}
+
+// @Summary      OpenID Connect Keşif Belgesi
+// @Description  Yerleşik OAuth2 / OIDC sunucusunun uç noktalarını ve desteklenen özelliklerini yayınlar
+// @Tags         WellKnown
+// @Produce      json
+// @Success      200  {object}  OpenIDConfiguration
+// @Router       /.well-known/openid-configuration [get]
+func (ctrl *WellKnownController) OpenIDConfiguration(w http.ResponseWriter, r *http.Request) {
+	// İmzalama algoritması anahtar döndürülürken değişebileceği için yayınlanan anahtarlardan alınır
+	algorithms := []string{}
+	seen := make(map[string]bool)
+	for _, key := range ctrl.keyManager.JWKS().Keys {
+		if !seen[key.Alg] {
+			seen[key.Alg] = true
+			algorithms = append(algorithms, key.Alg)
+		}
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, http.StatusOK, OpenIDConfiguration{
+		Issuer:                            ctrl.issuer,
+		AuthorizationEndpoint:             ctrl.issuer + "/oauth/authorize",
+		TokenEndpoint:                     ctrl.issuer + "/oauth/token",
+		UserinfoEndpoint:                  ctrl.issuer + "/oauth/userinfo",
+		JwksURI:                           ctrl.issuer + "/.well-known/jwks.json",
+		RevocationEndpoint:                ctrl.issuer + "/oauth/revoke",
+		IntrospectionEndpoint:             ctrl.issuer + "/oauth/introspect",
+		ScopesSupported:                   services.SupportedScopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{models.GrantAuthorizationCode, models.GrantRefreshToken, models.GrantClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  algorithms,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{services.PKCEMethodS256},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "azp",
+			"preferred_username", "name", "given_name", "family_name", "picture", "updated_at",
+			"email", "email_verified",
+		},
+	})
+}
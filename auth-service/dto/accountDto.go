@@ -0,0 +1,24 @@
+package dto
+
+type ChangePasswordDto struct {
+	CurrentPassword      string `json:"currentPassword"`
+	NewPassword          string `json:"newPassword"`
+	SignOutOtherSessions bool   `json:"signOutOtherSessions"`
+}
+
+type ChangeEmailDto struct {
+	NewEmail string `json:"newEmail"`
+	Password string `json:"password"`
+}
+
+type ConfirmEmailChangeDto struct {
+	Code string `json:"code"`
+}
+
+type DeleteAccountDto struct {
+	Password string `json:"password"`
+}
+
+type RevokeLoginDto struct {
+	Token string `json:"token"`
+}
@@ -5,3 +5,8 @@ type ActivationRequest struct {
 	ActivationToken string `json:"activationToken"`
 	ActivationCode  string `json:"activationCode"`
 }
+
+// ResendActivationRequest aktivasyon kodunu yeniden göndermek için kullanılır
+type ResendActivationRequest struct {
+	ActivationToken string `json:"activationToken"`
+}
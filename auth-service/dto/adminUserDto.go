@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+)
+
+// AdminUserSearchDto yöneticinin kullanıcı arama filtreleridir; sorgu parametrelerinden okunur
+type AdminUserSearchDto struct {
+	Query  string `json:"q" validate:"max=100"`                                              // Kullanıcı adı, e-posta, ad veya soyadında aranır
+	Role   string `json:"role" validate:"max=50"`                                            // Boşsa tüm roller
+	Status string `json:"status" validate:"omitempty,oneof=active suspended banned deleted"` // Boşsa silinmemiş tüm kullanıcılar
+	Page   int    `json:"page" validate:"min=1"`
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+}
+
+func (input *AdminUserSearchDto) SetDefaults() {
+	if input.Page == 0 {
+		input.Page = 1
+	}
+	if input.Limit == 0 {
+		input.Limit = 20
+	}
+}
+
+// RestrictUserDto hesabı askıya alma veya yasaklama isteğidir
+type RestrictUserDto struct {
+	Status         models.RestrictionStatus `json:"status" validate:"required,oneof=suspended banned"`
+	Reason         string                   `json:"reason" validate:"required,min=3,max=500"`
+	ExpiresInHours int                      `json:"expiresInHours" validate:"min=0"` // 0 ise kısıtlama yönetici kaldırana kadar sürer
+}
+
+type UpdateUserRolesDto struct {
+	Roles []models.UserRole `json:"roles" validate:"required,min=1,dive,required"`
+}
+
+// AdminUserResponse yöneticilere dönülen, hesap durumunu da içeren kullanıcı bilgisidir
+type AdminUserResponse struct {
+	UserResponse
+	Status      string                     `json:"status"`
+	LastSeenAt  *time.Time                 `json:"lastSeenAt,omitempty"`
+	IsDeleted   bool                       `json:"isDeleted"`
+	UpdatedAt   time.Time                  `json:"updatedAt"`
+	Restriction *models.AccountRestriction `json:"restriction,omitempty"`
+	Deletion    *models.AccountDeletion    `json:"deletion,omitempty"`
+}
+
+func NewAdminUserResponse(user *models.User) *AdminUserResponse {
+	return &AdminUserResponse{
+		UserResponse: *NewUserResponse(user),
+		Status:       user.Status,
+		LastSeenAt:   user.LastSeenAt,
+		IsDeleted:    user.IsDeleted,
+		UpdatedAt:    user.UpdatedAt,
+		Restriction:  user.Restriction,
+		Deletion:     user.Deletion,
+	}
+}
+
+type AdminUserListResponse struct {
+	Users []*AdminUserResponse `json:"users"`
+	Total int64                `json:"total"`
+	Page  int                  `json:"page"`
+	Limit int                  `json:"limit"`
+}
+
+type AdminUserDetailResponse struct {
+	User     *AdminUserResponse      `json:"user"`
+	Sessions []redisrepo.SessionMeta `json:"sessions"`
+}
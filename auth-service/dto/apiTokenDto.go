@@ -0,0 +1,7 @@
+package dto
+
+type CreateAPITokenDto struct {
+	Name          string   `json:"name" validate:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expiresInDays" validate:"min=0"` // 0 ise varsayılan süre kullanılır
+}
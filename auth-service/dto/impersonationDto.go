@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// StartImpersonationDto destek ekibinin kullanıcı adına oturum açma isteğidir
+type StartImpersonationDto struct {
+	Reason          string `json:"reason" validate:"required,min=3,max=500"` // Denetim kaydında görünür
+	DurationMinutes int    `json:"durationMinutes" validate:"min=0"`         // 0 ise varsayılan süre kullanılır
+}
+
+// ImpersonationInfo oturumun bir yönetici tarafından kullanıcı adına açıldığını belirtir
+type ImpersonationInfo struct {
+	ImpersonatorID    string    `json:"impersonatorId"`
+	ImpersonatorEmail string    `json:"impersonatorEmail"`
+	Reason            string    `json:"reason"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+type ImpersonationResponse struct {
+	User          *UserResponse      `json:"user"`
+	Impersonation *ImpersonationInfo `json:"impersonation"`
+}
+
+// MeResponse oturumdaki kullanıcının bilgisidir; kullanıcı adına açılmış oturumlarda Impersonation doludur
+type MeResponse struct {
+	ID            string             `json:"id"`
+	Username      string             `json:"username"`
+	Email         string             `json:"email"`
+	Roles         []string           `json:"roles"`
+	AuthMethod    string             `json:"authMethod"`
+	Impersonating bool               `json:"impersonating"`
+	Impersonation *ImpersonationInfo `json:"impersonation,omitempty"`
+}
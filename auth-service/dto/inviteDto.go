@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/MKMuhammetKaradag/go-microservice/shared/models"
+
+// CreateInviteDto yeni kayıt daveti isteğidir; e-posta verilirse davet bağlantısı o adrese gönderilir
+// ve davet yalnızca o adresle kullanılabilir
+type CreateInviteDto struct {
+	Email         string            `json:"email" validate:"omitempty,email"`
+	MaxUses       int               `json:"maxUses" validate:"min=0,max=1000"`        // 0 ise davet bir kez kullanılabilir
+	ExpiresInDays int               `json:"expiresInDays" validate:"min=0"`           // 0 ise varsayılan süre kullanılır
+	Roles         []models.UserRole `json:"roles" validate:"omitempty,dive,required"` // Boşsa varsayılan rol atanır
+}
+
+// SignUpDto kayıt isteğidir; davetle kayıt modunda davet kodu zorunludur
+type SignUpDto struct {
+	models.User
+	InviteCode string `json:"inviteCode"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type MagicLoginRequestDto struct {
+	Email string `json:"email"`
+}
+
+// MagicLoginVerifyDto e-postadaki bağlantının tokenini veya 6 haneli kodu taşır; ikisinden biri yeterlidir
+type MagicLoginVerifyDto struct {
+	Token string `json:"token"`
+	Code  string `json:"code"`
+}
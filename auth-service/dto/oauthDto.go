@@ -0,0 +1,49 @@
+package dto
+
+// OAuthAuthorizeDto /oauth/authorize sorgu parametreleridir
+type OAuthAuthorizeDto struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Prompt              string
+}
+
+// CreateOAuthClientDto yeni bir OAuth2 istemcisi kaydetmek için kullanılır
+type CreateOAuthClientDto struct {
+	Name         string   `json:"name" validate:"required,min=3,max=100"`
+	RedirectURIs []string `json:"redirectUris" validate:"dive,url"`
+	GrantTypes   []string `json:"grantTypes" validate:"required,min=1"`
+	Scopes       []string `json:"scopes" validate:"required,min=1"`
+	Public       bool     `json:"public"`
+	FirstParty   bool     `json:"firstParty"`
+}
+
+// OAuthTokenResponse RFC 6749 token yanıtıdır
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// OAuthIntrospectionResponse RFC 7662 introspection yanıtıdır
+type OAuthIntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Aud       string `json:"aud,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+type TwoFactorCodeDto struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorVerifyDto struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recoveryCode"`
+}
+
+type PasswordConfirmDto struct {
+	Password string `json:"password"`
+}
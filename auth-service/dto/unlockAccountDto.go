@@ -0,0 +1,6 @@
+package dto
+
+// UnlockAccountDto yöneticinin kilidini kaldıracağı hesabı belirtir
+type UnlockAccountDto struct {
+	Email string `json:"email"`
+}
@@ -1,5 +1,8 @@
 package dto
 
 type UpdateStatusDto struct {
-	Status string `json:"status"`
+	Status     string `json:"status" validate:"required,oneof=online away dnd invisible"`
+	StatusText string `json:"statusText" validate:"max=100"` // Boşsa özel durum metni silinir
+	// Özel durum metninin kaç dakika sonra silineceği; 0 ise süresizdir
+	StatusTextExpiresInMinutes int `json:"statusTextExpiresInMinutes" validate:"min=0,max=43200"`
 }
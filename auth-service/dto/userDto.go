@@ -15,11 +15,39 @@ import (
 // )
 
 type UserResponse struct {
-	ID        string            `json:"id"`
-	Username  string            `json:"username"`
-	Email     string            `json:"email"`
-	Roles     []models.UserRole `json:"roles"  `
-	FirstName string            `json:"firstName,omitempty"`
-	Age       int               `json:"age,omitempty"`
-	CreatedAt time.Time         `json:"createdAt"`
+	ID               string            `json:"id"`
+	Username         string            `json:"username"`
+	Email            string            `json:"email"`
+	Roles            []models.UserRole `json:"roles"  `
+	FirstName        string            `json:"firstName,omitempty"`
+	Age              int               `json:"age,omitempty"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	TwoFactorEnabled bool              `json:"twoFactorEnabled"`
+	// Restriction yalnızca hesap şu anda askıya alınmış veya yasaklanmışsa doludur; oturum açılmasını engeller
+	Restriction *models.AccountRestriction `json:"restriction,omitempty"`
+	// DeletionScheduledFor hesap silinmek üzere planlanmışsa silineceği zamandır; kullanıcı bu zamana kadar hesabını geri alabilir
+	DeletionScheduledFor *time.Time `json:"deletionScheduledFor,omitempty"`
+}
+
+// NewUserResponse veritabanındaki kullanıcıdan istemciye dönülecek yanıtı oluşturur
+func NewUserResponse(user *models.User) *UserResponse {
+	response := &UserResponse{
+		ID:               user.ID.Hex(),
+		Username:         user.Username,
+		Email:            user.Email,
+		Roles:            user.Roles,
+		FirstName:        user.FirstName,
+		CreatedAt:        user.CreatedAt,
+		TwoFactorEnabled: user.TwoFactor != nil && user.TwoFactor.Enabled,
+	}
+	if user.Age != nil {
+		response.Age = *user.Age
+	}
+	if user.Restriction.Active(time.Now()) {
+		response.Restriction = user.Restriction
+	}
+	if user.Deletion.Pending() {
+		response.DeletionScheduledFor = &user.Deletion.ScheduledFor
+	}
+	return response
 }
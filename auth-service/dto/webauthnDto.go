@@ -0,0 +1,104 @@
+package dto
+
+// WebAuthn seçenekleri ve yanıtları, tarayıcıdaki PublicKeyCredential JSON biçimini izler; tüm baytlar base64url kodludur
+
+type WebAuthnRelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type WebAuthnCredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// WebAuthnCreationOptions navigator.credentials.create({publicKey}) için seçenekler
+type WebAuthnCreationOptions struct {
+	RP                     WebAuthnRelyingPartyEntity     `json:"rp"`
+	User                   WebAuthnUserEntity             `json:"user"`
+	Challenge              string                         `json:"challenge"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+// WebAuthnRequestOptions navigator.credentials.get({publicKey}) için seçenekler
+type WebAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	Timeout          int64                          `json:"timeout"`
+	RPID             string                         `json:"rpId"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                         `json:"userVerification"`
+}
+
+type WebAuthnCreationResponse struct {
+	SessionID string                  `json:"sessionId"`
+	PublicKey WebAuthnCreationOptions `json:"publicKey"`
+}
+
+type WebAuthnRequestResponse struct {
+	SessionID string                 `json:"sessionId"`
+	PublicKey WebAuthnRequestOptions `json:"publicKey"`
+}
+
+type WebAuthnAttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject"`
+	Transports        []string `json:"transports"`
+}
+
+type WebAuthnAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle"`
+}
+
+type WebAuthnRegistrationCredential struct {
+	ID       string                      `json:"id"`
+	RawID    string                      `json:"rawId"`
+	Type     string                      `json:"type"`
+	Response WebAuthnAttestationResponse `json:"response"`
+}
+
+type WebAuthnAssertionCredential struct {
+	ID       string                    `json:"id"`
+	RawID    string                    `json:"rawId"`
+	Type     string                    `json:"type"`
+	Response WebAuthnAssertionResponse `json:"response"`
+}
+
+type WebAuthnRegisterFinishDto struct {
+	SessionID  string                         `json:"sessionId"`
+	Name       string                         `json:"name"`
+	Credential WebAuthnRegistrationCredential `json:"credential"`
+}
+
+type WebAuthnLoginBeginDto struct {
+	Email string `json:"email"` // Boş bırakılırsa cihazdaki keşfedilebilir passkeyler sunulur
+}
+
+type WebAuthnLoginFinishDto struct {
+	SessionID  string                      `json:"sessionId"`
+	Credential WebAuthnAssertionCredential `json:"credential"`
+}
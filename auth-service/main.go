@@ -5,8 +5,10 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
 	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
 	"github.com/MKMuhammetKaradag/go-microservice/auth-service/routes"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
@@ -26,6 +28,8 @@ func main() {
 
 // Auth servisini başlatan fonksiyon
 func startAuthService() error {
+	cfg := config.Load()
+
 	// Veritabanı bağlantılarını başlat
 	if err := initDatabases(); err != nil {
 		return fmt.Errorf("veritabanı hatası: %w", err)
@@ -38,8 +42,17 @@ func startAuthService() error {
 	}
 	defer rabbitMQ.Close()
 
+	// JWT imzalama anahtarlarını yükle ve döndürmeyi başlat
+	keyManager, err := services.NewKeyManager(cfg.JWT)
+	if err != nil {
+		return fmt.Errorf("JWT anahtar hatası: %w", err)
+	}
+	stopRotation := make(chan struct{})
+	defer close(stopRotation)
+	go keyManager.StartRotation(stopRotation)
+
 	// Sunucuyu başlat
-	return startServer(rabbitMQ)
+	return startServer(rabbitMQ, keyManager, cfg)
 }
 
 // Veritabanı bağlantılarını başlatan fonksiyon
@@ -77,7 +90,7 @@ func initDatabases() error {
 // RabbitMQ bağlantısını başlatan fonksiyon
 func initRabbitMQ() (*messaging.RabbitMQ, error) {
 	config := messaging.NewDefaultConfig()
-	config.RetryTypes = []string{"user_created"}
+	config.RetryTypes = []string{"user_created", "user_deletion_acknowledged", "data_export_part"}
 
 	rabbitMQ, err := messaging.NewRabbitMQ(config, messaging.AuthService)
 	if err != nil {
@@ -89,7 +102,7 @@ func initRabbitMQ() (*messaging.RabbitMQ, error) {
 }
 
 // HTTP sunucusunu başlatan fonksiyon
-func startServer(rabbitMQ *messaging.RabbitMQ) error {
+func startServer(rabbitMQ *messaging.RabbitMQ, keyManager *services.KeyManager, cfg config.Config) error {
 	port := 8080
 	fmt.Printf("Auth Service running on port %d\n", port)
 
@@ -97,9 +110,61 @@ func startServer(rabbitMQ *messaging.RabbitMQ) error {
 	userRepo := repository.NewUserRepository(collection)
 	redisRepo := redisrepo.NewRedisRepository(database.RedisClient)
 
+	// Bekleme süresi dolan hesapları sil ve diğer servislerin silme onaylarını dinle
+	dataExportRepo := repository.NewDataExportRepository()
+	accountDeletionService := services.NewAccountDeletionService(userRepo, dataExportRepo, repository.NewInviteRepository(), redisRepo, rabbitMQ, cfg.AccountDeletion)
+	go accountDeletionService.StartSweeper(nil)
+
+	// Diğer servislerden gelen dışa aktarma parçalarını topla, zaman aşımlarını ve süresi dolan arşivleri temizle
+	dataExportService := services.NewDataExportService(dataExportRepo, userRepo, redisRepo, rabbitMQ, cfg.DataExport)
+	go dataExportService.StartSweeper(nil)
+
+	err := rabbitMQ.ConsumeMessages(func(msg messaging.Message) error {
+		switch msg.Type {
+		case "user_deletion_acknowledged":
+			return handleUserDeletionAcknowledged(accountDeletionService, msg)
+		case "data_export_part":
+			return handleDataExportPart(dataExportService, msg)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("mesaj dinleyici başlatılamadı: %w", err)
+	}
+
 	// Router oluştur
-	r := routes.CreateServer(rabbitMQ, redisRepo, userRepo)
+	r := routes.CreateServer(rabbitMQ, redisRepo, userRepo, keyManager, accountDeletionService, dataExportService, cfg)
 
 	// HTTP sunucusunu başlat
 	return http.ListenAndServe(fmt.Sprintf(":%d", port), r)
 }
+
+// handleUserDeletionAcknowledged bir servisin silinen kullanıcının verisini temizlediği onayını kaydeder
+func handleUserDeletionAcknowledged(accountDeletionService *services.AccountDeletionService, msg messaging.Message) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("geçersiz mesaj formatı")
+	}
+
+	userID, _ := data["user_id"].(string)
+	service, _ := data["service"].(string)
+	if userID == "" || service == "" {
+		return fmt.Errorf("geçersiz silme onayı mesajı: %+v", data)
+	}
+	return accountDeletionService.Acknowledge(userID, service)
+}
+
+// handleDataExportPart bir servisin dışa aktarma isteği için gönderdiği veriyi kaydeder
+func handleDataExportPart(dataExportService *services.DataExportService, msg messaging.Message) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("geçersiz mesaj formatı")
+	}
+
+	exportID, _ := data["export_id"].(string)
+	service, _ := data["service"].(string)
+	if exportID == "" || service == "" {
+		return fmt.Errorf("geçersiz dışa aktarma parçası mesajı: %v", data["export_id"])
+	}
+	return dataExportService.ReceivePart(exportID, service, data["data"])
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	dataExportFilePart   = "part"
+	dataExportFileBundle = "bundle"
+)
+
+var ErrDataExportNotFound = errors.New("dışa aktarma bulunamadı veya bağlantının süresi doldu")
+
+// DataExportRepository dışa aktarma isteklerini authDB'de, servislerden gelen parçaları ve hazırlanan
+// zip arşivlerini GridFS'te saklar; böylece tüm servis örnekleri aynı dosyalara erişebilir
+type DataExportRepository struct {
+	collection *mongo.Collection
+	bucket     *gridfs.Bucket
+}
+
+func NewDataExportRepository() *DataExportRepository {
+	db, _ := database.GetDatabase(authDB)
+	bucket, _ := gridfs.NewBucket(db, options.GridFSBucket().SetName("data_export_files"))
+	return &DataExportRepository{
+		collection: db.Collection("data_exports"),
+		bucket:     bucket,
+	}
+}
+
+// Yeni dışa aktarma isteğini kaydetme
+func (r *DataExportRepository) Create(export *models.DataExport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.InsertOne(ctx, export)
+	if err != nil {
+		return err
+	}
+	export.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Kullanıcının dışa aktarma isteklerini en yeniden eskiye listeleme
+func (r *DataExportRepository) ListUserExports(userID primitive.ObjectID, limit int64) ([]models.DataExport, error) {
+	return r.find(bson.M{"userId": userID}, limit)
+}
+
+// Verilen durumda olup requestedAt veya expiresAt alanı before'dan önce olan istekleri listeleme
+func (r *DataExportRepository) FindStale(statuses []models.DataExportStatus, field string, before time.Time, limit int64) ([]models.DataExport, error) {
+	return r.find(bson.M{"status": bson.M{"$in": statuses}, field: bson.M{"$lte": before}}, limit)
+}
+
+func (r *DataExportRepository) find(filter bson.M, limit int64) ([]models.DataExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "requestedAt", Value: -1}}).SetLimit(limit)
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	exports := []models.DataExport{}
+	if err := cursor.All(ctx, &exports); err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// İndirme tokeninin özetiyle süresi dolmamış hazır arşivi bulma
+func (r *DataExportRepository) FindReadyByTokenHash(tokenHash string, now time.Time) (*models.DataExport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var export models.DataExport
+	err := r.collection.FindOne(ctx, bson.M{
+		"tokenHash": tokenHash,
+		"status":    models.DataExportReady,
+		"expiresAt": bson.M{"$gt": now},
+	}).Decode(&export)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// Servisin parçasını kaydetme ve güncel isteği döndürme; aynı servisten tekrar gelen parça öncekinin yerine geçer.
+// İstek artık parça beklemiyorsa ErrDataExportNotFound döner.
+func (r *DataExportRepository) SavePart(exportID primitive.ObjectID, service string, data []byte, now time.Time) (*models.DataExport, error) {
+	if err := r.deleteFiles(bson.M{"metadata.exportId": exportID, "metadata.kind": dataExportFilePart, "metadata.service": service}); err != nil {
+		return nil, err
+	}
+	uploadOptions := options.GridFSUpload().SetMetadata(bson.M{
+		"exportId": exportID,
+		"kind":     dataExportFilePart,
+		"service":  service,
+	})
+	fileID, err := r.bucket.UploadFromStream(service+".json", bytes.NewReader(data), uploadOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var export models.DataExport
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": exportID, "status": models.DataExportPending},
+		bson.M{"$set": bson.M{"parts." + service: now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&export)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			// Zaman aşımına uğramış veya arşivlenmiş isteğe geç gelen parça saklanmaz
+			r.bucket.DeleteContext(ctx, fileID)
+			return nil, ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// Tüm parçaları gelen isteği arşivlenmek üzere ayırma; başka bir servis örneği ayırdıysa false döner
+func (r *DataExportRepository) ClaimForAssembly(exportID primitive.ObjectID) (bool, error) {
+	return r.updateWhere(exportID, models.DataExportPending, bson.M{"$set": bson.M{"status": models.DataExportAssembling}})
+}
+
+// İsteğin parçalarını dosya adıyla birlikte okuma
+func (r *DataExportRepository) ReadParts(exportID primitive.ObjectID) (map[string][]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := r.bucket.FindContext(ctx, bson.M{"metadata.exportId": exportID, "metadata.kind": dataExportFilePart})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var files []gridfs.File
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+
+	parts := make(map[string][]byte, len(files))
+	for _, file := range files {
+		var buf bytes.Buffer
+		if _, err := r.bucket.DownloadToStream(file.ID, &buf); err != nil {
+			return nil, err
+		}
+		parts[file.Name] = buf.Bytes()
+	}
+	return parts, nil
+}
+
+// Hazırlanan arşivi kaydetme, parçaları silme ve isteği indirilebilir olarak işaretleme
+func (r *DataExportRepository) SaveBundle(export *models.DataExport, bundle []byte) error {
+	uploadOptions := options.GridFSUpload().SetMetadata(bson.M{
+		"exportId": export.ID,
+		"kind":     dataExportFileBundle,
+	})
+	fileID, err := r.bucket.UploadFromStream("export-"+export.ID.Hex()+".zip", bytes.NewReader(bundle), uploadOptions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.updateWhere(export.ID, models.DataExportAssembling, bson.M{"$set": bson.M{
+		"status":      models.DataExportReady,
+		"fileId":      fileID,
+		"size":        int64(len(bundle)),
+		"tokenHash":   export.TokenHash,
+		"completedAt": export.CompletedAt,
+		"expiresAt":   export.ExpiresAt,
+	}})
+	if err != nil {
+		return err
+	}
+	export.FileID = &fileID
+	export.Size = int64(len(bundle))
+	return r.deleteFiles(bson.M{"metadata.exportId": export.ID, "metadata.kind": dataExportFilePart})
+}
+
+// Arşivi okumak için açma
+func (r *DataExportRepository) OpenBundle(fileID primitive.ObjectID) (io.ReadCloser, error) {
+	stream, err := r.bucket.OpenDownloadStream(fileID)
+	if err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	return stream, nil
+}
+
+// İsteği verilen durumla kapatma ve tüm dosyalarını silme; hata nedeni boş olabilir.
+// Hazır arşivlerin tamamlanma zamanı korunur.
+func (r *DataExportRepository) Close(exportID primitive.ObjectID, from, to models.DataExportStatus, reason string, now time.Time) (bool, error) {
+	set := bson.M{"status": to}
+	if from != models.DataExportReady {
+		set["completedAt"] = now
+	}
+	if reason != "" {
+		set["error"] = reason
+	}
+	closed, err := r.updateWhere(exportID, from, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"fileId": "", "tokenHash": ""},
+	})
+	if err != nil || !closed {
+		return closed, err
+	}
+	return true, r.deleteFiles(bson.M{"metadata.exportId": exportID})
+}
+
+// Kullanıcının tüm dışa aktarma isteklerini ve dosyalarını silme
+func (r *DataExportRepository) DeleteUserExports(userID primitive.ObjectID) error {
+	exports, err := r.find(bson.M{"userId": userID}, 0)
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		if err := r.deleteFiles(bson.M{"metadata.exportId": export.ID}); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = r.collection.DeleteMany(ctx, bson.M{"userId": userID})
+	return err
+}
+
+func (r *DataExportRepository) deleteFiles(filter bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := r.bucket.FindContext(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var files []gridfs.File
+	if err := cursor.All(ctx, &files); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := r.bucket.DeleteContext(ctx, file.ID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateWhere isteği yalnızca verilen durumdaysa günceller ve güncellenip güncellenmediğini döner
+func (r *DataExportRepository) updateWhere(exportID primitive.ObjectID, status models.DataExportStatus, update bson.M) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": exportID, "status": status}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/go-redis/redis"
+)
+
+const (
+	pendingEmailChangePrefix         = "pending_email_change:"
+	pendingEmailChangeAttemptsPrefix = "pending_email_change_attempts:"
+)
+
+var ErrPendingEmailChangeNotFound = errors.New("e-posta değişikliği isteği bulunamadı veya süresi doldu")
+
+// EmailChangeRepository doğrulanmayı bekleyen e-posta değişikliklerini Redis'te TTL ile saklar.
+// Her kullanıcının aynı anda yalnızca bir bekleyen isteği olabilir.
+type EmailChangeRepository struct {
+	client *redis.Client
+}
+
+func NewEmailChangeRepository(client *redis.Client) *EmailChangeRepository {
+	return &EmailChangeRepository{client: client}
+}
+
+// Save kullanıcının önceki isteğinin yerine yenisini yazar ve deneme sayacını sıfırlar
+func (r *EmailChangeRepository) Save(change *models.PendingEmailChange, expiration time.Duration) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(pendingEmailChangePrefix+change.UserID, data, expiration)
+	pipe.Del(pendingEmailChangeAttemptsPrefix + change.UserID)
+	_, err = pipe.Exec()
+	return err
+}
+
+func (r *EmailChangeRepository) Get(userID string) (*models.PendingEmailChange, error) {
+	data, err := r.client.Get(pendingEmailChangePrefix + userID).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrPendingEmailChangeNotFound
+		}
+		return nil, err
+	}
+
+	var change models.PendingEmailChange
+	if err := json.Unmarshal([]byte(data), &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// Delete isteği siler; istek bu çağrıyla silindiyse true döner
+func (r *EmailChangeRepository) Delete(userID string) (bool, error) {
+	// Sonuç yalnızca kaydın kendisine bakar; deneme sayacı eşzamanlı bir istekle yeniden oluşmuş olabilir
+	pipe := r.client.TxPipeline()
+	deleted := pipe.Del(pendingEmailChangePrefix + userID)
+	pipe.Del(pendingEmailChangeAttemptsPrefix + userID)
+	if _, err := pipe.Exec(); err != nil {
+		return false, err
+	}
+	return deleted.Val() > 0, nil
+}
+
+// IncrementAttempts kod denemesi sayısını atomik olarak artırır ve yeni değeri döner.
+// Eşzamanlı denemelerin sınırı aşmaması için kod karşılaştırılmadan önce çağrılır.
+func (r *EmailChangeRepository) IncrementAttempts(userID string) (int64, error) {
+	key := pendingEmailChangeAttemptsPrefix + userID
+	attempts, err := r.client.Incr(key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	ttl, err := r.client.TTL(pendingEmailChangePrefix + userID).Result()
+	if err == nil && ttl > 0 {
+		r.client.Expire(key, ttl)
+	}
+	return attempts, nil
+}
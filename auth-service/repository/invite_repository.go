@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrInviteNotFound = errors.New("davet bulunamadı")
+
+// InviteRepository kayıt davetlerini authDB'de saklar; süresi dolan davetler expiresAt zamanında
+// MongoDB tarafından silinir
+type InviteRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInviteRepository() *InviteRepository {
+	collection, _ := database.GetCollection(authDB, "invites")
+	return &InviteRepository{collection: collection}
+}
+
+// usableInviteFilter iptal edilmemiş, süresi dolmamış ve kullanım sınırına ulaşmamış davetleri seçer.
+// E-posta adresine bağlı davetler yalnızca o adresle eşleşir.
+func usableInviteFilter(filter bson.M, email string, now time.Time) bson.M {
+	filter["revokedAt"] = bson.M{"$exists": false}
+	filter["expiresAt"] = bson.M{"$gt": now}
+	filter["$expr"] = bson.M{"$lt": bson.A{"$uses", "$maxUses"}}
+	filter["$or"] = bson.A{
+		bson.M{"email": bson.M{"$exists": false}},
+		bson.M{"email": email},
+	}
+	return filter
+}
+
+// Yeni daveti kaydetme
+func (r *InviteRepository) Create(invite *models.Invite) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.InsertOne(ctx, invite)
+	if err != nil {
+		return err
+	}
+	invite.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Kullanıcının hâlâ kullanılabilir davetlerini sayma
+func (r *InviteRepository) CountUsableUserInvites(userID primitive.ObjectID, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"userId":    userID,
+		"revokedAt": bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": now},
+		"$expr":     bson.M{"$lt": bson.A{"$uses", "$maxUses"}},
+	}
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// Davetleri en yeniden eskiye listeleme; userID boşsa tüm kullanıcıların davetleri döner
+func (r *InviteRepository) ListInvites(userID *primitive.ObjectID, limit int64) ([]models.Invite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if userID != nil {
+		filter["userId"] = *userID
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invites := []models.Invite{}
+	if err := cursor.All(ctx, &invites); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// Kodun özetiyle, verilen e-posta adresiyle kullanılabilecek daveti bulma
+func (r *InviteRepository) FindUsable(codeHash, email string, now time.Time) (*models.Invite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var invite models.Invite
+	err := r.collection.FindOne(ctx, usableInviteFilter(bson.M{"codeHash": codeHash}, email, now)).Decode(&invite)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrInviteNotFound
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// Consume davet hâlâ kullanılabiliyorsa kullanım sayısını tek işlemde artırır ve güncel daveti döner.
+// Aynı anda yapılan aktivasyonlar kullanım sınırını aşamaz.
+func (r *InviteRepository) Consume(inviteID primitive.ObjectID, email string, now time.Time) (*models.Invite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var invite models.Invite
+	err := r.collection.FindOneAndUpdate(ctx,
+		usableInviteFilter(bson.M{"_id": inviteID}, email, now),
+		bson.M{"$inc": bson.M{"uses": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&invite)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrInviteNotFound
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// Release hesap oluşturulamadığında Consume ile harcanan kullanımı geri verir
+func (r *InviteRepository) Release(inviteID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": inviteID, "uses": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"uses": -1}},
+	)
+	return err
+}
+
+// DetachCreator silinen hesabın davetlerinden oluşturan bilgisini kaldırır. Davetler gönderildikleri
+// kişiler için geçerli kalır; yalnızca yöneticiler tarafından listelenip iptal edilebilir.
+func (r *InviteRepository) DetachCreator(userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateMany(ctx, bson.M{"userId": userID}, bson.M{"$unset": bson.M{"userId": ""}})
+	return err
+}
+
+// Revoke daveti iptal eder. userID verilmişse yalnızca o kullanıcının daveti iptal edilebilir.
+func (r *InviteRepository) Revoke(inviteID primitive.ObjectID, userID *primitive.ObjectID, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": inviteID, "revokedAt": bson.M{"$exists": false}}
+	if userID != nil {
+		filter["userId"] = *userID
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revokedAt": now}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoginHistoryRepository kullanıcıların giriş kayıtlarını ve daha önce giriş yaptıkları cihazların
+// parmak izlerini saklar; her iki koleksiyondaki kayıtlar expiresAt zamanında MongoDB tarafından silinir
+type LoginHistoryRepository struct {
+	history *mongo.Collection
+	devices *mongo.Collection
+}
+
+func NewLoginHistoryRepository() *LoginHistoryRepository {
+	db, _ := database.GetDatabase(authDB)
+	return &LoginHistoryRepository{
+		history: db.Collection("login_history"),
+		devices: db.Collection("known_devices"),
+	}
+}
+
+// TouchDevice cihazın son görülme zamanını günceller ve cihaz ilk kez görüldüyse true döner.
+// Aynı anda yapılan iki girişten yalnızca biri cihazı yeni olarak görür.
+func (r *LoginHistoryRepository) TouchDevice(userID primitive.ObjectID, fingerprint string, now, expiresAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.devices.UpdateOne(ctx,
+		bson.M{"userId": userID, "fingerprint": fingerprint},
+		bson.M{
+			"$setOnInsert": bson.M{"firstSeenAt": now},
+			"$set":         bson.M{"lastSeenAt": now, "expiresAt": expiresAt},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// HasOtherDevices kullanıcının verilen parmak izi dışında bilinen bir cihazı olup olmadığını döner
+func (r *LoginHistoryRepository) HasOtherDevices(userID primitive.ObjectID, fingerprint string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := r.devices.CountDocuments(ctx,
+		bson.M{"userId": userID, "fingerprint": bson.M{"$ne": fingerprint}},
+		options.Count().SetLimit(1),
+	)
+	return count > 0, err
+}
+
+// Giriş kaydını ekleme
+func (r *LoginHistoryRepository) Create(record *models.LoginRecord) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.history.InsertOne(ctx, record)
+	if err != nil {
+		return err
+	}
+	record.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Kullanıcının son girişlerini en yeniden eskiye listeleme
+func (r *LoginHistoryRepository) ListUserLogins(userID primitive.ObjectID, limit int64) ([]models.LoginRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+	cursor, err := r.history.Find(ctx, bson.M{"userId": userID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	records := []models.LoginRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Girişi kullanıcı tarafından bildirilmiş olarak işaretleme
+func (r *LoginHistoryRepository) MarkReported(loginID primitive.ObjectID, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.history.UpdateOne(ctx,
+		bson.M{"_id": loginID, "reportedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"reportedAt": now}},
+	)
+	return err
+}
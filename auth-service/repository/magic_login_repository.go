@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/go-redis/redis"
+)
+
+const (
+	magicLoginPrefix         = "magic_login:"
+	magicLoginAttemptsPrefix = "magic_login_attempts:"
+	magicLoginCooldownPrefix = "magic_login_cooldown:"
+)
+
+var ErrMagicLoginNotFound = errors.New("giriş isteği bulunamadı veya süresi doldu")
+
+// MagicLoginRepository şifresiz giriş isteklerini Redis'te TTL ile saklar
+type MagicLoginRepository struct {
+	client *redis.Client
+}
+
+func NewMagicLoginRepository(client *redis.Client) *MagicLoginRepository {
+	return &MagicLoginRepository{client: client}
+}
+
+// Save isteği tarayıcı tokeninin özetiyle saklar
+func (r *MagicLoginRepository) Save(browserHash string, request *models.MagicLoginRequest, expiration time.Duration) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(magicLoginPrefix+browserHash, data, expiration).Err()
+}
+
+func (r *MagicLoginRepository) Get(browserHash string) (*models.MagicLoginRequest, error) {
+	data, err := r.client.Get(magicLoginPrefix + browserHash).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrMagicLoginNotFound
+		}
+		return nil, err
+	}
+
+	var request models.MagicLoginRequest
+	if err := json.Unmarshal([]byte(data), &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// Delete isteği siler; kayıt bu çağrıyla silindiyse true döner.
+// Aynı bağlantı veya kodla iki kez giriş yapılmasını engellemek için kullanılır.
+func (r *MagicLoginRepository) Delete(browserHash string) (bool, error) {
+	// Sonuç yalnızca kaydın kendisine bakar; deneme sayacı eşzamanlı bir istekle yeniden oluşmuş olabilir
+	pipe := r.client.TxPipeline()
+	deleted := pipe.Del(magicLoginPrefix + browserHash)
+	pipe.Del(magicLoginAttemptsPrefix + browserHash)
+	if _, err := pipe.Exec(); err != nil {
+		return false, err
+	}
+	return deleted.Val() > 0, nil
+}
+
+// IncrementAttempts kod denemesi sayısını atomik olarak artırır ve yeni değeri döner.
+// Eşzamanlı denemelerin sınırı aşmaması için kod karşılaştırılmadan önce çağrılır.
+func (r *MagicLoginRepository) IncrementAttempts(browserHash string) (int64, error) {
+	key := magicLoginAttemptsPrefix + browserHash
+	attempts, err := r.client.Incr(key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	ttl, err := r.client.TTL(magicLoginPrefix + browserHash).Result()
+	if err == nil && ttl > 0 {
+		r.client.Expire(key, ttl)
+	}
+	return attempts, nil
+}
+
+// AcquireCooldown e-posta için bekleme süresi başlatır; süre dolmadan yapılan isteklerde false döner
+func (r *MagicLoginRepository) AcquireCooldown(email string, cooldown time.Duration) (bool, error) {
+	return r.client.SetNX(magicLoginCooldownPrefix+email, 1, cooldown).Result()
+}
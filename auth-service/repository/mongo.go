@@ -37,6 +37,13 @@ func CreateUniqueIndexes(databaseName string, collectionName string) error {
 func InitAuthDatabase() {
 	CreateUserCollectionWithSchema()
 	CreatePasswordResetCollectionWithSchema()
+	CreateOAuthCollections()
+	CreateFederatedIdentityCollection()
+	CreateWebAuthnCredentialCollection()
+	CreateAPITokenCollection()
+	CreateDataExportCollection()
+	CreateLoginHistoryCollections()
+	CreateInviteCollection()
 	// CreateUniqueIndexes()
 	fmt.Println("Auth servisinin koleksiyonları oluşturuldu.")
 }
@@ -91,17 +98,17 @@ func CreatePasswordResetCollectionWithSchema() {
 
 	passwordResetSchema := bson.M{
 		"bsonType": "object",
-		"required": []string{"userId", "token", "expiresAt", "used"},
+		"required": []string{"userId", "tokenHash", "expiresAt", "used"},
 		"properties": bson.M{
 			"userId": bson.M{
 				"bsonType":    "objectId",
 				"description": "must be a valid ObjectId reference to users",
 			},
-			"token": bson.M{
+			"tokenHash": bson.M{
 				"bsonType":    "string",
-				"minLength":   32,
-				"maxLength":   256,
-				"description": "must be a valid reset token",
+				"minLength":   64,
+				"maxLength":   64,
+				"description": "must be the hex encoded SHA-256 hash of the reset token",
 			},
 			"expiresAt": bson.M{
 				"bsonType":    "date",
@@ -123,16 +130,230 @@ func CreatePasswordResetCollectionWithSchema() {
 	defer cancel()
 
 	if err := db.RunCommand(ctx, cmd).Err(); err != nil {
-		fmt.Println("PasswordReset collection already exists or error:", err)
+		// Koleksiyon zaten varsa eski şemayı (ham token alanı) güncel şema ile değiştir
+		modCmd := bson.D{
+			{Key: "collMod", Value: "passwordresets"},
+			{Key: "validator", Value: bson.M{"$jsonSchema": passwordResetSchema}},
+		}
+		if err := db.RunCommand(ctx, modCmd).Err(); err != nil {
+			fmt.Println("PasswordReset collection schema could not be updated:", err)
+		}
 	}
 
-	// Index oluşturma (Token'a göre hızlı arama için)
-	indexModel := mongo.IndexModel{
-		Keys:    bson.D{{Key: "token", Value: 1}},
-		Options: options.Index().SetUnique(true),
+	indexModels := []mongo.IndexModel{
+		// Token özetine göre hızlı arama için
+		{
+			Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// Süresi dolan kayıtları MongoDB kendisi siler
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		// Kullanıcının bekleyen diğer tokenlerini geçersiz kılmak için
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}},
+		},
 	}
-	_, err := db.Collection("passwordresets").Indexes().CreateOne(ctx, indexModel)
-	if err != nil {
+	if _, err := db.Collection("passwordresets").Indexes().CreateMany(ctx, indexModels); err != nil {
 		log.Printf("PasswordReset index oluşturulamadı: %v", err)
 	}
 }
+
+// OAuth istemcileri, kullanıcı onayları ve yenileme tokenleri için koleksiyon indeksleri
+func CreateOAuthCollections() {
+	db, _ := database.GetDatabase(authDB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := map[string][]mongo.IndexModel{
+		"oauth_clients": {
+			{
+				Keys:    bson.D{{Key: "clientId", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+		"oauth_consents": {
+			// Her kullanıcı-istemci çifti için tek bir onay kaydı tutulur
+			{
+				Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "clientId", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+		},
+		"oauth_refresh_tokens": {
+			{
+				Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0),
+			},
+			{
+				Keys: bson.D{{Key: "familyId", Value: 1}},
+			},
+			{
+				Keys: bson.D{{Key: "userId", Value: 1}, {Key: "clientId", Value: 1}},
+			},
+		},
+	}
+
+	for collection, models := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			log.Printf("%s index oluşturulamadı: %v", collection, err)
+		}
+	}
+}
+
+// Harici kimlik sağlayıcı hesaplarının yerel kullanıcılara bağlantıları
+func CreateFederatedIdentityCollection() {
+	db, _ := database.GetDatabase(authDB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexModels := []mongo.IndexModel{
+		// Sağlayıcıdaki bir hesap yalnızca bir kullanıcıya bağlanabilir
+		{
+			Keys:    bson.D{{Key: "provider", Value: 1}, {Key: "subject", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}},
+		},
+	}
+	if _, err := db.Collection("federated_identities").Indexes().CreateMany(ctx, indexModels); err != nil {
+		log.Printf("FederatedIdentity index oluşturulamadı: %v", err)
+	}
+}
+
+// Kullanıcıların kaydettiği passkeyler
+func CreateWebAuthnCredentialCollection() {
+	db, _ := database.GetDatabase(authDB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexModels := []mongo.IndexModel{
+		// Aynı kimlik doğrulayıcı anahtarı iki kez kaydedilemez
+		{
+			Keys:    bson.D{{Key: "credentialId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}},
+		},
+	}
+	if _, err := db.Collection("webauthn_credentials").Indexes().CreateMany(ctx, indexModels); err != nil {
+		log.Printf("WebAuthnCredential index oluşturulamadı: %v", err)
+	}
+}
+
+// Kişisel erişim tokenleri; süresi dolanlar TTL index ile silinir
+func CreateAPITokenCollection() {
+	db, _ := database.GetDatabase(authDB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := db.Collection("api_tokens").Indexes().CreateMany(ctx, indexModels); err != nil {
+		log.Printf("APIToken index oluşturulamadı: %v", err)
+	}
+}
+
+// Kişisel veri dışa aktarma istekleri; parçalar ve arşivler GridFS'te tutulur
+func CreateDataExportCollection() {
+	db, _ := database.GetDatabase(authDB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "requestedAt", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}},
+		},
+	}
+	if _, err := db.Collection("data_exports").Indexes().CreateMany(ctx, indexModels); err != nil {
+		log.Printf("DataExport index oluşturulamadı: %v", err)
+	}
+}
+
+func CreateLoginHistoryCollections() {
+	db, _ := database.GetDatabase(authDB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	historyIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := db.Collection("login_history").Indexes().CreateMany(ctx, historyIndexes); err != nil {
+		log.Printf("LoginHistory index oluşturulamadı: %v", err)
+	}
+
+	deviceIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "fingerprint", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := db.Collection("known_devices").Indexes().CreateMany(ctx, deviceIndexes); err != nil {
+		log.Printf("KnownDevice index oluşturulamadı: %v", err)
+	}
+}
+
+func CreateInviteCollection() {
+	db, _ := database.GetDatabase(authDB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "codeHash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	if _, err := db.Collection("invites").Indexes().CreateMany(ctx, indexModels); err != nil {
+		log.Printf("Invite index oluşturulamadı: %v", err)
+	}
+}
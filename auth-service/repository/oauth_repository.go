@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/go-redis/redis"
+)
+
+const (
+	oauthCodePrefix         = "oauth_code:"
+	oauthConsentPrefix      = "oauth_consent:"
+	oauthRevokedTokenPrefix = "oauth_revoked:"
+)
+
+var (
+	ErrAuthorizationCodeNotFound = errors.New("yetkilendirme kodu bulunamadı veya süresi doldu")
+	ErrConsentRequestNotFound    = errors.New("onay isteği bulunamadı veya süresi doldu")
+)
+
+// OAuthRepository yetkilendirme kodlarını, onay bekleyen istekleri ve iptal edilen
+// erişim tokenlerini Redis'te TTL ile saklar
+type OAuthRepository struct {
+	client *redis.Client
+}
+
+func NewOAuthRepository(client *redis.Client) *OAuthRepository {
+	return &OAuthRepository{client: client}
+}
+
+func (r *OAuthRepository) save(key string, request *models.OAuthAuthorizationRequest, expiration time.Duration) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(key, data, expiration).Err()
+}
+
+// take kaydı okur ve siler; aynı kayıt yalnızca bir kez alınabilir
+func (r *OAuthRepository) take(key string, notFound error) (*models.OAuthAuthorizationRequest, error) {
+	pipe := r.client.TxPipeline()
+	get := pipe.Get(key)
+	pipe.Del(key)
+	if _, err := pipe.Exec(); err != nil {
+		if err == redis.Nil {
+			return nil, notFound
+		}
+		return nil, err
+	}
+
+	var request models.OAuthAuthorizationRequest
+	if err := json.Unmarshal([]byte(get.Val()), &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// SaveCode yetkilendirme kodunu (özetiyle) saklar
+func (r *OAuthRepository) SaveCode(codeHash string, request *models.OAuthAuthorizationRequest, expiration time.Duration) error {
+	return r.save(oauthCodePrefix+codeHash, request, expiration)
+}
+
+// TakeCode kodu tek kullanımlık olarak alır
+func (r *OAuthRepository) TakeCode(codeHash string) (*models.OAuthAuthorizationRequest, error) {
+	return r.take(oauthCodePrefix+codeHash, ErrAuthorizationCodeNotFound)
+}
+
+// SaveConsentRequest onay ekranında bekleyen isteği saklar
+func (r *OAuthRepository) SaveConsentRequest(consentID string, request *models.OAuthAuthorizationRequest, expiration time.Duration) error {
+	return r.save(oauthConsentPrefix+consentID, request, expiration)
+}
+
+// TakeConsentRequest onay ekranındaki isteği tek kullanımlık olarak alır
+func (r *OAuthRepository) TakeConsentRequest(consentID string) (*models.OAuthAuthorizationRequest, error) {
+	return r.take(oauthConsentPrefix+consentID, ErrConsentRequestNotFound)
+}
+
+// GetConsentRequest onay ekranını çizmek için isteği silmeden okur
+func (r *OAuthRepository) GetConsentRequest(consentID string) (*models.OAuthAuthorizationRequest, error) {
+	data, err := r.client.Get(oauthConsentPrefix + consentID).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrConsentRequestNotFound
+		}
+		return nil, err
+	}
+
+	var request models.OAuthAuthorizationRequest
+	if err := json.Unmarshal([]byte(data), &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// RevokeAccessToken erişim tokeninin jti değerini token süresi dolana kadar iptal listesinde tutar
+func (r *OAuthRepository) RevokeAccessToken(jti string, remaining time.Duration) error {
+	if remaining <= 0 {
+		return nil
+	}
+	return r.client.Set(oauthRevokedTokenPrefix+jti, "1", remaining).Err()
+}
+
+// IsAccessTokenRevoked jti değerinin iptal edilip edilmediğini döner
+func (r *OAuthRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	count, err := r.client.Exists(oauthRevokedTokenPrefix + jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
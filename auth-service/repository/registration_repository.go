@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/go-redis/redis"
+)
+
+const (
+	pendingRegistrationPrefix         = "pending_registration:"
+	pendingRegistrationAttemptsPrefix = "pending_registration_attempts:"
+)
+
+var ErrPendingRegistrationNotFound = errors.New("aktivasyon isteği bulunamadı veya süresi doldu")
+
+// RegistrationRepository aktivasyon bekleyen kayıtları Redis'te TTL ile saklar
+type RegistrationRepository struct {
+	client *redis.Client
+}
+
+func NewRegistrationRepository(client *redis.Client) *RegistrationRepository {
+	return &RegistrationRepository{client: client}
+}
+
+// Save bekleyen kaydı verilen süre boyunca saklar ve deneme sayacını sıfırlar
+func (r *RegistrationRepository) Save(token string, registration *models.PendingRegistration, expiration time.Duration) error {
+	data, err := json.Marshal(registration)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(pendingRegistrationPrefix+token, data, expiration)
+	pipe.Del(pendingRegistrationAttemptsPrefix + token)
+	_, err = pipe.Exec()
+	return err
+}
+
+// Update bekleyen kaydı mevcut TTL'ini koruyarak günceller. Deneme sayacı sıfırlanmaz;
+// aksi halde her yeni kod isteği yeni deneme hakları kazandırırdı.
+func (r *RegistrationRepository) Update(token string, registration *models.PendingRegistration) error {
+	ttl, err := r.client.TTL(pendingRegistrationPrefix + token).Result()
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return ErrPendingRegistrationNotFound
+	}
+
+	data, err := json.Marshal(registration)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(pendingRegistrationPrefix+token, data, ttl).Err()
+}
+
+func (r *RegistrationRepository) Get(token string) (*models.PendingRegistration, error) {
+	data, err := r.client.Get(pendingRegistrationPrefix + token).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrPendingRegistrationNotFound
+		}
+		return nil, err
+	}
+
+	var registration models.PendingRegistration
+	if err := json.Unmarshal([]byte(data), &registration); err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
+
+// Delete bekleyen kaydı siler; kayıt bu çağrıyla silindiyse true döner.
+// Aynı token ile iki kez aktivasyon yapılmasını engellemek için kullanılır.
+func (r *RegistrationRepository) Delete(token string) (bool, error) {
+	// Sonuç yalnızca kaydın kendisine bakar; deneme sayacı eşzamanlı bir istekle yeniden oluşmuş olabilir
+	pipe := r.client.TxPipeline()
+	deleted := pipe.Del(pendingRegistrationPrefix + token)
+	pipe.Del(pendingRegistrationAttemptsPrefix + token)
+	if _, err := pipe.Exec(); err != nil {
+		return false, err
+	}
+	return deleted.Val() > 0, nil
+}
+
+// IncrementAttempts kod denemesi sayısını atomik olarak artırır ve yeni değeri döner.
+// Eşzamanlı denemelerin sınırı aşmaması için kod karşılaştırılmadan önce çağrılır.
+func (r *RegistrationRepository) IncrementAttempts(token string) (int64, error) {
+	key := pendingRegistrationAttemptsPrefix + token
+	attempts, err := r.client.Incr(key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	ttl, err := r.client.TTL(pendingRegistrationPrefix + token).Result()
+	if err == nil && ttl > 0 {
+		r.client.Expire(key, ttl)
+	}
+	return attempts, nil
+}
@@ -6,11 +6,15 @@ import (
 	"time"
 
 	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var ErrUserNotFound = errors.New("kullanıcı bulunamadı")
+
 type UserRepository struct {
 	collection *mongo.Collection
 }
@@ -19,8 +23,8 @@ func NewUserRepository(collection *mongo.Collection) *UserRepository {
 	return &UserRepository{collection: collection}
 }
 
-// Kullanıcı durumunu güncelleme
-func (r *UserRepository) UpdateUserStatus(userID string, status string) error {
+// Kullanıcının diğer kullanıcılara görünen durumunu ve son görülme zamanını güncelleme
+func (r *UserRepository) UpdateUserPresence(userID string, status string, lastSeenAt *time.Time) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -30,17 +34,18 @@ func (r *UserRepository) UpdateUserStatus(userID string, status string) error {
 		return errors.New("geçersiz kullanıcı ID'si")
 	}
 
-	// Durumu güncelle
-	filter := bson.M{"_id": objID}
-	update := bson.M{"$set": bson.M{"status": status}}
+	set := bson.M{"status": status}
+	if lastSeenAt != nil {
+		set["lastSeenAt"] = *lastSeenAt
+	}
 
-	result, err := r.collection.UpdateOne(ctx, filter, update)
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": set})
 	if err != nil {
 		return err
 	}
 
-	if result.ModifiedCount == 0 {
-		return errors.New("kullanıcı bulunamadı veya durum güncellenmedi")
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
 	}
 
 	return nil
@@ -63,7 +68,7 @@ func (r *UserRepository) FindUserByID(userID string) (*models.User, error) {
 	err = r.collection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("kullanıcı bulunamadı")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -81,7 +86,7 @@ func (r *UserRepository) FindUserByEmail(email string) (*models.User, error) {
 	err := r.collection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("kullanıcı bulunamadı")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -89,21 +94,178 @@ func (r *UserRepository) FindUserByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-// Tüm kullanıcıları listeleme (örnek)
-func (r *UserRepository) FindAllUsers() ([]models.User, error) {
+// Filtreye uyan kullanıcıları en yeniden eskiye sayfalayarak ve toplam sayısıyla birlikte listeleme
+func (r *UserRepository) SearchUsers(filter bson.M, skip, limit int64) ([]models.User, int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	var users []models.User
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
+	users := []models.User{}
 	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// Kullanıcının hesap kısıtlamasını ayarlama; restriction nil ise kısıtlama kaldırılır
+func (r *UserRepository) UpdateUserRestriction(userID string, restriction *models.AccountRestriction) error {
+	update := bson.M{"$unset": bson.M{"restriction": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	if restriction != nil {
+		update = bson.M{"$set": bson.M{"restriction": restriction, "updatedAt": time.Now()}}
+	}
+	return r.updateUser(userID, update)
+}
+
+// Kullanıcının rollerini güncelleme
+func (r *UserRepository) UpdateUserRoles(userID string, roles []models.UserRole) error {
+	return r.updateUser(userID, bson.M{"$set": bson.M{"roles": roles, "updatedAt": time.Now()}})
+}
+
+func (r *UserRepository) updateUser(userID string, update bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("geçersiz kullanıcı ID'si")
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// Hesap silme isteğini kaydetme; hesapta zaten bir silme isteği varsa false döner
+func (r *UserRepository) ScheduleUserDeletion(userID string, deletion *models.AccountDeletion) (bool, error) {
+	return r.updateUserWhere(userID,
+		bson.M{"deletion": bson.M{"$exists": false}, "isDeleted": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"deletion": deletion, "updatedAt": time.Now()}},
+	)
+}
+
+// Bekleme süresindeki silme isteğini iptal etme; iptal edilecek istek yoksa false döner
+func (r *UserRepository) CancelUserDeletion(userID string) (bool, error) {
+	return r.updateUserWhere(userID,
+		bson.M{"deletion.status": models.DeletionScheduled},
+		bson.M{"$unset": bson.M{"deletion": ""}, "$set": bson.M{"updatedAt": time.Now()}},
+	)
+}
+
+// Bekleme süresi dolan hesabı anonimleştirme; kullanıcı adı ve e-posta benzersiz kalacak şekilde
+// kullanıcı ID'sinden üretilir, şifre hiçbir bcrypt özetiyle eşleşmeyecek bir değerle değiştirilir.
+// Hesap başka bir servis örneği tarafından silinmişse veya geri alınmışsa false döner.
+func (r *UserRepository) EraseUser(userID string, now time.Time) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, errors.New("geçersiz kullanıcı ID'si")
+	}
+	return r.updateUserWhere(userID,
+		bson.M{"deletion.status": models.DeletionScheduled, "deletion.scheduledFor": bson.M{"$lte": now}},
+		bson.M{
+			"$set": bson.M{
+				"username":             "del_" + objID.Hex(),
+				"email":                objID.Hex() + "@deleted.invalid",
+				"password":             "!deleted-account",
+				"firstName":            "",
+				"lastName":             "",
+				"status":               redisrepo.PresenceOffline,
+				"isDeleted":            true,
+				"deletedAt":            now,
+				"updatedAt":            now,
+				"deletion.status":      models.DeletionErasing,
+				"deletion.erasedAt":    now,
+				"deletion.announcedAt": now,
+			},
+			"$unset": bson.M{
+				"age":          "",
+				"profilePhoto": "",
+				"lastSeenAt":   "",
+				"twoFactor":    "",
+				"restriction":  "",
+			},
+		},
+	)
+}
+
+// "user_deleted" mesajının yeniden gönderildiği zamanı kaydetme
+func (r *UserRepository) MarkUserDeletionAnnounced(userID string, now time.Time) error {
+	_, err := r.updateUserWhere(userID,
+		bson.M{"deletion.status": models.DeletionErasing},
+		bson.M{"$set": bson.M{"deletion.announcedAt": now}},
+	)
+	return err
+}
+
+// Servisin kullanıcının verisini sildiğini kaydetme ve güncel kullanıcıyı döndürme
+func (r *UserRepository) AcknowledgeUserDeletion(userID, service string, now time.Time) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("geçersiz kullanıcı ID'si")
+	}
+
+	var user models.User
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objID, "deletion.status": bson.M{"$in": []models.DeletionStatus{models.DeletionErasing, models.DeletionCompleted}}},
+		bson.M{"$set": bson.M{"deletion.acknowledgements." + service: now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
+	return &user, nil
+}
+
+// Tüm servislerin onay verdiği silme işlemini tamamlanmış olarak işaretleme
+func (r *UserRepository) CompleteUserDeletion(userID string, now time.Time) (bool, error) {
+	return r.updateUserWhere(userID,
+		bson.M{"deletion.status": models.DeletionErasing},
+		bson.M{"$set": bson.M{"deletion.status": models.DeletionCompleted, "deletion.completedAt": now}},
+	)
+}
 
-	return users, nil
+// updateUserWhere kullanıcıyı yalnızca condition sağlanıyorsa günceller ve güncellenip güncellenmediğini döner
+func (r *UserRepository) updateUserWhere(userID string, condition bson.M, update bson.M) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, errors.New("geçersiz kullanıcı ID'si")
+	}
+
+	filter := bson.M{"_id": objID}
+	for key, value := range condition {
+		filter[key] = value
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
 }
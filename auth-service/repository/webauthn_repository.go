@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/go-redis/redis"
+)
+
+const webauthnSessionPrefix = "webauthn_session:"
+
+var ErrWebAuthnSessionNotFound = errors.New("passkey isteği bulunamadı veya süresi doldu")
+
+// WebAuthnRepository kayıt ve giriş törenlerinin challenge'larını Redis'te TTL ile saklar
+type WebAuthnRepository struct {
+	client *redis.Client
+}
+
+func NewWebAuthnRepository(client *redis.Client) *WebAuthnRepository {
+	return &WebAuthnRepository{client: client}
+}
+
+func (r *WebAuthnRepository) SaveSession(sessionID string, session *models.WebAuthnSession, expiration time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(webauthnSessionPrefix+sessionID, data, expiration).Err()
+}
+
+// TakeSession töreni okur ve siler; aynı challenge yalnızca bir kez kullanılabilir
+func (r *WebAuthnRepository) TakeSession(sessionID string) (*models.WebAuthnSession, error) {
+	pipe := r.client.TxPipeline()
+	get := pipe.Get(webauthnSessionPrefix + sessionID)
+	pipe.Del(webauthnSessionPrefix + sessionID)
+	if _, err := pipe.Exec(); err != nil {
+		if err == redis.Nil {
+			return nil, ErrWebAuthnSessionNotFound
+		}
+		return nil, err
+	}
+
+	var session models.WebAuthnSession
+	if err := json.Unmarshal([]byte(get.Val()), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -1,12 +1,20 @@
 package routes
 
 import (
+	"log"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
 	"github.com/MKMuhammetKaradag/go-microservice/auth-service/controllers"
 
 	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/services"
 	"github.com/MKMuhammetKaradag/go-microservice/auth-service/websocket"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/authclient"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -14,56 +22,272 @@ import (
 )
 
 // CreateServer: Router oluşturur ve tüm endpointleri ekler
-func CreateServer(rabbitMQ *messaging.RabbitMQ, sessionRepo *redisrepo.RedisRepository, userRepo *repository.UserRepository) *chi.Mux {
-	authController := controllers.NewAuthController(rabbitMQ, sessionRepo)
-	authMiddleware := middlewares.NewAuthMiddleware(sessionRepo)
+func CreateServer(rabbitMQ *messaging.RabbitMQ, sessionRepo *redisrepo.RedisRepository, userRepo *repository.UserRepository, keyManager *services.KeyManager, accountDeletionService *services.AccountDeletionService, dataExportService *services.DataExportService, cfg config.Config) *chi.Mux {
+	loginGuard := services.NewLoginGuard(sessionRepo, cfg.BruteForce)
+	passwordPolicy := services.NewPasswordPolicy(cfg.PasswordPolicy)
+	loginHistoryService := services.NewLoginHistoryService(repository.NewLoginHistoryRepository(), sessionRepo, rabbitMQ, cfg.LoginHistory)
+	inviteService := services.NewInviteService(repository.NewInviteRepository(), rabbitMQ, cfg.Registration)
+	authController := controllers.NewAuthController(rabbitMQ, sessionRepo, loginHistoryService, loginGuard, passwordPolicy, inviteService, cfg)
+	sessionController := controllers.NewSessionController(sessionRepo)
+	accountController := controllers.NewAccountController(rabbitMQ, sessionRepo, passwordPolicy, accountDeletionService)
+	twoFactorController := controllers.NewTwoFactorController(sessionRepo, loginHistoryService)
+	magicLinkController := controllers.NewMagicLinkController(rabbitMQ, sessionRepo, loginHistoryService, loginGuard, cfg.MagicLink)
+	webauthnController := controllers.NewWebAuthnController(sessionRepo, loginHistoryService, cfg.WebAuthn)
+	// Oturumların sahibi auth-service olduğundan Redis'ten önbelleksiz okunur; çıkış ve iptal işlemleri hemen etkili olur
+	authMiddleware := middlewares.NewAuthMiddleware(authclient.NewClient(sessionRepo, authclient.Config{Mode: authclient.ModeRedis})).
+		WithImpersonationAudit(sessionRepo, "auth")
+	authorizer := middlewares.NewAuthorizer(middlewares.PermissionsFilePath())
+	adminController := controllers.NewAdminController(authorizer, loginGuard, services.NewUserAdminService(userRepo, sessionRepo), rabbitMQ)
+	rateLimiter := middlewares.NewRateLimiter(sessionRepo, "auth", publicRateLimitRules()...)
+	oauthClientService := services.NewOAuthClientService()
+	oauthService := services.NewOAuthService(oauthClientService, services.NewJwtHelperService(keyManager, cfg.JWT.Issuer), cfg)
+	oauthController := controllers.NewOAuthController(oauthService, oauthClientService, sessionRepo, cfg.OAuth.LoginURL)
+	federationController := controllers.NewFederationController(services.NewFederationService(inviteService, rabbitMQ, cfg.Federation), sessionRepo, loginHistoryService)
+	apiTokenService := services.NewAPITokenService(sessionRepo, cfg.APIToken)
+	apiTokenController := controllers.NewAPITokenController(apiTokenService)
+	presenceService := services.NewPresenceService(sessionRepo, userRepo, services.NewPresenceAudience(cfg.Presence, cfg.Internal.Secret), cfg.Presence)
+	presenceController := controllers.NewPresenceController(presenceService)
+	impersonationController := controllers.NewImpersonationController(services.NewImpersonationService(userRepo, sessionRepo, authorizer, cfg.Impersonation))
+	dataExportController := controllers.NewDataExportController(dataExportService)
+	loginHistoryController := controllers.NewLoginHistoryController(loginHistoryService)
+	inviteController := controllers.NewInviteController(inviteService, authorizer)
+	auditStore := audit.NewStore()
+	if err := auditStore.EnsureIndexes(); err != nil {
+		log.Printf("Denetim kaydı indeksleri oluşturulamadı: %v", err)
+	}
+	auditController := controllers.NewAuditController(auditStore)
 	hub := websocket.NewHub()
 
 	go hub.Run()
 	go hub.ListenRedisStatus(sessionRepo)
+	go authorizer.WatchConfig(10*time.Second, nil)
+	go apiTokenService.StartSync(nil)
+	go presenceService.StartCleanup(nil)
 
-	wsController := controllers.NewWebSocketController(hub, userRepo, sessionRepo)
+	wsController := controllers.NewWebSocketController(hub, presenceService)
 	r := chi.NewRouter()
 
 	// Global Middleware'ler
 	r.Use(middlewares.PrometheusMiddleware)
+	r.Use(authorizer.Middleware)
+	r.Use(audit.NewRecorder(auditStore, "auth").Middleware)
 
 	// Servis Route'larını Gruplama
 	registerMetricsRoutes(r)
-	registerAuthRoutes(r, authController, authMiddleware, wsController)
+	registerWellKnownRoutes(r, controllers.NewWellKnownController(keyManager, cfg.JWT.Issuer))
+	registerInternalRoutes(r, controllers.NewIntrospectionController(sessionRepo), cfg.Internal.Secret)
+	registerAuthRoutes(r, authController, sessionController, accountController, twoFactorController, magicLinkController, webauthnController, oauthController, apiTokenController, presenceController, impersonationController, dataExportController, loginHistoryController, inviteController, authMiddleware, rateLimiter, wsController)
+	registerOAuthRoutes(r, oauthController, authMiddleware, rateLimiter)
+	registerFederationRoutes(r, federationController, rateLimiter)
+	registerAdminRoutes(r, adminController, oauthController, apiTokenController, impersonationController, auditController, inviteController, authMiddleware)
 	registerSwaggerRoutes(r)
 
 	return r
 }
 
+// Yalnızca diğer servislerin çağırdığı iç endpointleri ekler; API gateway bu yolları dışarı açmaz
+func registerInternalRoutes(r *chi.Mux, introspectionController *controllers.IntrospectionController, secret string) {
+	if secret == "" {
+		log.Println("INTERNAL_API_SECRET tanımlı değil, iç endpointler kapalı")
+	}
+	r.Route("/internal", func(r chi.Router) {
+		r.Use(middlewares.RequireInternalSecret(secret))
+		r.Post("/introspect", introspectionController.Introspect)
+	})
+}
+
+// Oturum gerektirmeyen endpointler için IP bazlı istek sınırları
+func publicRateLimitRules() []middlewares.RateLimitRule {
+	return []middlewares.RateLimitRule{
+		{Name: "sign_in", Method: "POST", Pattern: "/auth/signIn", Algorithm: middlewares.SlidingWindow, Limit: 10, Window: time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "sign_up", Method: "POST", Pattern: "/auth/signUp", Algorithm: middlewares.SlidingWindow, Limit: 5, Window: time.Hour, KeyFunc: middlewares.KeyByIP},
+		{Name: "activation", Method: "POST", Pattern: "/auth/activationUser", Algorithm: middlewares.SlidingWindow, Limit: 10, Window: time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "resend_activation", Method: "POST", Pattern: "/auth/resendActivationCode", Algorithm: middlewares.SlidingWindow, Limit: 5, Window: 10 * time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "forgot_password", Method: "POST", Pattern: "/auth/forgotPassword", Algorithm: middlewares.SlidingWindow, Limit: 5, Window: 10 * time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "reset_password", Method: "POST", Pattern: "/auth/resetPassword", Algorithm: middlewares.SlidingWindow, Limit: 10, Window: 10 * time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "two_factor_verify", Method: "POST", Pattern: "/auth/2fa/verify", Algorithm: middlewares.TokenBucket, Limit: 10, Window: time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "magic_login_request", Method: "POST", Pattern: "/auth/magic/request", Algorithm: middlewares.SlidingWindow, Limit: 5, Window: 10 * time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "magic_login_verify", Method: "POST", Pattern: "/auth/magic/verify", Algorithm: middlewares.TokenBucket, Limit: 10, Window: time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "webauthn_login", Method: "POST", Pattern: "/auth/webauthn/login/*", Algorithm: middlewares.SlidingWindow, Limit: 30, Window: time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "login_alert_revoke", Method: "POST", Pattern: "/auth/logins/revoke", Algorithm: middlewares.SlidingWindow, Limit: 10, Window: 10 * time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "data_export_download", Method: "GET", Pattern: "/auth/export/download", Algorithm: middlewares.SlidingWindow, Limit: 20, Window: 10 * time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "federated_login", Method: "GET", Pattern: "/auth/federated/{provider}/*", Algorithm: middlewares.SlidingWindow, Limit: 20, Window: time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "oauth_token", Method: "POST", Pattern: "/oauth/token", Algorithm: middlewares.SlidingWindow, Limit: 60, Window: time.Minute, KeyFunc: middlewares.KeyByIP},
+		{Name: "oauth_introspect", Method: "POST", Pattern: "/oauth/introspect", Algorithm: middlewares.TokenBucket, Limit: 300, Window: time.Minute, KeyFunc: middlewares.KeyByIP},
+	}
+}
+
 // Prometheus ve metrik endpointleri ekler
 func registerMetricsRoutes(r *chi.Mux) {
 	r.Mount("/metrics", promhttp.Handler())
 }
 
+// Tokenleri doğrulayacak servisler için açık anahtarları yayınlar
+func registerWellKnownRoutes(r *chi.Mux, wellKnownController *controllers.WellKnownController) {
+	r.Get("/.well-known/jwks.json", wellKnownController.JWKS)
+	r.Get("/.well-known/openid-configuration", wellKnownController.OpenIDConfiguration)
+}
+
+// OAuth2 / OpenID Connect sunucusunun standart endpointlerini ekler
+func registerOAuthRoutes(r *chi.Mux, oauthController *controllers.OAuthController, authMiddleware *middlewares.AuthMiddleware, rateLimiter *middlewares.RateLimiter) {
+	r.Route("/oauth", func(r chi.Router) {
+		r.Use(middlewares.Logger)
+		r.Use(rateLimiter.Middleware)
+
+		// Oturum yoksa giriş sayfasına yönlendirilir
+		// Kullanıcı adına açılmış oturumlar üçüncü taraf uygulamalara yetki veremez
+		r.With(authMiddleware.OptionalAuthenticate, middlewares.BlockImpersonation).Get("/authorize", oauthController.Authorize)
+		r.With(authMiddleware.Authenticate, middlewares.BlockImpersonation).Post("/authorize/consent", oauthController.Consent)
+
+		// İstemci kimlik doğrulaması veya Bearer token ile çalışan endpointler
+		r.Post("/token", oauthController.Token)
+		r.Get("/userinfo", oauthController.UserInfo)
+		r.Post("/userinfo", oauthController.UserInfo)
+		r.Post("/revoke", oauthController.Revoke)
+		r.Post("/introspect", oauthController.Introspect)
+	})
+}
+
 // Auth ile ilgili tüm endpointleri ekler
-func registerAuthRoutes(r *chi.Mux, authController *controllers.AuthController, authMiddleware *middlewares.AuthMiddleware, wsController *controllers.WebSocketController) {
+func registerAuthRoutes(r *chi.Mux, authController *controllers.AuthController, sessionController *controllers.SessionController, accountController *controllers.AccountController, twoFactorController *controllers.TwoFactorController, magicLinkController *controllers.MagicLinkController, webauthnController *controllers.WebAuthnController, oauthController *controllers.OAuthController, apiTokenController *controllers.APITokenController, presenceController *controllers.PresenceController, impersonationController *controllers.ImpersonationController, dataExportController *controllers.DataExportController, loginHistoryController *controllers.LoginHistoryController, inviteController *controllers.InviteController, authMiddleware *middlewares.AuthMiddleware, rateLimiter *middlewares.RateLimiter, wsController *controllers.WebSocketController) {
 	r.Route("/auth", func(r chi.Router) {
 		r.Use(middlewares.Logger) // Tüm /auth endpointlerinde logger middleware aktif olacak
+		r.Use(rateLimiter.Middleware)
 
 		// Public endpointler
+		r.Get("/registration", inviteController.RegistrationInfo)
 		r.Post("/signUp", authController.SignUp)
 		r.Post("/activationUser", authController.ActivationUser)
+		r.Post("/resendActivationCode", authController.ResendActivationCode)
 		r.Post("/signIn", authController.SignIn)
 		r.Post("/forgotPassword", authController.ForgotPassword)
 		r.Post("/resetPassword", authController.ResetPassword)
+		r.Post("/2fa/verify", twoFactorController.Verify)
+		r.Post("/magic/request", magicLinkController.Request)
+		r.Post("/magic/verify", magicLinkController.Verify)
+		r.Post("/webauthn/login/begin", webauthnController.BeginLogin)
+		r.Post("/webauthn/login/finish", webauthnController.FinishLogin)
+		// İndirme bağlantısı e-postadan açıldığı için oturum yerine bağlantıdaki token doğrulanır
+		r.Get("/export/download", dataExportController.Download)
+		// Yeni cihaz uyarısındaki bağlantı, girişi yapan kişi değil hesabın sahibi tarafından açılır
+		r.Post("/logins/revoke", loginHistoryController.RevokeLogin)
 
 		// Protected Routes (JWT Authentication Gerekli)
 		r.Group(func(protectedRouter chi.Router) {
 			protectedRouter.Use(authMiddleware.Authenticate)
-			protectedRouter.Post("/logout", authController.Logout)
-			protectedRouter.Get("/me", authController.Logout)
-			protectedRouter.Post("/updateStatus", authController.UpdateStatus)
+			protectedRouter.Get("/me", authController.Me)
+			protectedRouter.Post("/impersonation/stop", impersonationController.Stop)
+			protectedRouter.Post("/updateStatus", presenceController.UpdateStatus)
+			protectedRouter.Get("/presence", presenceController.GetPresence)
 			protectedRouter.Get("/ws", wsController.HandleWebSocket)
+
+			protectedRouter.Get("/tokens", apiTokenController.ListTokens)
+
+			// Hesap güvenliğini etkileyen işlemler yalnızca tarayıcı oturumuyla yapılabilir; API tokeni
+			// sızdığında şifre, e-posta, 2FA veya yeni token ile hesabın ele geçirilmesi engellenir.
+			// BlockImpersonation ile işaretli işlemler destek ekibinin kullanıcı adına açtığı oturumlarda yapılamaz.
+			protectedRouter.Group(func(sessionRouter chi.Router) {
+				sessionRouter.Use(middlewares.RequireSession)
+				sessionRouter.Post("/logout", authController.Logout)
+
+				// Çoklu cihaz oturum yönetimi
+				sessionRouter.Get("/sessions", sessionController.ListSessions)
+				sessionRouter.Get("/logins", loginHistoryController.ListLogins)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/sessions/revokeOthers", sessionController.RevokeOtherSessions)
+				sessionRouter.With(middlewares.BlockImpersonation).Delete("/sessions/{sessionID}", sessionController.RevokeSession)
+
+				// Şifre ve e-posta değişikliği
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/password", accountController.ChangePassword)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/email", accountController.RequestEmailChange)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/email/confirm", accountController.ConfirmEmailChange)
+
+				// Hesap silme ve bekleme süresinde geri alma
+				sessionRouter.With(middlewares.BlockImpersonation).Delete("/account", accountController.DeleteAccount)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/account/restore", accountController.RestoreAccount)
+
+				// Kişisel verilerin dışa aktarılması
+				sessionRouter.Get("/export", dataExportController.ListExports)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/export", dataExportController.RequestExport)
+
+				// İki adımlı doğrulama (TOTP)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/2fa/enroll", twoFactorController.Enroll)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/2fa/confirm", twoFactorController.Confirm)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/2fa/disable", twoFactorController.Disable)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/2fa/recoveryCodes", twoFactorController.RegenerateRecoveryCodes)
+
+				// Passkey (WebAuthn) yönetimi
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/webauthn/register/begin", webauthnController.BeginRegistration)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/webauthn/register/finish", webauthnController.FinishRegistration)
+				sessionRouter.Get("/webauthn/credentials", webauthnController.ListCredentials)
+				sessionRouter.With(middlewares.BlockImpersonation).Delete("/webauthn/credentials/{credentialID}", webauthnController.DeleteCredential)
+
+				// Üçüncü taraf uygulamalara verilen OAuth izinleri
+				sessionRouter.Get("/oauth/consents", oauthController.ListConsents)
+				sessionRouter.With(middlewares.BlockImpersonation).Delete("/oauth/consents/{clientID}", oauthController.RevokeConsent)
+
+				// Kişisel erişim tokenleri
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/tokens", apiTokenController.CreateToken)
+				sessionRouter.With(middlewares.BlockImpersonation).Delete("/tokens/{tokenID}", apiTokenController.RevokeToken)
+
+				// Kayıt davetleri; kullanıcıların davet oluşturup oluşturamayacağı yapılandırmayla belirlenir
+				sessionRouter.Get("/invites", inviteController.ListInvites)
+				sessionRouter.With(middlewares.BlockImpersonation).Post("/invites", inviteController.CreateInvite)
+				sessionRouter.With(middlewares.BlockImpersonation).Delete("/invites/{inviteID}", inviteController.RevokeInvite)
+			})
 		})
 	})
 }
 
+// Harici OIDC sağlayıcılarıyla giriş endpointlerini ekler
+func registerFederationRoutes(r *chi.Mux, federationController *controllers.FederationController, rateLimiter *middlewares.RateLimiter) {
+	r.Route("/auth/federated", func(r chi.Router) {
+		r.Use(middlewares.Logger)
+		r.Use(rateLimiter.Middleware)
+
+		r.Get("/providers", federationController.Providers)
+		r.Get("/{provider}/login", federationController.Login)
+		r.Get("/{provider}/callback", federationController.Callback)
+	})
+}
+
+// Yalnızca yetkili kullanıcıların erişebileceği yönetim endpointlerini ekler
+func registerAdminRoutes(r *chi.Mux, adminController *controllers.AdminController, oauthController *controllers.OAuthController, apiTokenController *controllers.APITokenController, impersonationController *controllers.ImpersonationController, auditController *controllers.AuditController, inviteController *controllers.InviteController, authMiddleware *middlewares.AuthMiddleware) {
+	r.Route("/auth/admin", func(r chi.Router) {
+		r.Use(middlewares.Logger)
+		r.Use(authMiddleware.Authenticate)
+		// Kullanıcı adına açılmış oturumlar yönetim işlemleri yapamaz
+		r.Use(middlewares.BlockImpersonation)
+
+		r.With(middlewares.RequirePermission(models.PermPermissionsManage)).Get("/permissions", adminController.GetPermissions)
+		r.With(middlewares.RequirePermission(models.PermPermissionsManage)).Post("/permissions/reload", adminController.ReloadPermissions)
+		r.With(middlewares.RequirePermission(models.PermUserUnlock)).Post("/users/unlock", adminController.UnlockAccount)
+
+		r.With(middlewares.RequirePermission(models.PermUserReadAny)).Get("/users", adminController.SearchUsers)
+		r.With(middlewares.RequirePermission(models.PermUserReadAny)).Get("/users/{userID}", adminController.GetUser)
+		r.With(middlewares.RequirePermission(models.PermUserBan)).Post("/users/{userID}/restriction", adminController.RestrictUser)
+		r.With(middlewares.RequirePermission(models.PermUserBan)).Delete("/users/{userID}/restriction", adminController.LiftRestriction)
+		r.With(middlewares.RequireSession, middlewares.RequirePermission(models.PermUserManageRoles)).Put("/users/{userID}/roles", adminController.UpdateUserRoles)
+		r.With(middlewares.RequirePermission(models.PermUserRevokeSessions)).Post("/users/{userID}/logout", adminController.ForceLogout)
+
+		r.With(middlewares.RequireSession, middlewares.RequirePermission(models.PermUserImpersonate)).Post("/users/{userID}/impersonate", impersonationController.Start)
+		r.With(middlewares.RequirePermission(models.PermUserImpersonate)).Get("/impersonation/audit", impersonationController.ListAudit)
+
+		r.With(middlewares.RequirePermission(models.PermAuditRead)).Get("/audit", auditController.ListEvents)
+		r.With(middlewares.RequirePermission(models.PermAuditRead)).Get("/audit/export", auditController.ExportEvents)
+
+		r.With(middlewares.RequirePermission(models.PermOAuthClientsManage)).Post("/oauth/clients", oauthController.CreateClient)
+		r.With(middlewares.RequirePermission(models.PermOAuthClientsManage)).Get("/oauth/clients", oauthController.ListClients)
+		r.With(middlewares.RequirePermission(models.PermOAuthClientsManage)).Delete("/oauth/clients/{clientID}", oauthController.DeleteClient)
+
+		r.With(middlewares.RequireSession, middlewares.RequirePermission(models.PermAPITokensManage)).Post("/users/{userID}/tokens", apiTokenController.AdminCreateToken)
+		r.With(middlewares.RequirePermission(models.PermAPITokensManage)).Get("/users/{userID}/tokens", apiTokenController.AdminListTokens)
+		r.With(middlewares.RequirePermission(models.PermAPITokensManage)).Delete("/tokens/{tokenID}", apiTokenController.AdminRevokeToken)
+
+		r.With(middlewares.RequirePermission(models.PermInvitesManage)).Get("/invites", inviteController.AdminListInvites)
+		r.With(middlewares.RequirePermission(models.PermInvitesManage)).Delete("/invites/{inviteID}", inviteController.AdminRevokeInvite)
+	})
+}
+
 // Swagger dökümantasyonunu ekler
 func registerSwaggerRoutes(r *chi.Mux) {
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// deletionSweepBatch bir taramada işlenen en fazla hesap sayısıdır; kalanlar sonraki taramada işlenir
+const deletionSweepBatch = 100
+
+var (
+	ErrDeletionAlreadyScheduled = errors.New("hesabınız zaten silinmek üzere planlandı")
+	ErrDeletionNotScheduled     = errors.New("hesabınız için geri alınabilecek bir silme isteği yok")
+)
+
+// userDataCollections authDB'de kullanıcıya ait olup hesap silinince tamamen kaldırılan koleksiyonlardır.
+// Kullanıcının oluşturduğu davetler başka kişilere gönderilmiş olabileceğinden silinmez; yalnızca
+// oluşturan bilgisi kaldırılır (bkz. InviteRepository.DetachCreator).
+var userDataCollections = []string{
+	"api_tokens",
+	"federated_identities",
+	"webauthn_credentials",
+	"oauth_consents",
+	"oauth_refresh_tokens",
+	"passwordresets",
+	"login_history",
+	"known_devices",
+}
+
+// AccountDeletionService kullanıcının hesabını silme isteğini bekleme süresi boyunca tutar; süre dolunca
+// auth verisini anonimleştirir, diğer servislere "user_deleted" mesajı gönderir ve onaylarını toplar
+type AccountDeletionService struct {
+	userRepo    *repository.UserRepository
+	exportRepo  *repository.DataExportRepository
+	inviteRepo  *repository.InviteRepository
+	sessionRepo *redisrepo.RedisRepository
+	rabbitMQ    *messaging.RabbitMQ
+	config      config.AccountDeletionConfig
+}
+
+func NewAccountDeletionService(userRepo *repository.UserRepository, exportRepo *repository.DataExportRepository, inviteRepo *repository.InviteRepository, sessionRepo *redisrepo.RedisRepository, rabbitMQ *messaging.RabbitMQ, cfg config.AccountDeletionConfig) *AccountDeletionService {
+	return &AccountDeletionService{
+		userRepo:    userRepo,
+		exportRepo:  exportRepo,
+		inviteRepo:  inviteRepo,
+		sessionRepo: sessionRepo,
+		rabbitMQ:    rabbitMQ,
+		config:      cfg,
+	}
+}
+
+// Schedule şifre doğrulandıktan sonra hesabı bekleme süresi sonunda silinmek üzere planlar. Kullanıcının
+// tüm oturumları kapatılır; bekleme süresinde tekrar giriş yapıp hesabını geri alabilir.
+func (s *AccountDeletionService) Schedule(userID, password string) (*models.User, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, ErrInvalidPassword
+	}
+	if user.Deletion != nil {
+		return nil, ErrDeletionAlreadyScheduled
+	}
+
+	now := time.Now()
+	deletion := &models.AccountDeletion{
+		Status:       models.DeletionScheduled,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(s.config.GracePeriod),
+	}
+	scheduled, err := s.userRepo.ScheduleUserDeletion(userID, deletion)
+	if err != nil {
+		return nil, err
+	}
+	if !scheduled {
+		return nil, ErrDeletionAlreadyScheduled
+	}
+	user.Deletion = deletion
+
+	if _, err := s.sessionRepo.RevokeUserSessions(userID, ""); err != nil {
+		log.Printf("Silinecek hesabın oturumları sonlandırılamadı: %v", err)
+	}
+	if err := s.sessionRepo.DeleteUserAPITokens(userID); err != nil {
+		log.Printf("Silinecek hesabın API tokenleri önbellekten silinemedi: %v", err)
+	}
+	return user, nil
+}
+
+// Restore bekleme süresindeki silme isteğini iptal eder
+func (s *AccountDeletionService) Restore(userID string) error {
+	restored, err := s.userRepo.CancelUserDeletion(userID)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return ErrDeletionNotScheduled
+	}
+	return nil
+}
+
+// Acknowledge servisin kullanıcının verisini sildiği onayını kaydeder; beklenen tüm servisler
+// onayladığında silme işlemi tamamlanır
+func (s *AccountDeletionService) Acknowledge(userID, service string) error {
+	now := time.Now()
+	user, err := s.userRepo.AcknowledgeUserDeletion(userID, service, now)
+	if err != nil {
+		return err
+	}
+	log.Printf("Kullanıcı verisinin silindiği onaylandı (%s): %s", service, userID)
+
+	for _, required := range s.config.RequiredServices {
+		if _, ok := user.Deletion.Acknowledgements[required]; !ok {
+			return nil
+		}
+	}
+	return s.complete(userID, now)
+}
+
+func (s *AccountDeletionService) complete(userID string, now time.Time) error {
+	completed, err := s.userRepo.CompleteUserDeletion(userID, now)
+	if err != nil {
+		return err
+	}
+	if completed {
+		log.Printf("Kullanıcının verileri tüm servislerden silindi: %s", userID)
+	}
+	return nil
+}
+
+// Sweep bekleme süresi dolan hesapları siler ve onayı gecikmiş silme işlemleri için mesajı yeniden gönderir
+func (s *AccountDeletionService) Sweep(now time.Time) error {
+	due, _, err := s.userRepo.SearchUsers(bson.M{
+		"deletion.status":       models.DeletionScheduled,
+		"deletion.scheduledFor": bson.M{"$lte": now},
+	}, 0, deletionSweepBatch)
+	if err != nil {
+		return err
+	}
+	for i := range due {
+		if err := s.erase(&due[i], now); err != nil {
+			log.Printf("Hesap silinemedi (%s): %v", due[i].ID.Hex(), err)
+		}
+	}
+
+	stale, _, err := s.userRepo.SearchUsers(bson.M{
+		"deletion.status":      models.DeletionErasing,
+		"deletion.announcedAt": bson.M{"$lte": now.Add(-s.config.AckTimeout)},
+	}, 0, deletionSweepBatch)
+	if err != nil {
+		return err
+	}
+	for _, user := range stale {
+		userID := user.ID.Hex()
+		if err := s.userRepo.MarkUserDeletionAnnounced(userID, now); err != nil {
+			log.Printf("Silme duyurusu kaydedilemedi (%s): %v", userID, err)
+			continue
+		}
+		s.announce(userID)
+	}
+	return nil
+}
+
+// erase hesabı anonimleştirir, auth verisini siler ve diğer servislere duyurur. Hesap başka bir servis
+// örneği tarafından silinmişse veya son anda geri alınmışsa hiçbir şey yapılmaz.
+func (s *AccountDeletionService) erase(user *models.User, now time.Time) error {
+	userID := user.ID.Hex()
+	erased, err := s.userRepo.EraseUser(userID, now)
+	if err != nil || !erased {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, name := range userDataCollections {
+		collection, _ := database.GetCollection("authDB", name)
+		if _, err := collection.DeleteMany(ctx, bson.M{"userId": user.ID}); err != nil {
+			log.Printf("%s koleksiyonundaki kullanıcı verisi silinemedi: %v", name, err)
+		}
+	}
+	if err := s.exportRepo.DeleteUserExports(user.ID); err != nil {
+		log.Printf("Silinen hesabın dışa aktarma arşivleri silinemedi: %v", err)
+	}
+	if err := s.inviteRepo.DetachCreator(user.ID); err != nil {
+		log.Printf("Silinen hesabın davetlerinden oluşturan bilgisi kaldırılamadı: %v", err)
+	}
+	if _, err := s.sessionRepo.RevokeUserSessions(userID, ""); err != nil {
+		log.Printf("Silinen hesabın oturumları sonlandırılamadı: %v", err)
+	}
+	if err := s.sessionRepo.DeleteUserAPITokens(userID); err != nil {
+		log.Printf("Silinen hesabın API tokenleri önbellekten silinemedi: %v", err)
+	}
+
+	// Bildirim, anonimleştirmeden önce okunan adrese gönderilir
+	emailMessage := messaging.Message{
+		Type:      "account_deleted",
+		ToService: messaging.EmailService,
+		Data: map[string]interface{}{
+			"email":         user.Email,
+			"userName":      user.Username,
+			"template_name": "account_deleted.html",
+		},
+	}
+	if err := s.rabbitMQ.PublishMessage(context.Background(), emailMessage); err != nil {
+		log.Printf("Hesap silme e-postası gönderilemedi: %v", err)
+	}
+
+	if len(s.config.RequiredServices) == 0 {
+		return s.complete(userID, now)
+	}
+	s.announce(userID)
+	return nil
+}
+
+// announce user-service ve chat-service'in kullanıcının verisini silmesi için tüm servislere "user_deleted"
+// mesajı yayınlar; mesaj gönderilemezse onay zaman aşımından sonra yeniden denenir
+func (s *AccountDeletionService) announce(userID string) {
+	message := messaging.Message{
+		Type: "user_deleted",
+		Data: map[string]interface{}{
+			"user_id": userID,
+		},
+	}
+	if err := s.rabbitMQ.PublishMessage(context.Background(), message); err != nil {
+		log.Printf("user_deleted mesajı gönderilemedi: %v", err)
+	}
+}
+
+// StartSweeper bekleme süresi dolan hesapları yapılandırılan aralıklarla siler; stop kapatılınca durur.
+// Tarama her servis örneğinde çalışabilir, bir hesap yalnızca bir kez silinir.
+func (s *AccountDeletionService) StartSweeper(stop <-chan struct{}) {
+	if s.config.SweepInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := s.Sweep(now); err != nil {
+				log.Printf("Silinecek hesaplar taranamadı: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailChangeTTL         = 15 * time.Minute
+	maxEmailChangeAttempts = 5
+)
+
+var (
+	ErrSamePassword              = errors.New("yeni şifre mevcut şifreyle aynı olamaz")
+	ErrSameEmail                 = errors.New("yeni e-posta adresi mevcut adresle aynı")
+	ErrEmailInUse                = errors.New("bu e-posta adresi zaten kullanımda")
+	ErrInvalidEmailChangeCode    = errors.New("doğrulama kodu hatalı")
+	ErrEmailChangeAttemptsExceed = errors.New("çok fazla hatalı deneme yapıldı, lütfen yeni kod isteyin")
+)
+
+// AccountService oturum açmış kullanıcının kendi hesap bilgilerini değiştirmesini sağlar
+type AccountService struct {
+	collection      *mongo.Collection
+	emailChangeRepo *repository.EmailChangeRepository
+	passwordPolicy  *PasswordPolicy
+}
+
+func NewAccountService(passwordPolicy *PasswordPolicy) *AccountService {
+	collection, _ := database.GetCollection("authDB", "users")
+	return &AccountService{
+		collection:      collection,
+		emailChangeRepo: repository.NewEmailChangeRepository(database.RedisClient),
+		passwordPolicy:  passwordPolicy,
+	}
+}
+
+func (s *AccountService) findUser(ctx context.Context, userID string) (*models.User, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("geçersiz kullanıcı ID'si")
+	}
+
+	var user models.User
+	if err := s.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ChangePassword mevcut şifre doğrulandıktan sonra şifreyi günceller
+func (s *AccountService) ChangePassword(userID, currentPassword, newPassword string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)) != nil {
+		return ErrInvalidPassword
+	}
+	if currentPassword == newPassword {
+		return ErrSamePassword
+	}
+	if err := s.passwordPolicy.Validate(newPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("şifre işlenirken hata oluştu: %v", err)
+	}
+
+	_, err = s.collection.UpdateOne(ctx,
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"password": string(hashedPassword), "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return fmt.Errorf("şifre güncellenemedi: %v", err)
+	}
+	return nil
+}
+
+// RequestEmailChange şifre doğrulandıktan sonra yeni adrese gönderilecek doğrulama kodunu üretir
+func (s *AccountService) RequestEmailChange(userID, newEmail, password string) (string, *models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return "", nil, ErrInvalidPassword
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return "", nil, ErrSameEmail
+	}
+	count, err := s.collection.CountDocuments(ctx, bson.M{"email": newEmail})
+	if err != nil {
+		return "", nil, fmt.Errorf("veritabanı hatası: %v", err)
+	}
+	if count > 0 {
+		return "", nil, ErrEmailInUse
+	}
+
+	code, err := GenerateActivationCode()
+	if err != nil {
+		return "", nil, fmt.Errorf("doğrulama kodu oluşturulamadı: %v", err)
+	}
+
+	change := &models.PendingEmailChange{
+		UserID:    userID,
+		NewEmail:  newEmail,
+		CodeHash:  hashCode(code),
+		CreatedAt: time.Now(),
+	}
+	if err := s.emailChangeRepo.Save(change, emailChangeTTL); err != nil {
+		return "", nil, fmt.Errorf("e-posta değişikliği isteği saklanamadı: %v", err)
+	}
+	return code, user, nil
+}
+
+// ConfirmEmailChange kodu doğrular ve e-posta adresini günceller; güncellenen kullanıcı ve eski adres döner
+func (s *AccountService) ConfirmEmailChange(userID, code string) (*models.User, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	change, err := s.emailChangeRepo.Get(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Deneme önce sayılır; eşzamanlı tahminler sayacı okuyup sınırı birlikte aşamaz
+	attempts, err := s.emailChangeRepo.IncrementAttempts(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if attempts > maxEmailChangeAttempts {
+		s.emailChangeRepo.Delete(userID)
+		return nil, "", ErrEmailChangeAttemptsExceed
+	}
+
+	if subtle.ConstantTimeCompare([]byte(change.CodeHash), []byte(hashCode(code))) != 1 {
+		if attempts >= maxEmailChangeAttempts {
+			s.emailChangeRepo.Delete(userID)
+			return nil, "", ErrEmailChangeAttemptsExceed
+		}
+		return nil, "", ErrInvalidEmailChangeCode
+	}
+
+	// Aynı kodla eşzamanlı iki onayı engelle
+	deleted, err := s.emailChangeRepo.Delete(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !deleted {
+		return nil, "", repository.ErrPendingEmailChangeNotFound
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	oldEmail := user.Email
+
+	// E-posta üzerindeki unique index, kod gönderildikten sonra adresi alan başka bir hesaba karşı korur
+	now := time.Now()
+	_, err = s.collection.UpdateOne(ctx,
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"email": change.NewEmail, "updatedAt": now}},
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, "", ErrEmailInUse
+		}
+		return nil, "", fmt.Errorf("e-posta güncellenemedi: %v", err)
+	}
+
+	user.Email = change.NewEmail
+	user.UpdatedAt = now
+	return user, oldEmail, nil
+}
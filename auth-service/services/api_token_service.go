@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const apiTokenDisplayPrefixLength = 12
+
+var (
+	ErrAPITokenNotFound   = errors.New("token bulunamadı")
+	ErrAPITokenLimit      = errors.New("token sayısı sınırına ulaşıldı, önce kullanılmayan tokenleri silin")
+	ErrAPITokenTTLTooLong = errors.New("token süresi izin verilen en uzun süreyi aşıyor")
+	ErrInvalidTokenScope  = errors.New("geçersiz token kapsamı")
+)
+
+// Kapsamlar yetki adlarıyla aynı biçimdedir: "*", "kaynak:*" veya "kaynak:eylem"
+var tokenScopePattern = regexp.MustCompile(`^(\*|[a-z][a-z-]*:(\*|[a-z][a-z-]*))$`)
+
+// APITokenService kişisel erişim tokenlerini authDB'de saklar ve doğrulama için Redis önbelleğini güncel tutar
+type APITokenService struct {
+	collection  *mongo.Collection
+	users       *mongo.Collection
+	sessionRepo *redisrepo.RedisRepository
+	config      config.APITokenConfig
+}
+
+func NewAPITokenService(sessionRepo *redisrepo.RedisRepository, cfg config.APITokenConfig) *APITokenService {
+	collection, _ := database.GetCollection("authDB", "api_tokens")
+	users, _ := database.GetCollection("authDB", "users")
+	return &APITokenService{
+		collection:  collection,
+		users:       users,
+		sessionRepo: sessionRepo,
+		config:      cfg,
+	}
+}
+
+func activeTokenFilter(filter bson.M) bson.M {
+	filter["revokedAt"] = bson.M{"$exists": false}
+	filter["expiresAt"] = bson.M{"$gt": time.Now()}
+	return filter
+}
+
+// tokenUserData middleware'in context'e koyacağı, oturumla aynı biçimdeki kullanıcı verisini üretir
+func tokenUserData(user *models.User, token *models.APIToken) (map[string]string, error) {
+	rolesJSON, err := json.Marshal(user.Roles)
+	if err != nil {
+		return nil, err
+	}
+	scopesJSON, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"id":          user.ID.Hex(),
+		"email":       user.Email,
+		"roles":       string(rolesJSON),
+		"username":    user.Username,
+		"auth_method": redisrepo.AuthMethodAPIToken,
+		"token_id":    token.ID.Hex(),
+		"scopes":      string(scopesJSON),
+	}, nil
+}
+
+func (s *APITokenService) findUser(ctx context.Context, userID primitive.ObjectID) (*models.User, error) {
+	var user models.User
+	if err := s.users.FindOne(ctx, bson.M{"_id": userID, "isDeleted": bson.M{"$ne": true}}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// cacheToken tokeni kalan süresiyle Redis'e yazar; kısıtlanmış veya silinmek üzere olan hesapların
+// tokenleri kısıtlama kalkana ya da silme isteği geri alınana kadar yazılmaz
+func (s *APITokenService) cacheToken(user *models.User, token *models.APIToken) error {
+	ttl := time.Until(token.ExpiresAt)
+	if ttl <= 0 || user.Restriction.Active(time.Now()) || user.Deletion != nil {
+		return nil
+	}
+	userData, err := tokenUserData(user, token)
+	if err != nil {
+		return err
+	}
+	return s.sessionRepo.SetAPIToken(token.TokenHash, userData, ttl)
+}
+
+// CreateToken userID için yeni bir token oluşturur; düz metin token yalnızca bu çağrıda döner.
+// createdBy, yöneticinin bir servis hesabı için token oluşturduğu durumda kullanıcıdan farklıdır.
+func (s *APITokenService) CreateToken(userID, createdBy string, input *dto.CreateAPITokenDto) (*models.APIToken, string, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, "", ErrUserNotFound
+	}
+	creatorObjID, err := primitive.ObjectIDFromHex(createdBy)
+	if err != nil {
+		return nil, "", ErrUserNotFound
+	}
+
+	scopes := make([]models.Permission, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !tokenScopePattern.MatchString(scope) {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidTokenScope, scope)
+		}
+		scopes = append(scopes, models.Permission(scope))
+	}
+
+	ttl := s.config.DefaultTTL
+	if input.ExpiresInDays > 0 {
+		ttl = time.Duration(input.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl > s.config.MaxTTL {
+		return nil, "", ErrAPITokenTTLTooLong
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := s.findUser(ctx, userObjID)
+	if err != nil {
+		return nil, "", err
+	}
+	count, err := s.collection.CountDocuments(ctx, activeTokenFilter(bson.M{"userId": userObjID}))
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= int64(s.config.MaxPerUser) {
+		return nil, "", ErrAPITokenLimit
+	}
+
+	secret, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	plainToken := redisrepo.APITokenPrefix + secret
+
+	now := time.Now()
+	token := &models.APIToken{
+		UserID:    userObjID,
+		Name:      input.Name,
+		TokenHash: redisrepo.APITokenHash(plainToken),
+		Prefix:    plainToken[:apiTokenDisplayPrefixLength],
+		Scopes:    scopes,
+		ExpiresAt: now.Add(ttl),
+		CreatedBy: creatorObjID,
+		CreatedAt: now,
+	}
+	result, err := s.collection.InsertOne(ctx, token)
+	if err != nil {
+		return nil, "", fmt.Errorf("token kaydedilemedi: %v", err)
+	}
+	token.ID = result.InsertedID.(primitive.ObjectID)
+
+	// Önbelleğe yazılamayan token hiçbir serviste çalışmaz; kayıt geri alınır
+	if err := s.cacheToken(user, token); err != nil {
+		s.collection.DeleteOne(ctx, bson.M{"_id": token.ID})
+		return nil, "", fmt.Errorf("token kaydedilemedi: %v", err)
+	}
+	return token, plainToken, nil
+}
+
+// withLastUsed Redis'teki son kullanım zamanını kayıtla birleştirir
+func (s *APITokenService) withLastUsed(token *models.APIToken) {
+	lastUsed, err := s.sessionRepo.APITokenLastUsed(token.ID.Hex())
+	if err != nil || lastUsed.IsZero() {
+		return
+	}
+	if token.LastUsedAt == nil || lastUsed.After(*token.LastUsedAt) {
+		token.LastUsedAt = &lastUsed
+	}
+}
+
+// ListTokens kullanıcının iptal edilmemiş ve süresi dolmamış tokenlerini döner
+func (s *APITokenService) ListTokens(userID string) ([]models.APIToken, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, activeTokenFilter(bson.M{"userId": userObjID}), options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	tokens := []models.APIToken{}
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	for i := range tokens {
+		s.withLastUsed(&tokens[i])
+	}
+	return tokens, nil
+}
+
+// RevokeToken tokeni iptal eder ve önbellekten siler.
+// ownerID boş değilse yalnızca o kullanıcıya ait token iptal edilebilir; yöneticiler boş geçer.
+func (s *APITokenService) RevokeToken(tokenID, ownerID string) error {
+	objID, err := primitive.ObjectIDFromHex(tokenID)
+	if err != nil {
+		return ErrAPITokenNotFound
+	}
+	filter := bson.M{"_id": objID, "revokedAt": bson.M{"$exists": false}}
+	if ownerID != "" {
+		ownerObjID, err := primitive.ObjectIDFromHex(ownerID)
+		if err != nil {
+			return ErrAPITokenNotFound
+		}
+		filter["userId"] = ownerObjID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var token models.APIToken
+	if err := s.collection.FindOne(ctx, filter).Decode(&token); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrAPITokenNotFound
+		}
+		return err
+	}
+
+	// Önce önbellekten silinir; böylece token veritabanı güncellemesi beklenmeden reddedilir
+	s.withLastUsed(&token)
+	if err := s.sessionRepo.DeleteAPIToken(token.UserID.Hex(), token.TokenHash, tokenID); err != nil {
+		return err
+	}
+
+	set := bson.M{"revokedAt": time.Now()}
+	if token.LastUsedAt != nil {
+		set["lastUsedAt"] = *token.LastUsedAt
+	}
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": set})
+	return err
+}
+
+// SyncCache etkin tokenleri Redis'e yeniden yazar (Redis verisi kaybolursa veya kullanıcının rolleri
+// değişirse önbellek güncellenir), son kullanım zamanlarını veritabanına taşır ve iptal edilenleri önbellekten siler
+func (s *APITokenService) SyncCache() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{"expiresAt": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	users := map[primitive.ObjectID]*models.User{}
+	for cursor.Next(ctx) {
+		var token models.APIToken
+		if err := cursor.Decode(&token); err != nil {
+			return err
+		}
+
+		if token.RevokedAt != nil {
+			if err := s.sessionRepo.DeleteAPIToken(token.UserID.Hex(), token.TokenHash, token.ID.Hex()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		previous := token.LastUsedAt
+		s.withLastUsed(&token)
+		if token.LastUsedAt != nil && (previous == nil || token.LastUsedAt.After(*previous)) {
+			s.collection.UpdateOne(ctx, bson.M{"_id": token.ID}, bson.M{"$set": bson.M{"lastUsedAt": *token.LastUsedAt}})
+		}
+
+		user, ok := users[token.UserID]
+		if !ok {
+			if user, err = s.findUser(ctx, token.UserID); err != nil && !errors.Is(err, ErrUserNotFound) {
+				return err
+			}
+			users[token.UserID] = user
+		}
+		// Silinmiş, silinmek üzere olan, askıya alınmış veya yasaklanmış kullanıcının tokenleri çalışmaz
+		if user == nil || user.Restriction.Active(time.Now()) || user.Deletion != nil {
+			if err := s.sessionRepo.DeleteAPIToken(token.UserID.Hex(), token.TokenHash, token.ID.Hex()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.cacheToken(user, &token); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// StartSync önbelleği hemen ve ardından yapılandırılan aralıklarla eşitler; stop kapatılınca durur
+func (s *APITokenService) StartSync(stop <-chan struct{}) {
+	if err := s.SyncCache(); err != nil {
+		log.Printf("API token önbelleği eşitlenemedi: %v", err)
+	}
+	if s.config.SyncInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.SyncCache(); err != nil {
+				log.Printf("API token önbelleği eşitlenemedi: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
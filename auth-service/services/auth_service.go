@@ -2,18 +2,25 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
-	"math/rand"
+	"math/big"
+	"net/url"
 	"time"
 
 	// "github.com/MKMuhammetKaradag/go-microservice/auth-service/database"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
 	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
-	"github.com/google/uuid"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
 
 	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -22,18 +29,41 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-var r = rand.New(rand.NewSource(time.Now().UnixNano()))
+const (
+	ACTIVATION_CODE_LENGTH   = 6
+	activationTTL            = 1 * time.Hour
+	maxActivationAttempts    = 5
+	maxActivationResends     = 5
+	activationResendCooldown = 1 * time.Minute
+	activationTokenBytes     = 32
+)
+
+var (
+	ErrActivationAttemptsExceeded = errors.New("çok fazla hatalı deneme yapıldı, lütfen yeniden kayıt olun")
+	ErrActivationResendTooSoon    = errors.New("yeni kod istemeden önce lütfen biraz bekleyin")
+	ErrActivationResendLimit      = errors.New("aktivasyon kodu gönderme sınırına ulaşıldı")
+	ErrUserNotFound               = errors.New("Kullanıcı bulunamadı")
+	ErrInvalidResetToken          = errors.New("geçersiz ya da süresi dolmuş token")
+)
 
 type AuthService struct {
 	collection              *mongo.Collection
 	passwordResetCollection *mongo.Collection
+	registrationRepo        *repository.RegistrationRepository
+	passwordResetConfig     config.PasswordResetConfig
+	passwordPolicy          *PasswordPolicy
+	inviteService           *InviteService
 }
 
-func NewAuthService() *AuthService {
+func NewAuthService(cfg config.Config, passwordPolicy *PasswordPolicy, inviteService *InviteService) *AuthService {
 	passwordResetCollection, _ := database.GetCollection("authDB", "passwordresets")
 	return &AuthService{
 		collection:              database.MongoClient.Database("authDB").Collection("users"),
 		passwordResetCollection: passwordResetCollection,
+		registrationRepo:        repository.NewRegistrationRepository(database.RedisClient),
+		passwordResetConfig:     cfg.PasswordReset,
+		passwordPolicy:          passwordPolicy,
+		inviteService:           inviteService,
 	}
 }
 
@@ -97,7 +127,7 @@ func (s *AuthService) FindUser(email, userName string) (*models.User, error) {
 	err := s.collection.FindOne(context.Background(), filter).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("Kullanıcı bulunamadı") // Kullanıcı bulunamadı
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("veritabanı hatası: %v", err)
 	}
@@ -161,83 +191,211 @@ func (s *AuthService) Register(user *models.User) (*models.User, error) {
 	return user, nil
 }
 
-// const ACTIVATION_CODE_LENGTH = 4
-func GenerateActivationCode() string {
+// GenerateActivationCode kriptografik olarak güvenli, sabit uzunlukta sayısal bir kod üretir
+func GenerateActivationCode() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(ACTIVATION_CODE_LENGTH), nil)
+	num, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
 
-	// 0 ile 9999 arasında bir sayı üret
-	num := r.Intn(10000)
+	// Başındaki sıfırları koruyarak string'e çevir (örn: 003412)
+	return fmt.Sprintf("%0*d", ACTIVATION_CODE_LENGTH, num), nil
+}
 
-	// 4 haneye tamamlayarak string'e çevir (örn: 0034, 0923, 0001)
-	return fmt.Sprintf("%04d", num)
+// generateOpaqueToken istemciye verilecek, içinde veri taşımayan rastgele bir token üretir
+func generateOpaqueToken() (string, error) {
+	b := make([]byte, activationTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
 }
 
-func (s *AuthService) SignUp(user *models.User) (string, string, error) {
-	secretKey := "your-secret-key"
+// SignUp kayıt modunu ve davet kodunu doğrular, kullanıcıyı aktivasyon bekleyen kayıt olarak saklar ve
+// aktivasyon kodu ile token döner. Davet bu aşamada yalnızca doğrulanır; kullanım hakkı aktivasyonda harcanır.
+func (s *AuthService) SignUp(user *models.User, inviteCode string) (string, string, error) {
+	invite, err := s.inviteService.CheckSignUp(inviteCode, user.Email)
+	if err != nil {
+		return "", "", err
+	}
 
-	jwtService := NewJwtHelperService(secretKey)
-	activationCode := GenerateActivationCode()
-	payload := map[string]interface{}{
-		"activationCode": activationCode,
-		"user":           user,
+	exists, err := s.CheckExistingUser(user.Email, user.Username)
+	if err != nil {
+		return "", "", err
 	}
-	token, err := jwtService.SignToken(payload, 1*time.Hour)
+	if exists {
+		return "", "", errors.New("bu email veya kullanıcı adı zaten kullanımda")
+	}
+
+	if err := s.passwordPolicy.Validate(user.Password, user.Username, user.Email); err != nil {
+		return "", "", err
+	}
+
+	// Şifre sunucuda bile düz metin olarak tutulmaz
+	hashedPassword, err := s.hashPassword(user.Password)
 	if err != nil {
-		log.Fatalf("Error signing token: %v", err)
+		return "", "", fmt.Errorf("şifre işlenirken hata oluştu: %v", err)
 	}
 
-	fmt.Println("Activation Code:", activationCode)
-	return activationCode, token, nil
-}
-func ptrToInt(i int) *int {
-	return &i
+	activationCode, err := GenerateActivationCode()
+	if err != nil {
+		return "", "", fmt.Errorf("aktivasyon kodu oluşturulamadı: %v", err)
+	}
+	activationToken, err := generateOpaqueToken()
+	if err != nil {
+		return "", "", fmt.Errorf("aktivasyon tokeni oluşturulamadı: %v", err)
+	}
+
+	now := time.Now()
+	registration := &models.PendingRegistration{
+		Username:     user.Username,
+		Email:        user.Email,
+		PasswordHash: hashedPassword,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Age:          user.Age,
+		// Roller istemciden alınmaz; yeni kullanıcılar varsayılan rolle veya davette belirlenen rollerle başlar
+		Roles:      []models.UserRole{models.USER},
+		CodeHash:   hashCode(activationCode),
+		LastSentAt: now,
+		CreatedAt:  now,
+	}
+	if invite != nil {
+		registration.InviteID = invite.ID.Hex()
+	}
+
+	if err := s.registrationRepo.Save(activationToken, registration, activationTTL); err != nil {
+		return "", "", fmt.Errorf("kayıt isteği saklanamadı: %v", err)
+	}
+
+	return activationCode, activationToken, nil
 }
-func (s *AuthService) ActivationUser(activationCode, activationToken string) (*models.User, error) {
-	secretKey := "your-secret-key"
 
-	jwtService := NewJwtHelperService(secretKey)
-	claims, err := jwtService.VerifyToken(activationToken)
+// ResendActivationCode bekleyen kayıt için yeni bir aktivasyon kodu üretir
+func (s *AuthService) ResendActivationCode(activationToken string) (string, *models.PendingRegistration, error) {
+	registration, err := s.registrationRepo.Get(activationToken)
 	if err != nil {
-		return nil, fmt.Errorf("error verifying token: %w", err)
+		return "", nil, err
 	}
 
-	// Aktivasyon kodunun doğruluğunu kontrol et
-	if claims["activationCode"] != activationCode {
-		return nil, errors.New("activation code mismatch")
+	if registration.ResendCount >= maxActivationResends {
+		return "", nil, ErrActivationResendLimit
+	}
+	if time.Since(registration.LastSentAt) < activationResendCooldown {
+		return "", nil, ErrActivationResendTooSoon
 	}
 
-	// Kullanıcı verilerini al
-	userData, ok := claims["user"].(map[string]interface{})
-	if !ok {
-		return nil, errors.New("invalid user data in token")
+	activationCode, err := GenerateActivationCode()
+	if err != nil {
+		return "", nil, fmt.Errorf("aktivasyon kodu oluşturulamadı: %v", err)
 	}
-	user := &models.User{
-		Username:  userData["username"].(string),
-		Email:     userData["email"].(string),
-		Password:  userData["password"].(string),
-		FirstName: userData["firstName"].(string),
-		LastName:  userData["lastName"].(string),
-		Age:       ptrToInt(int(userData["age"].(float64))),
+
+	registration.CodeHash = hashCode(activationCode)
+	registration.ResendCount++
+	registration.LastSentAt = time.Now()
+
+	// Deneme sayacı yeni kodla sıfırlanmaz
+	if err := s.registrationRepo.Update(activationToken, registration); err != nil {
+		return "", nil, err
 	}
-	// Roller kontrolü ve eklenmesi
-	roles, ok := userData["roles"].([]interface{})
-	if !ok {
-		roles = []interface{}{models.USER}
+
+	return activationCode, registration, nil
+}
+
+func (s *AuthService) ActivationUser(activationCode, activationToken string) (*models.User, error) {
+	registration, err := s.registrationRepo.Get(activationToken)
+	if err != nil {
+		return nil, err
 	}
 
-	var userRoles []models.UserRole
-	for _, role := range roles {
-		roleStr, ok := role.(string)
-		if ok {
+	// Deneme önce sayılır; eşzamanlı tahminler sayacı okuyup sınırı birlikte aşamaz
+	attempts, err := s.registrationRepo.IncrementAttempts(activationToken)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > maxActivationAttempts {
+		s.registrationRepo.Delete(activationToken)
+		return nil, ErrActivationAttemptsExceeded
+	}
 
-			userRoles = append(userRoles, models.UserRole(roleStr))
+	// Aktivasyon kodunun doğruluğunu sabit zamanlı karşılaştırma ile kontrol et
+	if subtle.ConstantTimeCompare([]byte(registration.CodeHash), []byte(hashCode(activationCode))) != 1 {
+		if attempts >= maxActivationAttempts {
+			s.registrationRepo.Delete(activationToken)
+			return nil, ErrActivationAttemptsExceeded
 		}
+		return nil, fmt.Errorf("aktivasyon kodu hatalı, kalan deneme hakkı: %d", maxActivationAttempts-attempts)
 	}
 
-	user.Roles = userRoles
+	// Aynı token ile eşzamanlı iki aktivasyonu engelle
+	deleted, err := s.registrationRepo.Delete(activationToken)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, repository.ErrPendingRegistrationNotFound
+	}
+
+	user := models.NewUser()
+	user.Username = registration.Username
+	user.Email = registration.Email
+	user.Password = registration.PasswordHash
+	user.FirstName = registration.FirstName
+	user.LastName = registration.LastName
+	user.Age = registration.Age
+	user.Roles = registration.Roles
+
+	// Davet kayıttan sonra iptal edilmiş, süresi dolmuş veya başka kayıtlarla tükenmiş olabilir
+	var invite *models.Invite
+	if registration.InviteID != "" {
+		if invite, err = s.inviteService.Consume(registration.InviteID, registration.Email); err != nil {
+			return nil, err
+		}
+		user.Roles = invite.Roles
+	}
 
 	// Kullanıcıyı kaydet (aktif hale getirme)
-	return s.Register(user)
+	activatedUser, err := s.createActivatedUser(&user)
+	if err != nil && invite != nil {
+		s.inviteService.Release(invite)
+	}
+	return activatedUser, err
+}
+
+// createActivatedUser şifresi zaten hashlenmiş kullanıcıyı veritabanına kaydeder
+func (s *AuthService) createActivatedUser(user *models.User) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+
+	if err := s.saveUserToDB(ctx, user); err != nil {
+		return nil, fmt.Errorf("kullanıcı kaydedilemedi: %v", err)
+	}
+	return user, nil
+}
+
+// UserCreatedMessage yeni kullanıcıyı diğer servislere bildiren mesajı oluşturur
+func UserCreatedMessage(user *models.User) messaging.Message {
+	return messaging.Message{
+		Type:      "user_created",
+		ToService: messaging.UserService, // Eğer belirli bir servis varsa burada belirtin
+		Data: map[string]interface{}{
+			"user_id":   user.ID,
+			"email":     user.Email,
+			"firstName": user.FirstName,
+			"age":       user.Age,
+			"createdAt": user.CreatedAt,
+			"username":  user.Username,
+		},
+	}
 }
 
 func (s *AuthService) SignIn(input *models.User) (*dto.UserResponse, error) {
@@ -257,35 +415,29 @@ func (s *AuthService) SignIn(input *models.User) (*dto.UserResponse, error) {
 		return nil, errors.New("E-posta veya şifre hatalı")
 	}
 
-	response := &dto.UserResponse{
-		ID:        hex.EncodeToString(user.ID[:]),
-		Username:  user.Username,
-		Email:     user.Email,
-		Roles:     user.Roles,
-		FirstName: user.FirstName,
-		Age:       *user.Age,
-		CreatedAt: user.CreatedAt,
-	}
+	response := dto.NewUserResponse(&user)
 	return response, nil
 
 }
 
+// GenerateForgotPasswordLink kullanıcı için tek kullanımlık bir sıfırlama tokeni üretir; veritabanında yalnızca özeti saklanır
 func (s *AuthService) GenerateForgotPasswordLink(userId primitive.ObjectID) (string, error) {
-	// UUID oluştur
-	resetToken := uuid.NewString()
-
-	// Token süresini belirle (şu anki zamana +1 saat)
-	expiresAt := time.Now().Add(1 * time.Hour)
+	resetToken, err := generateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("şifre sıfırlama bağlantısı oluşturulurken hata oluştu")
+	}
 
-	// MongoDB'ye kaydedilecek veri
+	now := time.Now()
 	passwordReset := models.PasswordReset{
 		UserID:    userId,
-		Token:     resetToken,
-		ExpiresAt: expiresAt,
+		TokenHash: hashCode(resetToken),
+		ExpiresAt: now.Add(s.passwordResetConfig.TokenTTL),
+		Used:      false,
+		CreatedAt: now,
 	}
 
 	// MongoDB'ye kaydet
-	_, err := s.passwordResetCollection.InsertOne(context.Background(), passwordReset)
+	_, err = s.passwordResetCollection.InsertOne(context.Background(), passwordReset)
 	if err != nil {
 		// Hata günlüğü ile daha açıklayıcı bir hata mesajı
 		log.Printf("Password reset token creation failed for user %s: %v", userId.Hex(), err)
@@ -295,6 +447,7 @@ func (s *AuthService) GenerateForgotPasswordLink(userId primitive.ObjectID) (str
 	return resetToken, nil
 }
 
+// ForgotPassword sıfırlama bağlantısı ve kullanıcı adını döner; e-posta kayıtlı değilse ErrUserNotFound döner
 func (s *AuthService) ForgotPassword(email string) (*string, *string, error) {
 
 	// Kullanıcıyı bul
@@ -309,74 +462,95 @@ func (s *AuthService) ForgotPassword(email string) (*string, *string, error) {
 		return nil, nil, err
 	}
 
-	// Şifre sıfırlama linkini oluştur
-	link := fmt.Sprintf("http://localhost:8000/resetPassword?token=%s", token)
+	// Şifre sıfırlama linkini yapılandırılmış adres üzerinden oluştur
+	resetURL, err := url.Parse(s.passwordResetConfig.BaseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("şifre sıfırlama adresi geçersiz: %v", err)
+	}
+	query := resetURL.Query()
+	query.Set("token", token)
+	resetURL.RawQuery = query.Encode()
+	link := resetURL.String()
 
 	// Link ve kullanıcı adı döndür
 	return &link, &user.Username, nil
 
 }
 
-func (s *AuthService) ResetPassword(input *dto.ResetPasswordDto) (*string, error) {
-	var passwordReset models.PasswordReset
-	filter := bson.M{
-		"token": input.Token,
-	}
-	err := s.passwordResetCollection.FindOne(context.Background(), filter).Decode(&passwordReset)
+// ResetPassword tokeni tek seferlik olarak tüketir ve şifreyi günceller; şifresi değişen kullanıcının ID'sini döner
+func (s *AuthService) ResetPassword(input *dto.ResetPasswordDto) (primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Şifre politikası kullanıcı bilgilerine göre kontrol edilir; politika hatası tokeni tüketmez
+	now := time.Now()
+	var pending models.PasswordReset
+	err := s.passwordResetCollection.FindOne(ctx, bson.M{
+		"tokenHash": hashCode(input.Token),
+		"used":      false,
+		"expiresAt": bson.M{"$gt": now},
+	}).Decode(&pending)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("token  Not Found")
+			return primitive.NilObjectID, ErrInvalidResetToken
 		}
-		return nil, fmt.Errorf("error finding token: %v", err)
+		return primitive.NilObjectID, fmt.Errorf("error finding token: %v", err)
 	}
 
 	var user models.User
-	err = s.collection.FindOne(context.Background(), bson.M{"_id": passwordReset.UserID}).Decode(&user)
-	if err != nil {
+	if err := s.collection.FindOne(ctx, bson.M{"_id": pending.UserID}).Decode(&user); err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("User Not Found")
+			return primitive.NilObjectID, ErrUserNotFound
 		}
-		return nil, fmt.Errorf("error finding user: %v", err)
+		return primitive.NilObjectID, fmt.Errorf("error finding user: %v", err)
 	}
-	// Şifreyi hashle
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
-
-	if err != nil {
-		return nil, errors.New("An error occurred while processing the password.")
+	if err := s.passwordPolicy.Validate(input.Password, user.Username, user.Email); err != nil {
+		return primitive.NilObjectID, err
 	}
-	// Kullanıcı şifresini güncelle
-	update := bson.M{"$set": bson.M{"password": string(hashedPassword)}}
-	_, err = s.collection.UpdateOne(context.Background(), bson.M{"_id": passwordReset.UserID}, update)
-	if err != nil {
-		return nil, fmt.Errorf("error updating password: %v", err)
+
+	// Kullanılmamış ve süresi dolmamış tokeni atomik olarak kullanıldı işaretle;
+	// aynı token ile eşzamanlı iki istekten yalnızca biri başarılı olur
+	filter := bson.M{
+		"tokenHash": hashCode(input.Token),
+		"used":      false,
+		"expiresAt": bson.M{"$gt": now},
 	}
-	// Şifre sıfırlama kaydını sil
-	_, err = s.passwordResetCollection.DeleteOne(context.Background(), bson.M{"_id": passwordReset.ID})
+	update := bson.M{"$set": bson.M{"used": true, "usedAt": now}}
+
+	var passwordReset models.PasswordReset
+	err = s.passwordResetCollection.FindOneAndUpdate(ctx, filter, update).Decode(&passwordReset)
 	if err != nil {
-		return nil, err
+		if err == mongo.ErrNoDocuments {
+			return primitive.NilObjectID, ErrInvalidResetToken
+		}
+		return primitive.NilObjectID, fmt.Errorf("error finding token: %v", err)
 	}
-	message := "Password successfully reset"
-	return &message, nil
-}
 
-func (s *AuthService) UpdateStatus(userId, status string) error {
-
-	objID, err := primitive.ObjectIDFromHex(userId)
+	// Şifreyi hashle
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return errors.New("geçersiz kullanıcı ID'si")
+		return primitive.NilObjectID, errors.New("An error occurred while processing the password.")
 	}
 
-	// Durumu güncelle
-	filter := bson.M{"_id": objID}
-	update := bson.M{"$set": bson.M{"status": status}}
-
-	result, err := s.collection.UpdateOne(context.Background(), filter, update)
+	// Kullanıcı şifresini güncelle
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": passwordReset.UserID},
+		bson.M{"$set": bson.M{"password": string(hashedPassword), "updatedAt": now}},
+	)
 	if err != nil {
-		return err
+		return primitive.NilObjectID, fmt.Errorf("error updating password: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return primitive.NilObjectID, ErrUserNotFound
 	}
 
-	if result.ModifiedCount == 0 {
-		return errors.New("kullanıcı bulunamadı veya durum güncellenmedi")
+	// Kullanıcının bekleyen diğer sıfırlama bağlantılarını da geçersiz kıl
+	if _, err := s.passwordResetCollection.UpdateMany(ctx,
+		bson.M{"userId": passwordReset.UserID, "used": false},
+		bson.M{"$set": bson.M{"used": true, "usedAt": now}},
+	); err != nil {
+		log.Printf("Bekleyen şifre sıfırlama tokenleri geçersiz kılınamadı: %v", err)
 	}
-	return nil
+
+	return passwordReset.UserID, nil
 }
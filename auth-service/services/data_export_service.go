@@ -0,0 +1,360 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// authExportPart auth-service'in arşive eklediği parçanın adıdır
+	authExportPart = "auth"
+	// dataExportSweepBatch bir taramada işlenen en fazla istek sayısıdır
+	dataExportSweepBatch = 100
+)
+
+var (
+	ErrDataExportInProgress = errors.New("hazırlanmakta olan bir dışa aktarma isteğiniz var")
+	ErrDataExportTooSoon    = errors.New("kısa süre önce dışa aktarma istediniz, lütfen daha sonra tekrar deneyin")
+)
+
+// DataExportService kullanıcının tüm servislerde tutulan verilerini dışa aktarır. auth-service kendi parçasını
+// hemen ekler ve diğer servislerden "data_export_requested" mesajıyla parçalarını ister; tüm parçalar gelince
+// zip arşivi oluşturulur ve süreli indirme bağlantısı e-postayla gönderilir.
+type DataExportService struct {
+	exportRepo  *repository.DataExportRepository
+	userRepo    *repository.UserRepository
+	sessionRepo *redisrepo.RedisRepository
+	rabbitMQ    *messaging.RabbitMQ
+	config      config.DataExportConfig
+}
+
+func NewDataExportService(exportRepo *repository.DataExportRepository, userRepo *repository.UserRepository, sessionRepo *redisrepo.RedisRepository, rabbitMQ *messaging.RabbitMQ, cfg config.DataExportConfig) *DataExportService {
+	return &DataExportService{
+		exportRepo:  exportRepo,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		rabbitMQ:    rabbitMQ,
+		config:      cfg,
+	}
+}
+
+// Request yeni bir dışa aktarma isteği oluşturur ve diğer servislerden parçalarını ister
+func (s *DataExportService) Request(userID string) (*models.DataExport, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	previous, err := s.exportRepo.ListUserExports(user.ID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(previous) > 0 {
+		switch {
+		case previous[0].Status == models.DataExportPending, previous[0].Status == models.DataExportAssembling:
+			return nil, ErrDataExportInProgress
+		case previous[0].Status != models.DataExportFailed && now.Sub(previous[0].RequestedAt) < s.config.MinInterval:
+			return nil, ErrDataExportTooSoon
+		}
+	}
+
+	export := &models.DataExport{
+		UserID:      user.ID,
+		Status:      models.DataExportPending,
+		RequestedAt: now,
+	}
+	if err := s.exportRepo.Create(export); err != nil {
+		return nil, err
+	}
+
+	part, err := s.authPart(user)
+	if err != nil {
+		return nil, s.fail(export, fmt.Sprintf("auth verisi hazırlanamadı: %v", err))
+	}
+	updated, err := s.exportRepo.SavePart(export.ID, authExportPart, part, now)
+	if err != nil {
+		return nil, s.fail(export, fmt.Sprintf("auth verisi kaydedilemedi: %v", err))
+	}
+
+	// Diğer servis yoksa arşiv hemen hazırlanır
+	if s.complete(updated) {
+		s.assemble(updated)
+		return updated, nil
+	}
+
+	message := messaging.Message{
+		Type: "data_export_requested",
+		Data: map[string]interface{}{
+			"export_id": export.ID.Hex(),
+			"user_id":   userID,
+		},
+	}
+	if err := s.rabbitMQ.PublishMessage(context.Background(), message); err != nil {
+		return nil, s.fail(export, fmt.Sprintf("data_export_requested mesajı gönderilemedi: %v", err))
+	}
+	return updated, nil
+}
+
+// authPart kullanıcının auth profilini ve açık oturumlarını JSON olarak döner; şifre özeti ve 2FA
+// sırları gibi kimlik bilgileri dışa aktarılmaz
+func (s *DataExportService) authPart(user *models.User) ([]byte, error) {
+	sessions, err := s.sessionRepo.ListUserSessions(user.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	profile := map[string]interface{}{
+		"id":               user.ID.Hex(),
+		"username":         user.Username,
+		"email":            user.Email,
+		"firstName":        user.FirstName,
+		"lastName":         user.LastName,
+		"age":              user.Age,
+		"profilePhoto":     user.ProfilePhoto,
+		"roles":            user.Roles,
+		"status":           user.Status,
+		"lastSeenAt":       user.LastSeenAt,
+		"twoFactorEnabled": user.TwoFactor != nil && user.TwoFactor.Enabled,
+		"restriction":      user.Restriction,
+		"createdAt":        user.CreatedAt,
+		"updatedAt":        user.UpdatedAt,
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"profile":  profile,
+		"sessions": sessions,
+	}, "", "  ")
+}
+
+// ReceivePart bir servisin gönderdiği parçayı kaydeder; beklenen tüm parçalar geldiyse arşivi hazırlar
+func (s *DataExportService) ReceivePart(exportID, service string, data interface{}) error {
+	objID, err := primitive.ObjectIDFromHex(exportID)
+	if err != nil || service == "" || service == authExportPart {
+		return fmt.Errorf("geçersiz dışa aktarma parçası: %s/%s", exportID, service)
+	}
+
+	part, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	export, err := s.exportRepo.SavePart(objID, service, part, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrDataExportNotFound) {
+			// İstek zaman aşımına uğramış veya arşiv zaten hazırlanmış; parçayı yeniden denemenin anlamı yok
+			log.Printf("Dışa aktarma parçası kabul edilmedi (%s/%s): %v", exportID, service, err)
+			return nil
+		}
+		return err
+	}
+	log.Printf("Dışa aktarma parçası alındı (%s): %s", service, exportID)
+
+	if s.complete(export) {
+		s.assemble(export)
+	}
+	return nil
+}
+
+// complete auth ve beklenen tüm servislerin parçalarının gelip gelmediğini döner
+func (s *DataExportService) complete(export *models.DataExport) bool {
+	return len(s.missingParts(export)) == 0
+}
+
+// missingParts henüz gelmeyen parçaların adlarını döner
+func (s *DataExportService) missingParts(export *models.DataExport) []string {
+	var missing []string
+	for _, service := range append([]string{authExportPart}, s.config.RequiredServices...) {
+		if _, ok := export.Parts[service]; !ok {
+			missing = append(missing, service)
+		}
+	}
+	return missing
+}
+
+// assemble parçaları zip arşivinde birleştirir, indirme bağlantısını oluşturur ve kullanıcıya e-postayla
+// gönderir. Son parçayı aynı anda alan servis örneklerinden yalnızca biri arşivi hazırlar.
+func (s *DataExportService) assemble(export *models.DataExport) {
+	claimed, err := s.exportRepo.ClaimForAssembly(export.ID)
+	if err != nil || !claimed {
+		if err != nil {
+			log.Printf("Dışa aktarma arşivi hazırlanamadı (%s): %v", export.ID.Hex(), err)
+		}
+		return
+	}
+
+	token, err := s.buildBundle(export)
+	if err != nil {
+		log.Printf("Dışa aktarma arşivi hazırlanamadı (%s): %v", export.ID.Hex(), err)
+		if _, err := s.exportRepo.Close(export.ID, models.DataExportAssembling, models.DataExportFailed, "arşiv oluşturulamadı", time.Now()); err != nil {
+			log.Printf("Dışa aktarma isteği kapatılamadı: %v", err)
+		}
+		return
+	}
+
+	user, err := s.userRepo.FindUserByID(export.UserID.Hex())
+	if err != nil {
+		log.Printf("Dışa aktarma e-postası için kullanıcı bulunamadı: %v", err)
+		return
+	}
+	emailMessage := messaging.Message{
+		Type:      "data_export_ready",
+		ToService: messaging.EmailService,
+		Data: map[string]interface{}{
+			"email":         user.Email,
+			"userName":      user.Username,
+			"template_name": "data_export_ready.html",
+			"download_url":  s.config.BaseURL + "?token=" + url.QueryEscape(token),
+			"expiresAt":     export.ExpiresAt,
+		},
+	}
+	if err := s.rabbitMQ.PublishMessage(context.Background(), emailMessage); err != nil {
+		log.Printf("Dışa aktarma e-postası gönderilemedi: %v", err)
+	}
+}
+
+// buildBundle arşivi oluşturup kaydeder ve düz metin indirme tokenini döner
+func (s *DataExportService) buildBundle(export *models.DataExport) (string, error) {
+	parts, err := s.exportRepo.ReadParts(export.ID)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(parts))
+	for name := range parts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range names {
+		file, err := archive.Create(name)
+		if err != nil {
+			return "", err
+		}
+		if _, err := file.Write(parts[name]); err != nil {
+			return "", err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return "", err
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.config.LinkTTL)
+	export.TokenHash = hashCode(token)
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	if err := s.exportRepo.SaveBundle(export, buf.Bytes()); err != nil {
+		return "", err
+	}
+	export.Status = models.DataExportReady
+	return token, nil
+}
+
+// fail isteği başarısız olarak kapatır ve hatayı döner
+func (s *DataExportService) fail(export *models.DataExport, reason string) error {
+	if _, err := s.exportRepo.Close(export.ID, models.DataExportPending, models.DataExportFailed, reason, time.Now()); err != nil {
+		log.Printf("Dışa aktarma isteği kapatılamadı: %v", err)
+	}
+	return errors.New(reason)
+}
+
+// ListExports kullanıcının son dışa aktarma isteklerini döner
+func (s *DataExportService) ListExports(userID string) ([]models.DataExport, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.exportRepo.ListUserExports(objID, 10)
+}
+
+// OpenDownload indirme tokenine ait arşivi açar; bağlantı süresi boyunca birden fazla kez indirilebilir
+func (s *DataExportService) OpenDownload(token string) (*models.DataExport, io.ReadCloser, error) {
+	export, err := s.exportRepo.FindReadyByTokenHash(hashCode(token), time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.FileID == nil {
+		return nil, nil, repository.ErrDataExportNotFound
+	}
+	bundle, err := s.exportRepo.OpenBundle(*export.FileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return export, bundle, nil
+}
+
+// Sweep parçaları zamanında gelmeyen istekleri başarısız sayar ve bağlantı süresi dolan arşivleri siler
+func (s *DataExportService) Sweep(now time.Time) error {
+	stalled, err := s.exportRepo.FindStale(
+		[]models.DataExportStatus{models.DataExportPending, models.DataExportAssembling},
+		"requestedAt", now.Add(-s.config.PartTimeout), dataExportSweepBatch,
+	)
+	if err != nil {
+		return err
+	}
+	for i := range stalled {
+		export := &stalled[i]
+		reason := "arşiv zamanında oluşturulamadı"
+		if missing := s.missingParts(export); len(missing) > 0 {
+			reason = "yanıt vermeyen servisler: " + strings.Join(missing, ", ")
+		}
+		if _, err := s.exportRepo.Close(export.ID, export.Status, models.DataExportFailed, reason, now); err != nil {
+			log.Printf("Dışa aktarma isteği kapatılamadı (%s): %v", export.ID.Hex(), err)
+		}
+	}
+
+	expired, err := s.exportRepo.FindStale([]models.DataExportStatus{models.DataExportReady}, "expiresAt", now, dataExportSweepBatch)
+	if err != nil {
+		return err
+	}
+	for _, export := range expired {
+		if _, err := s.exportRepo.Close(export.ID, models.DataExportReady, models.DataExportExpired, "", now); err != nil {
+			log.Printf("Süresi dolan dışa aktarma arşivi silinemedi (%s): %v", export.ID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// StartSweeper zaman aşımına uğrayan istekleri ve süresi dolan arşivleri yapılandırılan aralıklarla temizler;
+// stop kapatılınca durur
+func (s *DataExportService) StartSweeper(stop <-chan struct{}) {
+	if s.config.SweepInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := s.Sweep(now); err != nil {
+				log.Printf("Dışa aktarma istekleri taranamadı: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,499 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/jwks"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	federationStatePrefix = "oidc_state:"
+	providerKeysCacheTTL  = 1 * time.Hour
+)
+
+var (
+	ErrUnknownProvider           = errors.New("bilinmeyen kimlik sağlayıcı")
+	ErrFederationStateInvalid    = errors.New("giriş isteği geçersiz veya süresi dolmuş, lütfen tekrar deneyin")
+	ErrFederatedEmailNotVerified = errors.New("kimlik sağlayıcı e-posta adresinizi doğrulamamış")
+	ErrFederatedTokenInvalid     = errors.New("kimlik sağlayıcıdan gelen token doğrulanamadı")
+)
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_.-]`)
+
+// FederatedProvider giriş ekranında listelenecek sağlayıcı bilgisidir
+type FederatedProvider struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// federationState sağlayıcıya yönlendirme ile geri dönüş arasında saklanan giriş isteğidir
+type federationState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+	ReturnTo     string `json:"returnTo"`
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// oidcProvider sağlayıcının keşif belgesini ve anahtarlarını ilk kullanımda çekip önbellekte tutar
+type oidcProvider struct {
+	config     config.OIDCProviderConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	verifier  *jwks.Verifier
+}
+
+func (p *oidcProvider) discover() (*oidcDiscovery, *jwks.Verifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, p.verifier, nil
+	}
+
+	resp, err := p.httpClient.Get(p.config.Issuer + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s keşif belgesi alınamadı: %w", p.config.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%s keşif belgesi alınamadı: HTTP %d", p.config.Name, resp.StatusCode)
+	}
+
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, nil, fmt.Errorf("%s keşif belgesi çözümlenemedi: %w", p.config.Name, err)
+	}
+	// Belgedeki issuer yapılandırılan adresle aynı olmalı (OIDC Discovery 4.3)
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.config.Issuer {
+		return nil, nil, fmt.Errorf("%s keşif belgesindeki issuer eşleşmiyor: %s", p.config.Name, discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+		return nil, nil, fmt.Errorf("%s keşif belgesi eksik", p.config.Name)
+	}
+
+	p.discovery = &discovery
+	p.verifier = jwks.NewVerifier(discovery.JwksURI, providerKeysCacheTTL).WithHTTPClient(p.httpClient)
+	return p.discovery, p.verifier, nil
+}
+
+// FederationService auth-service'in harici OIDC sağlayıcılarına karşı relying party olarak çalışmasını sağlar
+type FederationService struct {
+	providers     map[string]*oidcProvider
+	providerOrder []string
+	store         federationStore
+	states        federationStateStore
+	publisher     messagePublisher
+	inviteService *InviteService
+	config        config.FederationConfig
+}
+
+func NewFederationService(inviteService *InviteService, rabbitMQ *messaging.RabbitMQ, cfg config.FederationConfig) *FederationService {
+	return newFederationService(newMongoFederationStore(), &redisFederationStateStore{client: database.RedisClient}, rabbitMQ, inviteService, cfg)
+}
+
+func newFederationService(store federationStore, states federationStateStore, publisher messagePublisher, inviteService *InviteService, cfg config.FederationConfig) *FederationService {
+	s := &FederationService{
+		providers:     make(map[string]*oidcProvider),
+		store:         store,
+		states:        states,
+		publisher:     publisher,
+		inviteService: inviteService,
+		config:        cfg,
+	}
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	for _, provider := range cfg.Providers {
+		s.providers[provider.Name] = &oidcProvider{config: provider, httpClient: httpClient}
+		s.providerOrder = append(s.providerOrder, provider.Name)
+	}
+	return s
+}
+
+// WithHTTPClient tüm sağlayıcılar için özel bir HTTP istemcisi kullanır (testlerde sahte sağlayıcı için)
+func (s *FederationService) WithHTTPClient(client *http.Client) *FederationService {
+	for _, provider := range s.providers {
+		provider.httpClient = client
+	}
+	return s
+}
+
+// Providers yapılandırılmış sağlayıcıları döner
+func (s *FederationService) Providers() []FederatedProvider {
+	providers := make([]FederatedProvider, 0, len(s.providerOrder))
+	for _, name := range s.providerOrder {
+		providers = append(providers, FederatedProvider{Name: name, DisplayName: s.providers[name].config.DisplayName})
+	}
+	return providers
+}
+
+func (s *FederationService) callbackURL(providerName string) string {
+	return strings.TrimSuffix(s.config.CallbackBaseURL, "/") + "/" + providerName + "/callback"
+}
+
+// SafeReturnTo açık yönlendirmeyi önlemek için yalnızca SuccessURL ile aynı kökendeki adresleri kabul eder
+func (s *FederationService) SafeReturnTo(returnTo string) string {
+	success, err := url.Parse(s.config.SuccessURL)
+	if returnTo == "" || err != nil {
+		return s.config.SuccessURL
+	}
+	// Göreli yollar ön yüzün adresine göre çözülür
+	if strings.HasPrefix(returnTo, "/") && !strings.HasPrefix(returnTo, "//") && !strings.HasPrefix(returnTo, "/\\") {
+		if target, err := url.Parse(returnTo); err == nil {
+			return success.ResolveReference(target).String()
+		}
+		return s.config.SuccessURL
+	}
+
+	target, err := url.Parse(returnTo)
+	if err != nil || target.Scheme != success.Scheme || target.Host != success.Host {
+		return s.config.SuccessURL
+	}
+	return returnTo
+}
+
+// BeginLogin sağlayıcının yetkilendirme adresini ve tarayıcıya bağlanacak state değerini döner
+func (s *FederationService) BeginLogin(providerName, returnTo string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+	discovery, _, err := provider.discover()
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := generateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := generateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, err := generateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := json.Marshal(federationState{
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ReturnTo:     s.SafeReturnTo(returnTo),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.states.Save(federationStatePrefix+hashCode(state), data, s.config.StateTTL); err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", "", err
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.config.ClientID)
+	query.Set("redirect_uri", s.callbackURL(providerName))
+	query.Set("scope", strings.Join(provider.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", PKCEMethodS256)
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), state, nil
+}
+
+// takeState giriş isteğini tek kullanımlık olarak alır
+func (s *FederationService) takeState(state string) (*federationState, error) {
+	data, err := s.states.Take(federationStatePrefix + hashCode(state))
+	if err != nil {
+		return nil, err
+	}
+
+	var stored federationState
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// CompleteLogin sağlayıcıdan dönen kodu tokene çevirir, ID tokeni doğrular ve yerel kullanıcıyı bulur veya oluşturur.
+// Kullanıcı bu girişte oluşturulduysa diğer servislere user_created mesajı gönderilir.
+func (s *FederationService) CompleteLogin(providerName, state, code string) (*models.User, string, error) {
+	stored, err := s.takeState(state)
+	if err != nil {
+		return nil, "", err
+	}
+	if stored.Provider != providerName {
+		return nil, "", ErrFederationStateInvalid
+	}
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, "", ErrUnknownProvider
+	}
+
+	discovery, verifier, err := provider.discover()
+	if err != nil {
+		return nil, "", err
+	}
+	idToken, err := s.exchangeCode(provider, discovery, code, stored.CodeVerifier)
+	if err != nil {
+		return nil, "", err
+	}
+
+	claims, err := verifier.Verify(idToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrFederatedTokenInvalid, err)
+	}
+	if err := validateIDTokenClaims(claims, discovery.Issuer, provider.config.ClientID, stored.Nonce); err != nil {
+		return nil, "", err
+	}
+
+	user, created, err := s.resolveUser(providerName, claims)
+	if err != nil {
+		return nil, "", err
+	}
+	if created {
+		if err := s.publisher.PublishMessage(context.Background(), UserCreatedMessage(user)); err != nil {
+			log.Printf("Kullanıcı oluşturma mesajı gönderilemedi: %v", err)
+		}
+	}
+	return user, stored.ReturnTo, nil
+}
+
+func (s *FederationService) exchangeCode(provider *oidcProvider, discovery *oidcDiscovery, code, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {models.GrantAuthorizationCode},
+		"code":          {code},
+		"redirect_uri":  {s.callbackURL(provider.config.Name)},
+		"client_id":     {provider.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	if provider.config.ClientSecret != "" {
+		form.Set("client_secret", provider.config.ClientSecret)
+	}
+
+	resp, err := provider.httpClient.PostForm(discovery.TokenEndpoint, form)
+	if err != nil {
+		return "", fmt.Errorf("%s token isteği başarısız: %w", provider.config.Name, err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResponse struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("%s token yanıtı çözümlenemedi: %w", provider.config.Name, err)
+	}
+	if resp.StatusCode != http.StatusOK || tokenResponse.Error != "" {
+		return "", fmt.Errorf("%w: %s %s", ErrFederatedTokenInvalid, tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+	if tokenResponse.IDToken == "" {
+		return "", fmt.Errorf("%w: id_token dönmedi", ErrFederatedTokenInvalid)
+	}
+	return tokenResponse.IDToken, nil
+}
+
+// validateIDTokenClaims OIDC Core 3.1.3.7'deki issuer, audience ve nonce kontrollerini yapar
+func validateIDTokenClaims(claims map[string]interface{}, issuer, clientID, nonce string) error {
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(issuer, "/") {
+		return fmt.Errorf("%w: issuer eşleşmiyor", ErrFederatedTokenInvalid)
+	}
+
+	audienceMatches := false
+	switch aud := claims["aud"].(type) {
+	case string:
+		audienceMatches = aud == clientID
+	case []interface{}:
+		for _, value := range aud {
+			if value == clientID {
+				audienceMatches = true
+			}
+		}
+	}
+	if !audienceMatches {
+		return fmt.Errorf("%w: audience eşleşmiyor", ErrFederatedTokenInvalid)
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != clientID {
+		return fmt.Errorf("%w: azp eşleşmiyor", ErrFederatedTokenInvalid)
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return fmt.Errorf("%w: nonce eşleşmiyor", ErrFederatedTokenInvalid)
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return fmt.Errorf("%w: sub eksik", ErrFederatedTokenInvalid)
+	}
+	return nil
+}
+
+// emailVerified bazı sağlayıcıların email_verified değerini metin olarak göndermesini de kabul eder
+func emailVerified(claims map[string]interface{}) bool {
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	}
+	return false
+}
+
+// resolveUser sağlayıcı hesabına bağlı kullanıcıyı döner; bağlantı yoksa doğrulanmış e-posta ile
+// mevcut hesaba bağlar veya yeni bir kullanıcı oluşturur
+func (s *FederationService) resolveUser(providerName string, claims map[string]interface{}) (*models.User, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	subject, _ := claims["sub"].(string)
+	now := time.Now()
+
+	identity, err := s.store.TouchIdentity(ctx, providerName, subject, now)
+	if err == nil {
+		user, err := s.store.FindActiveUserByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, false, err
+		}
+		return user, false, nil
+	}
+	if err != errIdentityNotFound {
+		return nil, false, err
+	}
+
+	// Doğrulanmamış bir e-posta ile bağlanmak, adresi sahiplenmeyen birine başkasının hesabını açtırır
+	email, _ := claims["email"].(string)
+	email = strings.TrimSpace(email)
+	if email == "" || !emailVerified(claims) {
+		return nil, false, ErrFederatedEmailNotVerified
+	}
+
+	created := false
+	user, err := s.store.FindUserByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if user.IsDeleted {
+			return nil, false, ErrUserNotFound
+		}
+	case err == ErrUserNotFound:
+		// Sağlayıcı akışında davet kodu sorulamaz; davetle kayıt modunda kullanıcı önce davetle kayıt olup
+		// ardından aynı e-posta adresiyle sağlayıcı girişini kullanabilir
+		if !s.inviteService.SignUpAllowed() {
+			return nil, false, ErrRegistrationClosed
+		}
+		user, err = s.createUser(ctx, email, claims)
+		if err != nil {
+			return nil, false, err
+		}
+		created = true
+	default:
+		return nil, false, err
+	}
+
+	err = s.store.LinkIdentity(ctx, &models.FederatedIdentity{
+		UserID:      user.ID,
+		Provider:    providerName,
+		Subject:     subject,
+		Email:       email,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("hesap bağlantısı kaydedilemedi: %v", err)
+	}
+	return user, created, nil
+}
+
+// createUser sağlayıcıdan gelen bilgilerle şifresi rastgele olan yeni bir kullanıcı oluşturur;
+// kullanıcı isterse şifre sıfırlama ile şifre belirleyebilir
+func (s *FederationService) createUser(ctx context.Context, email string, claims map[string]interface{}) (*models.User, error) {
+	preferred, _ := claims["preferred_username"].(string)
+	if preferred == "" {
+		preferred = email[:strings.Index(email, "@")]
+	}
+	username, err := s.uniqueUsername(ctx, preferred)
+	if err != nil {
+		return nil, err
+	}
+
+	randomPassword, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.NewUser()
+	user.Username = username
+	user.Email = email
+	user.Password = string(hashedPassword)
+	user.FirstName, _ = claims["given_name"].(string)
+	user.LastName, _ = claims["family_name"].(string)
+	if picture, ok := claims["picture"].(string); ok && picture != "" {
+		user.ProfilePhoto = &picture
+	}
+
+	if err := s.store.CreateUser(ctx, &user); err != nil {
+		return nil, fmt.Errorf("kullanıcı kaydedilemedi: %v", err)
+	}
+	return &user, nil
+}
+
+// uniqueUsername kullanıcı adı kurallarına uyan ve kullanılmayan bir ad üretir
+func (s *FederationService) uniqueUsername(ctx context.Context, preferred string) (string, error) {
+	base := usernameInvalidChars.ReplaceAllString(strings.ToLower(preferred), "")
+	if len(base) < 3 {
+		base = "user" + base
+	}
+	if len(base) > 25 {
+		base = base[:25]
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		exists, err := s.store.UsernameExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+		suffix, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s%04d", base, suffix.Int64())
+	}
+	return "", errors.New("benzersiz kullanıcı adı üretilemedi")
+}
@@ -0,0 +1,432 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/golang-jwt/jwt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	testProviderName = "stub"
+	testClientID     = "auth-service"
+	testKeyID        = "stub-key"
+	testAuthCode     = "stub-code"
+)
+
+// stubIssuer keşif belgesi, JWKS ve token uç noktası sunan sahte bir OIDC sağlayıcısıdır.
+// Token uç noktası testin idToken alanına koyduğu ID tokeni döner.
+type stubIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu            sync.Mutex
+	idToken       string
+	codeChallenge string
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &stubIssuer{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JwksURI:               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": testKeyID,
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", issuer.handleToken)
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *stubIssuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	r.ParseForm()
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("code") != testAuthCode || r.PostForm.Get("client_id") != testClientID ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != i.codeChallenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "stub", "token_type": "Bearer", "id_token": i.idToken})
+}
+
+// sign claims'i verilen anahtarla RS256 ile imzalar
+func (i *stubIssuer) sign(key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	i.t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(key)
+	if err != nil {
+		i.t.Fatal(err)
+	}
+	return signed
+}
+
+// claims sağlayıcının geçerli bir girişte döneceği ID token içeriğidir
+func (i *stubIssuer) claims(nonce, email string, verified bool) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            i.server.URL,
+		"aud":            testClientID,
+		"sub":            "stub-subject",
+		"nonce":          nonce,
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"email":          email,
+		"email_verified": verified,
+		"given_name":     "Ayşe",
+	}
+}
+
+type memoryFederationStore struct {
+	mu         sync.Mutex
+	users      []*models.User
+	identities []*models.FederatedIdentity
+}
+
+func (m *memoryFederationStore) TouchIdentity(ctx context.Context, provider, subject string, now time.Time) (*models.FederatedIdentity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, identity := range m.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			identity.LastLoginAt = now
+			return identity, nil
+		}
+	}
+	return nil, errIdentityNotFound
+}
+
+func (m *memoryFederationStore) FindActiveUserByID(ctx context.Context, userID primitive.ObjectID) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, user := range m.users {
+		if user.ID == userID && !user.IsDeleted {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (m *memoryFederationStore) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (m *memoryFederationStore) UsernameExists(ctx context.Context, username string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, user := range m.users {
+		if user.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryFederationStore) CreateUser(ctx context.Context, user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user.ID = primitive.NewObjectID()
+	m.users = append(m.users, user)
+	return nil
+}
+
+func (m *memoryFederationStore) LinkIdentity(ctx context.Context, identity *models.FederatedIdentity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.identities = append(m.identities, identity)
+	return nil
+}
+
+type memoryStateStore struct {
+	mu     sync.Mutex
+	states map[string][]byte
+}
+
+func (m *memoryStateStore) Save(key string, data []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[key] = data
+	return nil
+}
+
+func (m *memoryStateStore) Take(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.states[key]
+	if !ok {
+		return nil, ErrFederationStateInvalid
+	}
+	delete(m.states, key)
+	return data, nil
+}
+
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages []messaging.Message
+}
+
+func (p *recordingPublisher) PublishMessage(ctx context.Context, msg messaging.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+type federationFixture struct {
+	issuer    *stubIssuer
+	store     *memoryFederationStore
+	publisher *recordingPublisher
+	service   *FederationService
+}
+
+func newFederationFixture(t *testing.T) *federationFixture {
+	t.Helper()
+	issuer := newStubIssuer(t)
+	store := &memoryFederationStore{}
+	publisher := &recordingPublisher{}
+	inviteService := NewInviteService(nil, nil, config.RegistrationConfig{Mode: config.RegistrationOpen})
+	service := newFederationService(store, &memoryStateStore{states: make(map[string][]byte)}, publisher, inviteService, config.FederationConfig{
+		Providers: []config.OIDCProviderConfig{{
+			Name:     testProviderName,
+			Issuer:   issuer.server.URL,
+			ClientID: testClientID,
+			Scopes:   []string{"openid", "email", "profile"},
+		}},
+		CallbackBaseURL: "https://auth.example.com/auth/federated",
+		SuccessURL:      "https://app.example.com/",
+		StateTTL:        time.Minute,
+	}).WithHTTPClient(issuer.server.Client())
+	return &federationFixture{issuer: issuer, store: store, publisher: publisher, service: service}
+}
+
+// beginLogin girişi başlatır ve sağlayıcıya gönderilen state ile nonce değerlerini döner
+func (f *federationFixture) beginLogin(t *testing.T) (string, string) {
+	t.Helper()
+	authURL, state, err := f.service.BeginLogin(testProviderName, "")
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("state") != state || query.Get("client_id") != testClientID {
+		t.Fatalf("yetkilendirme adresi beklenen parametreleri içermiyor: %s", authURL)
+	}
+	f.issuer.mu.Lock()
+	f.issuer.codeChallenge = query.Get("code_challenge")
+	f.issuer.mu.Unlock()
+	return state, query.Get("nonce")
+}
+
+func (f *federationFixture) completeWith(t *testing.T, state, idToken string) (*models.User, error) {
+	t.Helper()
+	f.issuer.mu.Lock()
+	f.issuer.idToken = idToken
+	f.issuer.mu.Unlock()
+	user, _, err := f.service.CompleteLogin(testProviderName, state, testAuthCode)
+	return user, err
+}
+
+func TestCompleteLoginLinksExistingUserByVerifiedEmail(t *testing.T) {
+	f := newFederationFixture(t)
+	existing := models.NewUser()
+	existing.ID = primitive.NewObjectID()
+	existing.Username = "ayse"
+	existing.Email = "ayse@example.com"
+	f.store.users = append(f.store.users, &existing)
+
+	state, nonce := f.beginLogin(t)
+	user, err := f.completeWith(t, state, f.issuer.sign(f.issuer.key, f.issuer.claims(nonce, existing.Email, true)))
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if user.ID != existing.ID {
+		t.Fatalf("mevcut kullanıcı yerine %s döndü", user.ID.Hex())
+	}
+	if len(f.store.identities) != 1 || f.store.identities[0].UserID != existing.ID || f.store.identities[0].Subject != "stub-subject" {
+		t.Fatalf("hesap bağlantısı beklendiği gibi kaydedilmedi: %+v", f.store.identities)
+	}
+	if len(f.store.users) != 1 || len(f.publisher.messages) != 0 {
+		t.Fatal("mevcut hesaba bağlanırken yeni kullanıcı oluşturulmamalı")
+	}
+
+	// Bağlantı kurulduktan sonra aynı sağlayıcı hesabı e-postaya bakılmadan aynı kullanıcıya girer
+	state, nonce = f.beginLogin(t)
+	user, err = f.completeWith(t, state, f.issuer.sign(f.issuer.key, f.issuer.claims(nonce, "", false)))
+	if err != nil {
+		t.Fatalf("bağlı hesapla ikinci giriş: %v", err)
+	}
+	if user.ID != existing.ID {
+		t.Fatalf("bağlı hesap başka kullanıcıya girdi: %s", user.ID.Hex())
+	}
+}
+
+func TestCompleteLoginRejectsUnverifiedEmail(t *testing.T) {
+	f := newFederationFixture(t)
+	existing := models.NewUser()
+	existing.ID = primitive.NewObjectID()
+	existing.Email = "ayse@example.com"
+	f.store.users = append(f.store.users, &existing)
+
+	state, nonce := f.beginLogin(t)
+	_, err := f.completeWith(t, state, f.issuer.sign(f.issuer.key, f.issuer.claims(nonce, existing.Email, false)))
+	if !errors.Is(err, ErrFederatedEmailNotVerified) {
+		t.Fatalf("ErrFederatedEmailNotVerified bekleniyordu, %v döndü", err)
+	}
+	if len(f.store.identities) != 0 || len(f.store.users) != 1 {
+		t.Fatal("doğrulanmamış e-posta ile hesap bağlanmamalı veya oluşturulmamalı")
+	}
+}
+
+func TestCompleteLoginCreatesUserAndPublishesUserCreated(t *testing.T) {
+	f := newFederationFixture(t)
+
+	state, nonce := f.beginLogin(t)
+	claims := f.issuer.claims(nonce, "yeni.kullanici@example.com", true)
+	claims["preferred_username"] = "Yeni Kullanıcı"
+	user, err := f.completeWith(t, state, f.issuer.sign(f.issuer.key, claims))
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if user.ID.IsZero() || user.Email != "yeni.kullanici@example.com" || user.FirstName != "Ayşe" {
+		t.Fatalf("kullanıcı sağlayıcı bilgileriyle oluşturulmadı: %+v", user)
+	}
+	if user.Username != "yenikullanc" {
+		t.Fatalf("kullanıcı adı kurallara göre üretilmedi: %q", user.Username)
+	}
+	if len(f.store.identities) != 1 || f.store.identities[0].UserID != user.ID {
+		t.Fatalf("yeni kullanıcı sağlayıcı hesabına bağlanmadı: %+v", f.store.identities)
+	}
+
+	if len(f.publisher.messages) != 1 {
+		t.Fatalf("bir user_created mesajı bekleniyordu, %d mesaj gönderildi", len(f.publisher.messages))
+	}
+	message := f.publisher.messages[0]
+	data, _ := message.Data.(map[string]interface{})
+	if message.Type != "user_created" || message.ToService != messaging.UserService || data["user_id"] != user.ID || data["username"] != user.Username {
+		t.Fatalf("user_created mesajı beklendiği gibi değil: %+v", message)
+	}
+}
+
+func TestCompleteLoginRejectsInvalidIDTokens(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		key    func(f *federationFixture) *rsa.PrivateKey
+		mutate func(claims jwt.MapClaims)
+	}{
+		{
+			name: "imza başka anahtarla atılmış",
+			key:  func(f *federationFixture) *rsa.PrivateKey { return otherKey },
+		},
+		{
+			name:   "issuer farklı",
+			mutate: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+		},
+		{
+			name:   "audience farklı",
+			mutate: func(claims jwt.MapClaims) { claims["aud"] = "another-client" },
+		},
+		{
+			name:   "audience dizisinde istemci yok",
+			mutate: func(claims jwt.MapClaims) { claims["aud"] = []string{"another-client"} },
+		},
+		{
+			name:   "nonce farklı",
+			mutate: func(claims jwt.MapClaims) { claims["nonce"] = "replayed-nonce" },
+		},
+		{
+			name:   "nonce eksik",
+			mutate: func(claims jwt.MapClaims) { delete(claims, "nonce") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFederationFixture(t)
+			state, nonce := f.beginLogin(t)
+
+			claims := f.issuer.claims(nonce, "ayse@example.com", true)
+			if tt.mutate != nil {
+				tt.mutate(claims)
+			}
+			key := f.issuer.key
+			if tt.key != nil {
+				key = tt.key(f)
+			}
+
+			_, err := f.completeWith(t, state, f.issuer.sign(key, claims))
+			if !errors.Is(err, ErrFederatedTokenInvalid) {
+				t.Fatalf("ErrFederatedTokenInvalid bekleniyordu, %v döndü", err)
+			}
+			if len(f.store.users) != 0 || len(f.store.identities) != 0 || len(f.publisher.messages) != 0 {
+				t.Fatal("geçersiz token ile kullanıcı oluşturulmamalı veya bağlanmamalı")
+			}
+		})
+	}
+}
+
+func TestCompleteLoginStateIsSingleUse(t *testing.T) {
+	f := newFederationFixture(t)
+	state, nonce := f.beginLogin(t)
+	idToken := f.issuer.sign(f.issuer.key, f.issuer.claims(nonce, "ayse@example.com", true))
+	if _, err := f.completeWith(t, state, idToken); err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if _, err := f.completeWith(t, state, idToken); !errors.Is(err, ErrFederationStateInvalid) {
+		t.Fatalf("aynı state ikinci kez kullanılabildi: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/go-redis/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var errIdentityNotFound = errors.New("sağlayıcı hesabı bağlı değil")
+
+// federationStore harici girişte kullanılan kullanıcı ve hesap bağlantısı kayıtlarına erişir.
+// Üretimde MongoDB kullanılır; testler bellekte çalışan bir uygulama verir.
+type federationStore interface {
+	// TouchIdentity sağlayıcı hesabının bağlantısını bulur ve son giriş zamanını günceller
+	TouchIdentity(ctx context.Context, provider, subject string, now time.Time) (*models.FederatedIdentity, error)
+	FindActiveUserByID(ctx context.Context, userID primitive.ObjectID) (*models.User, error)
+	// FindUserByEmail silinmiş hesapları da döner; çağıran IsDeleted alanını kontrol eder
+	FindUserByEmail(ctx context.Context, email string) (*models.User, error)
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	// LinkIdentity bağlantıyı kaydeder; aynı bağlantı eşzamanlı bir girişte zaten oluşturulmuşsa hata dönmez
+	LinkIdentity(ctx context.Context, identity *models.FederatedIdentity) error
+}
+
+// federationStateStore sağlayıcıya yönlendirme ile geri dönüş arasındaki giriş isteklerini saklar
+type federationStateStore interface {
+	Save(key string, data []byte, ttl time.Duration) error
+	// Take kaydı tek kullanımlık olarak alır; kayıt yoksa ErrFederationStateInvalid döner
+	Take(key string) ([]byte, error)
+}
+
+// messagePublisher *messaging.RabbitMQ'nun servislerin kullandığı kısmıdır
+type messagePublisher interface {
+	PublishMessage(ctx context.Context, msg messaging.Message) error
+}
+
+type mongoFederationStore struct {
+	users      *mongo.Collection
+	identities *mongo.Collection
+}
+
+func newMongoFederationStore() *mongoFederationStore {
+	db := database.MongoClient.Database("authDB")
+	return &mongoFederationStore{
+		users:      db.Collection("users"),
+		identities: db.Collection("federated_identities"),
+	}
+}
+
+func (m *mongoFederationStore) TouchIdentity(ctx context.Context, provider, subject string, now time.Time) (*models.FederatedIdentity, error) {
+	var identity models.FederatedIdentity
+	err := m.identities.FindOneAndUpdate(ctx,
+		bson.M{"provider": provider, "subject": subject},
+		bson.M{"$set": bson.M{"lastLoginAt": now}},
+	).Decode(&identity)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errIdentityNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (m *mongoFederationStore) findUser(ctx context.Context, filter bson.M) (*models.User, error) {
+	var user models.User
+	if err := m.users.FindOne(ctx, filter).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (m *mongoFederationStore) FindActiveUserByID(ctx context.Context, userID primitive.ObjectID) (*models.User, error) {
+	return m.findUser(ctx, bson.M{"_id": userID, "isDeleted": false})
+}
+
+func (m *mongoFederationStore) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return m.findUser(ctx, bson.M{"email": email})
+}
+
+func (m *mongoFederationStore) UsernameExists(ctx context.Context, username string) (bool, error) {
+	count, err := m.users.CountDocuments(ctx, bson.M{"username": username})
+	return count > 0, err
+}
+
+func (m *mongoFederationStore) CreateUser(ctx context.Context, user *models.User) error {
+	result, err := m.users.InsertOne(ctx, user)
+	if err != nil {
+		return err
+	}
+	user.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (m *mongoFederationStore) LinkIdentity(ctx context.Context, identity *models.FederatedIdentity) error {
+	_, err := m.identities.InsertOne(ctx, identity)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	return nil
+}
+
+type redisFederationStateStore struct {
+	client *redis.Client
+}
+
+func (r *redisFederationStateStore) Save(key string, data []byte, ttl time.Duration) error {
+	return r.client.Set(key, data, ttl).Err()
+}
+
+func (r *redisFederationStateStore) Take(key string) ([]byte, error) {
+	pipe := r.client.TxPipeline()
+	get := pipe.Get(key)
+	pipe.Del(key)
+	if _, err := pipe.Exec(); err != nil {
+		if err == redis.Nil {
+			return nil, ErrFederationStateInvalid
+		}
+		return nil, err
+	}
+	return []byte(get.Val()), nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-redis/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrImpersonationNotAllowed = errors.New("bu kullanıcı adına oturum açılamaz")
+	ErrImpersonationTTLTooLong = errors.New("oturum süresi izin verilen en uzun süreyi aşıyor")
+	ErrNotImpersonating        = errors.New("kullanıcı adına açılmış bir oturum yok")
+)
+
+// ImpersonationService destek ekibinin hataları ayıklamak için uygulamayı belirli bir kullanıcı
+// gibi görebilmesini sağlar. Açılan oturum hem yöneticinin hem kullanıcının ID'sini taşır.
+type ImpersonationService struct {
+	userRepo    *repository.UserRepository
+	sessionRepo *redisrepo.RedisRepository
+	authorizer  *middlewares.Authorizer
+	config      config.ImpersonationConfig
+}
+
+func NewImpersonationService(userRepo *repository.UserRepository, sessionRepo *redisrepo.RedisRepository, authorizer *middlewares.Authorizer, cfg config.ImpersonationConfig) *ImpersonationService {
+	return &ImpersonationService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		authorizer:  authorizer,
+		config:      cfg,
+	}
+}
+
+// Start admin adına targetID kullanıcısı olarak süreli bir oturum açar ve oturum kimliğini döner.
+// Başka kullanıcılar adına oturum açabilen hesaplar (ör. diğer yöneticiler) hedef alınamaz.
+func (s *ImpersonationService) Start(admin map[string]string, targetID string, input *dto.StartImpersonationDto, meta redisrepo.SessionMeta) (string, *dto.ImpersonationResponse, error) {
+	ttl := s.config.DefaultTTL
+	if input.DurationMinutes > 0 {
+		ttl = time.Duration(input.DurationMinutes) * time.Minute
+	}
+	if ttl > s.config.MaxTTL {
+		return "", nil, ErrImpersonationTTLTooLong
+	}
+
+	if admin["id"] == targetID {
+		return "", nil, ErrCannotModifySelf
+	}
+	if _, err := primitive.ObjectIDFromHex(targetID); err != nil {
+		return "", nil, ErrUserNotFound
+	}
+	user, err := s.userRepo.FindUserByID(targetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return "", nil, ErrUserNotFound
+		}
+		return "", nil, err
+	}
+	if user.IsDeleted {
+		return "", nil, ErrUserNotFound
+	}
+	if s.authorizer.HasPermission(user.Roles, models.PermUserImpersonate) {
+		return "", nil, ErrImpersonationNotAllowed
+	}
+
+	rolesJSON, err := json.Marshal(user.Roles)
+	if err != nil {
+		return "", nil, err
+	}
+	info := &dto.ImpersonationInfo{
+		ImpersonatorID:    admin["id"],
+		ImpersonatorEmail: admin["email"],
+		Reason:            strings.TrimSpace(input.Reason),
+		ExpiresAt:         time.Now().Add(ttl),
+	}
+	userData := map[string]string{
+		"id":                               user.ID.Hex(),
+		"email":                            user.Email,
+		"roles":                            string(rolesJSON),
+		"username":                         user.Username,
+		redisrepo.ImpersonatorIDKey:        info.ImpersonatorID,
+		redisrepo.ImpersonatorEmailKey:     info.ImpersonatorEmail,
+		redisrepo.ImpersonatorSessionIDKey: admin["session_id"],
+		redisrepo.ImpersonationReasonKey:   info.Reason,
+		redisrepo.ImpersonationExpiresKey:  info.ExpiresAt.Format(time.RFC3339),
+	}
+	meta.UserID = user.ID.Hex()
+	meta.ImpersonatorID = info.ImpersonatorID
+
+	sessionID, err := s.sessionRepo.CreateSession(userData, meta, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+	return sessionID, &dto.ImpersonationResponse{
+		User:          dto.NewUserResponse(user),
+		Impersonation: info,
+	}, nil
+}
+
+// Stop kullanıcı adına açılmış oturumu sonlandırır ve hâlâ geçerliyse yöneticinin kendi oturumunun
+// kimliğini döner; yöneticinin oturumu sona erdiyse boş döner
+func (s *ImpersonationService) Stop(userData map[string]string) (string, error) {
+	if userData[redisrepo.ImpersonatorIDKey] == "" {
+		return "", ErrNotImpersonating
+	}
+	if err := s.sessionRepo.RevokeSession(userData["id"], userData["session_id"]); err != nil && !errors.Is(err, redisrepo.ErrSessionNotFound) {
+		return "", err
+	}
+
+	adminSessionID := userData[redisrepo.ImpersonatorSessionIDKey]
+	adminSession, err := s.sessionRepo.GetSession(redisrepo.SessionKey(adminSessionID))
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+		return "", err
+	}
+	if adminSession["session_id"] != adminSessionID || adminSession["id"] != userData[redisrepo.ImpersonatorIDKey] {
+		return "", nil
+	}
+	return adminSessionID, nil
+}
+
+// ImpersonationInfoFromSession oturum kullanıcı adına açıldıysa ayrıntılarını döner
+func ImpersonationInfoFromSession(userData map[string]string) *dto.ImpersonationInfo {
+	if userData[redisrepo.ImpersonatorIDKey] == "" {
+		return nil
+	}
+	expiresAt, _ := time.Parse(time.RFC3339, userData[redisrepo.ImpersonationExpiresKey])
+	return &dto.ImpersonationInfo{
+		ImpersonatorID:    userData[redisrepo.ImpersonatorIDKey],
+		ImpersonatorEmail: userData[redisrepo.ImpersonatorEmailKey],
+		Reason:            userData[redisrepo.ImpersonationReasonKey],
+		ExpiresAt:         expiresAt,
+	}
+}
+
+// ListAudit kullanıcı adına yapılan isteklerin denetim kayıtlarını en yeniden başlayarak döner
+func (s *ImpersonationService) ListAudit(impersonatorID, userID string, limit int) ([]redisrepo.ImpersonationAuditEntry, error) {
+	return s.sessionRepo.ListImpersonationAudit(impersonatorID, userID, limit)
+}
+
+// RecordAudit oturumun açılması ve kapanması gibi yöneticinin kendi oturumuyla yaptığı işlemleri
+// denetim akışına yazar
+func (s *ImpersonationService) RecordAudit(entry *redisrepo.ImpersonationAuditEntry) error {
+	entry.Service = "auth"
+	entry.CreatedAt = time.Now()
+	return s.sessionRepo.AppendImpersonationAudit(entry)
+}
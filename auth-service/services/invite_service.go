@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	inviteDisplayPrefixLength = 8
+	inviteListLimit           = 200
+)
+
+var (
+	ErrRegistrationClosed    = errors.New("yeni kayıtlar şu anda kapalı")
+	ErrInviteRequired        = errors.New("kayıt için davet kodu gerekli")
+	ErrInviteInvalid         = errors.New("davet kodu geçersiz, süresi dolmuş veya kullanım sınırına ulaşılmış")
+	ErrInviteNotAllowed      = errors.New("davet oluşturma yetkiniz yok")
+	ErrInviteRolesNotAllowed = errors.New("davete rol atama yetkiniz yok")
+	ErrInviteLimit           = errors.New("geçerli davet sayısı sınırına ulaşıldı, önce kullanılmayan davetleri iptal edin")
+	ErrInviteTTLTooLong      = errors.New("davet süresi izin verilen en uzun süreyi aşıyor")
+	ErrInviteTooManyUses     = errors.New("davet kullanım sayısı izin verilen sınırı aşıyor")
+)
+
+// InviteCreator daveti oluşturan kullanıcıyı ve yetkilerini tanımlar
+type InviteCreator struct {
+	UserID   string
+	Username string
+	// CanManage invite:manage yetkisidir; kullanıcı davetleri için yapılandırılan sınırlar uygulanmaz
+	CanManage bool
+	// CanAssignRoles user:manage-roles yetkisidir; davetle açılacak hesaba varsayılan dışında rol verilebilir
+	CanAssignRoles bool
+}
+
+// RegistrationInfo kayıt sayfasının hangi alanları göstereceğini belirlemesi için kayıt modunu döner
+type RegistrationInfo struct {
+	Mode           string `json:"mode"`
+	UsersCanInvite bool   `json:"usersCanInvite"`
+}
+
+// InviteService kayıt modunu uygular ve davet kodlarını yönetir. Davet kayıt sırasında doğrulanır,
+// aktivasyonda kullanılır; böylece aktivasyonu tamamlanmayan kayıtlar davet hakkını harcamaz.
+type InviteService struct {
+	repo     *repository.InviteRepository
+	rabbitMQ *messaging.RabbitMQ
+	config   config.RegistrationConfig
+}
+
+func NewInviteService(repo *repository.InviteRepository, rabbitMQ *messaging.RabbitMQ, cfg config.RegistrationConfig) *InviteService {
+	return &InviteService{
+		repo:     repo,
+		rabbitMQ: rabbitMQ,
+		config:   cfg,
+	}
+}
+
+func normalizeInviteEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Info geçerli kayıt modunu döner
+func (s *InviteService) Info() RegistrationInfo {
+	return RegistrationInfo{Mode: s.config.Mode, UsersCanInvite: s.config.UsersCanInvite}
+}
+
+// SignUpAllowed harici kimlik sağlayıcısıyla ilk girişte olduğu gibi davet kodu sorulamayan
+// durumlarda yeni hesap açılıp açılamayacağını döner
+func (s *InviteService) SignUpAllowed() bool {
+	return s.config.Mode == config.RegistrationOpen
+}
+
+// CheckSignUp kayıt modunu uygular ve verilen davet kodunu email adresi için doğrular.
+// Davet kodu verilmemişse ve kayıtlar açıksa nil döner; açık modda verilen kod da doğrulanır.
+func (s *InviteService) CheckSignUp(code, email string) (*models.Invite, error) {
+	if s.config.Mode == config.RegistrationClosed {
+		return nil, ErrRegistrationClosed
+	}
+	if code == "" {
+		if s.config.Mode == config.RegistrationInviteOnly {
+			return nil, ErrInviteRequired
+		}
+		return nil, nil
+	}
+
+	invite, err := s.repo.FindUsable(hashCode(code), normalizeInviteEmail(email), time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrInviteNotFound) {
+			return nil, ErrInviteInvalid
+		}
+		return nil, err
+	}
+	return invite, nil
+}
+
+// Consume aktivasyon sırasında davetin bir kullanımını harcar
+func (s *InviteService) Consume(inviteID, email string) (*models.Invite, error) {
+	objID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return nil, ErrInviteInvalid
+	}
+	invite, err := s.repo.Consume(objID, normalizeInviteEmail(email), time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrInviteNotFound) {
+			return nil, ErrInviteInvalid
+		}
+		return nil, err
+	}
+	return invite, nil
+}
+
+// Release hesap oluşturulamadığında harcanan kullanımı geri verir
+func (s *InviteService) Release(invite *models.Invite) {
+	if err := s.repo.Release(invite.ID); err != nil {
+		log.Printf("Davet kullanımı geri alınamadı: %v", err)
+	}
+}
+
+// CreateInvite yeni bir davet oluşturur; düz metin kod yalnızca bu çağrıda döner.
+// E-posta adresi verilmişse davet bağlantısı email-service ile o adrese gönderilir.
+// knownRoles yetki dosyasında tanımlı rollerdir.
+func (s *InviteService) CreateInvite(creator InviteCreator, input *dto.CreateInviteDto, knownRoles map[models.UserRole][]models.Permission) (*models.Invite, string, error) {
+	if !creator.CanManage && !s.config.UsersCanInvite {
+		return nil, "", ErrInviteNotAllowed
+	}
+	creatorID, err := primitive.ObjectIDFromHex(creator.UserID)
+	if err != nil {
+		return nil, "", ErrUserNotFound
+	}
+
+	roles := []models.UserRole{models.USER}
+	if len(input.Roles) > 0 {
+		if !creator.CanAssignRoles {
+			return nil, "", ErrInviteRolesNotAllowed
+		}
+		roles = make([]models.UserRole, 0, len(input.Roles))
+		seen := map[models.UserRole]bool{}
+		for _, role := range input.Roles {
+			if _, ok := knownRoles[role]; !ok {
+				return nil, "", fmt.Errorf("%w: %s", ErrUnknownRole, role)
+			}
+			if !seen[role] {
+				seen[role] = true
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	maxUses := input.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+	ttl := s.config.DefaultInviteTTL
+	if input.ExpiresInDays > 0 {
+		ttl = time.Duration(input.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl > s.config.MaxInviteTTL {
+		return nil, "", ErrInviteTTLTooLong
+	}
+
+	now := time.Now()
+	if !creator.CanManage {
+		if maxUses > s.config.UserInviteMaxUses {
+			return nil, "", ErrInviteTooManyUses
+		}
+		count, err := s.repo.CountUsableUserInvites(creatorID, now)
+		if err != nil {
+			return nil, "", err
+		}
+		if count >= int64(s.config.MaxInvitesPerUser) {
+			return nil, "", ErrInviteLimit
+		}
+	}
+
+	code, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	invite := &models.Invite{
+		UserID:    creatorID,
+		CodeHash:  hashCode(code),
+		Prefix:    code[:inviteDisplayPrefixLength],
+		Email:     normalizeInviteEmail(input.Email),
+		Roles:     roles,
+		MaxUses:   maxUses,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := s.repo.Create(invite); err != nil {
+		return nil, "", fmt.Errorf("davet kaydedilemedi: %v", err)
+	}
+
+	if invite.Email != "" {
+		s.sendInvite(invite, code, creator.Username)
+	}
+	return invite, code, nil
+}
+
+// InviteURL davet kodunu kayıt sayfasının adresine ekler
+func (s *InviteService) InviteURL(code string) string {
+	return s.config.InviteBaseURL + "?code=" + url.QueryEscape(code)
+}
+
+// sendInvite davet bağlantısını email-service'e "user_invited" mesajı olarak gönderir.
+// Gönderim başarısız olursa davet geçerli kalır; kod oluşturan kişiye yanıtta döndüğü için elle iletilebilir.
+func (s *InviteService) sendInvite(invite *models.Invite, code, inviterName string) {
+	inviteMessage := messaging.Message{
+		Type:      "user_invited",
+		ToService: messaging.EmailService,
+		Data: map[string]interface{}{
+			"email":         invite.Email,
+			"userName":      inviterName,
+			"template_name": "user_invited.html",
+			"invite_url":    s.InviteURL(code),
+			"expiresAt":     invite.ExpiresAt,
+		},
+	}
+	if err := s.rabbitMQ.PublishMessage(context.Background(), inviteMessage); err != nil {
+		log.Printf("Davet e-postası gönderilemedi: %v", err)
+	}
+}
+
+// ListInvites davetleri en yeniden eskiye döner; userID boşsa tüm kullanıcıların davetleri listelenir
+func (s *InviteService) ListInvites(userID string) ([]models.Invite, error) {
+	if userID == "" {
+		return s.repo.ListInvites(nil, inviteListLimit)
+	}
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.repo.ListInvites(&objID, inviteListLimit)
+}
+
+// RevokeInvite daveti iptal eder.
+// ownerID boş değilse yalnızca o kullanıcının daveti iptal edilebilir; yöneticiler boş geçer.
+func (s *InviteService) RevokeInvite(inviteID, ownerID string) error {
+	objID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return repository.ErrInviteNotFound
+	}
+	var owner *primitive.ObjectID
+	if ownerID != "" {
+		ownerObjID, err := primitive.ObjectIDFromHex(ownerID)
+		if err != nil {
+			return repository.ErrInviteNotFound
+		}
+		owner = &ownerObjID
+	}
+	return s.repo.Revoke(objID, owner, time.Now())
+}
@@ -8,17 +8,26 @@ import (
 )
 
 type JwtHelperService struct {
-	secretKey string
+	keyManager *KeyManager
+	issuer     string
 }
 
-func NewJwtHelperService(secretKey string) *JwtHelperService {
-	return &JwtHelperService{secretKey: secretKey}
+func NewJwtHelperService(keyManager *KeyManager, issuer string) *JwtHelperService {
+	return &JwtHelperService{keyManager: keyManager, issuer: issuer}
 }
 
-// SignToken generates a JWT token with the given payload and options
+// SignToken generates a JWT token with the given payload, signed with the newest active key
 func (j *JwtHelperService) SignToken(payload map[string]interface{}, expiration time.Duration) (string, error) {
+	key, err := j.keyManager.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := jwt.MapClaims{
-		"exp": time.Now().Add(expiration).Unix(),
+		"iss": j.issuer,
+		"iat": now.Unix(),
+		"exp": now.Add(expiration).Unix(),
 	}
 
 	// Adding the payload to claims
@@ -26,30 +35,37 @@ func (j *JwtHelperService) SignToken(payload map[string]interface{}, expiration
 		claims[key] = value
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.secretKey))
-	if err != nil {
-		return "", err
-	}
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
 
-	return tokenString, nil
+	return token.SignedString(key.Private)
 }
 
-// VerifyToken verifies the JWT token and returns the claims if valid
+// VerifyToken verifies the JWT token against the key named in its "kid" header and returns the claims if valid
 func (j *JwtHelperService) VerifyToken(tokenString string) (map[string]interface{}, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate the token signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.keyManager.Key(kid)
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+
+		// Validate the token signing method against the key's algorithm
+		if token.Method.Alg() != key.method().Alg() {
 			return nil, errors.New("invalid signing method")
 		}
-		return []byte(j.secretKey), nil
+		return key.Private.Public(), nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if iss, _ := claims["iss"].(string); iss != j.issuer {
+		return nil, errors.New("invalid issuer")
 	}
-	return nil, errors.New("invalid token")
+	return claims, nil
 }
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+)
+
+// SigningKey kid ile tanımlanan bir imzalama anahtarıdır
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	CreatedAt time.Time
+	RetiredAt *time.Time
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// JWK JSON Web Key (RFC 7517) gösterimi
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet /.well-known/jwks.json yanıtı
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyManager JWT imzalama anahtarlarını yükler, döndürür ve yayınlar.
+// En yeni anahtar imzalamada kullanılır; eski anahtarlar imzaladıkları tokenlerin
+// süresi dolana kadar doğrulama için tutulur.
+type KeyManager struct {
+	mu     sync.RWMutex
+	keys   []*SigningKey // en yeni başta
+	config config.JWTConfig
+}
+
+// NewKeyManager anahtarları dizinden veya ortam değişkeninden yükler.
+// Hiç anahtar verilmemişse geçici bir anahtar üretir.
+func NewKeyManager(cfg config.JWTConfig) (*KeyManager, error) {
+	if cfg.Algorithm != AlgRS256 && cfg.Algorithm != AlgEdDSA {
+		return nil, fmt.Errorf("desteklenmeyen JWT algoritması: %s", cfg.Algorithm)
+	}
+	if cfg.MaxActiveKeys < 1 {
+		cfg.MaxActiveKeys = 1
+	}
+
+	// Süreç içinde üretilen anahtarlar örnekler arasında paylaşılmaz; bir örneğin imzaladığı token diğerinin
+	// JWKS'inde bulunmaz. Birden fazla örnekte anahtarlar dizinden veya ortam değişkeninden gelmelidir.
+	if cfg.Instances > 1 && cfg.KeysDir == "" {
+		if cfg.PrivateKeyPEM == "" {
+			return nil, fmt.Errorf("%d örnek için JWT_KEYS_DIR veya JWT_PRIVATE_KEY ile ortak anahtar verilmeli", cfg.Instances)
+		}
+		if cfg.RotationInterval > 0 {
+			log.Println("JWT_PRIVATE_KEY birden fazla örnekle kullanılıyor, otomatik anahtar döndürme kapatıldı")
+			cfg.RotationInterval = 0
+		}
+	}
+
+	m := &KeyManager{config: cfg}
+
+	switch {
+	case cfg.KeysDir != "":
+		if err := m.loadKeysDir(); err != nil {
+			return nil, err
+		}
+	case cfg.PrivateKeyPEM != "":
+		key, err := parsePrivateKey([]byte(cfg.PrivateKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY okunamadı: %w", err)
+		}
+		m.keys = []*SigningKey{key}
+	}
+
+	if len(m.keys) == 0 {
+		// Birden fazla örnek çalışıyorsa anahtarlar dosyadan verilmeli, aksi halde her örnek farklı anahtar üretir
+		log.Println("JWT anahtarı yapılandırılmamış, geçici anahtar üretiliyor")
+		if err := m.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// loadKeysDir dizindeki *.pem dosyalarını yükler; dosya adı kid olarak kullanılır.
+// Dizinden kaldırılan anahtarlar hemen silinmez, emekliye ayrılır.
+func (m *KeyManager) loadKeysDir() error {
+	paths, err := filepath.Glob(filepath.Join(m.config.KeysDir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]*SigningKey, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("anahtar dosyası okunamadı %s: %w", path, err)
+		}
+		key, err := parsePrivateKey(data)
+		if err != nil {
+			return fmt.Errorf("anahtar dosyası çözümlenemedi %s: %w", path, err)
+		}
+		if info, err := os.Stat(path); err == nil {
+			key.CreatedAt = info.ModTime()
+		}
+		key.ID = strings.TrimSuffix(filepath.Base(path), ".pem")
+		loaded[key.ID] = key
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, existing := range m.keys {
+		if _, ok := loaded[existing.ID]; !ok {
+			if existing.RetiredAt == nil {
+				existing.RetiredAt = &now
+			}
+			loaded[existing.ID] = existing
+		}
+	}
+
+	keys := make([]*SigningKey, 0, len(loaded))
+	for _, key := range loaded {
+		keys = append(keys, key)
+	}
+	m.keys = keys
+	m.normalizeLocked(now)
+	return nil
+}
+
+// Rotate yeni bir anahtar ekler (dizin yapılandırılmışsa dizini yeniden okur)
+// ve aktif anahtar sayısını aşan eski anahtarları emekliye ayırır
+func (m *KeyManager) Rotate() error {
+	if m.config.KeysDir != "" {
+		return m.loadKeysDir()
+	}
+
+	key, err := generateSigningKey(m.config.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = append([]*SigningKey{key}, m.keys...)
+	m.normalizeLocked(time.Now())
+	log.Printf("JWT imzalama anahtarı döndürüldü, yeni kid: %s", key.ID)
+	return nil
+}
+
+// normalizeLocked anahtarları sıralar, fazlalıkları emekliye ayırır ve süresi geçenleri siler
+func (m *KeyManager) normalizeLocked(now time.Time) {
+	sort.SliceStable(m.keys, func(i, j int) bool {
+		return m.keys[i].CreatedAt.After(m.keys[j].CreatedAt)
+	})
+
+	active := 0
+	kept := m.keys[:0]
+	for _, key := range m.keys {
+		if key.RetiredAt == nil {
+			active++
+			if active > m.config.MaxActiveKeys {
+				retiredAt := now
+				key.RetiredAt = &retiredAt
+			}
+		}
+		if key.RetiredAt != nil && now.Sub(*key.RetiredAt) > m.config.MaxTokenTTL {
+			continue
+		}
+		kept = append(kept, key)
+	}
+	m.keys = kept
+}
+
+// StartRotation anahtarları yapılandırılan aralıkla döndürür
+func (m *KeyManager) StartRotation(stop <-chan struct{}) {
+	if m.config.RotationInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.RotationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.Rotate(); err != nil {
+				log.Printf("JWT anahtarı döndürülemedi: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// SigningKey imzalamada kullanılacak en yeni aktif anahtarı döner
+func (m *KeyManager) SigningKey() (*SigningKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, key := range m.keys {
+		if key.RetiredAt == nil {
+			return key, nil
+		}
+	}
+	return nil, errors.New("aktif JWT imzalama anahtarı yok")
+}
+
+// Key doğrulama için kid'e karşılık gelen anahtarı döner (emekliye ayrılmış olanlar dahil)
+func (m *KeyManager) Key(kid string) (*SigningKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, key := range m.keys {
+		if key.ID == kid {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// JWKS doğrulamada kullanılabilecek tüm açık anahtarları döner
+func (m *KeyManager) JWKS() JWKSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(m.keys))}
+	for _, key := range m.keys {
+		set.Keys = append(set.Keys, publicJWK(key))
+	}
+	return set
+}
+
+func publicJWK(key *SigningKey) JWK {
+	jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Algorithm}
+	switch pub := key.Private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+// thumbprint RFC 7638 JWK thumbprint değerini kid olarak üretir
+func thumbprint(key *SigningKey) string {
+	jwk := publicJWK(key)
+	var members map[string]string
+	if jwk.Kty == "RSA" {
+		members = map[string]string{"e": jwk.E, "kty": jwk.Kty, "n": jwk.N}
+	} else {
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X}
+	}
+	// encoding/json map anahtarlarını sıralı yazar, RFC 7638'in istediği de budur
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func generateSigningKey(algorithm string) (*SigningKey, error) {
+	key := &SigningKey{Algorithm: algorithm, CreatedAt: time.Now()}
+	switch algorithm {
+	case AlgEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key.Private = private
+	default:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		key.Private = private
+	}
+	key.ID = thumbprint(key)
+	return key, nil
+}
+
+// parsePrivateKey PKCS#8 (RSA/Ed25519) veya PKCS#1 (RSA) PEM anahtarını çözümler
+func parsePrivateKey(data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("PEM bloğu bulunamadı")
+	}
+
+	var private interface{}
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{CreatedAt: time.Now()}
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = AlgRS256
+		key.Private = k
+	case ed25519.PrivateKey:
+		key.Algorithm = AlgEdDSA
+		key.Private = k
+	default:
+		return nil, errors.New("yalnızca RSA ve Ed25519 anahtarları desteklenir")
+	}
+	key.ID = thumbprint(key)
+	return key, nil
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-redis/redis"
+)
+
+// Korunan işlemler; her biri ayrı bir deneme penceresi kullanır
+const (
+	GuardSignIn        = "signin"
+	GuardActivation    = "activation"
+	GuardResetPassword = "reset_password"
+	GuardMagicLogin    = "magic_login"
+)
+
+const (
+	bruteForceKeyPrefix  = "bruteforce:"
+	accountLockKeyPrefix = "account_lock:"
+	attemptDelayPrefix   = "attempt_delay:"
+)
+
+// AttemptLimitError deneme sınırı aşıldığında döner; RetryAfter istemciye Retry-After başlığı olarak iletilir
+type AttemptLimitError struct {
+	Message    string
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *AttemptLimitError) Error() string {
+	return e.Message
+}
+
+// LoginGuard hesap ve IP bazlı kayan pencere sayaçlarıyla kaba kuvvet denemelerini sınırlar
+type LoginGuard struct {
+	repo *redisrepo.RedisRepository
+	cfg  config.BruteForceConfig
+}
+
+func NewLoginGuard(repo *redisrepo.RedisRepository, cfg config.BruteForceConfig) *LoginGuard {
+	return &LoginGuard{repo: repo, cfg: cfg}
+}
+
+func normalizeAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+func accountAttemptsKey(scope, account string) string {
+	return bruteForceKeyPrefix + scope + ":account:" + account
+}
+
+func ipAttemptsKey(scope, ip string) string {
+	return bruteForceKeyPrefix + scope + ":ip:" + ip
+}
+
+func accountLockKey(account string) string {
+	return accountLockKeyPrefix + account
+}
+
+func attemptDelayKey(scope, account string) string {
+	return attemptDelayPrefix + scope + ":" + account
+}
+
+// GuardAttempt Check'in pencerelerde ayırdığı deneme hakkıdır. İşlemin sonucu RegisterSuccess,
+// RegisterFailure veya RegisterIPFailure ile bildirilir.
+type GuardAttempt struct {
+	scope         string
+	account       string
+	ip            string
+	accountMember string
+	ipMember      string
+	failures      int64 // Ayırma anında hesabın penceredeki deneme sayısı (bu deneme dahil)
+}
+
+// Check işlemden önce çağrılır; hesap kilitliyse, bekleme süresi dolmadıysa veya sınır aşıldıysa hata döner.
+// Deneme, sonucu beklenmeden hesap ve IP pencerelerine atomik olarak eklenir; böylece aynı anda gelen
+// istekler de sınırı aşamaz. Redis hatalarında istek engellenmez, yalnızca loglanır.
+func (g *LoginGuard) Check(scope, account, ip string) (*GuardAttempt, error) {
+	attempt := &GuardAttempt{scope: scope, account: normalizeAccount(account), ip: ip}
+
+	if attempt.account != "" {
+		if remaining, err := g.LockRemaining(attempt.account); err != nil {
+			log.Printf("Hesap kilidi kontrol edilemedi: %v", err)
+		} else if remaining > 0 {
+			return nil, &AttemptLimitError{
+				Message:    "Çok fazla hatalı deneme nedeniyle hesap geçici olarak kilitlendi",
+				RetryAfter: remaining,
+				Locked:     true,
+			}
+		}
+
+		if remaining, err := g.repo.Client.TTL(attemptDelayKey(scope, attempt.account)).Result(); err != nil {
+			log.Printf("Deneme gecikmesi kontrol edilemedi: %v", err)
+		} else if remaining > 0 {
+			return nil, &AttemptLimitError{
+				Message:    "Lütfen tekrar denemeden önce bekleyin",
+				RetryAfter: remaining,
+			}
+		}
+	}
+
+	if ip != "" {
+		result, member, err := g.repo.SlidingWindowReserve(ipAttemptsKey(scope, ip), g.cfg.MaxIPFailures, g.cfg.FailureWindow)
+		if err != nil {
+			log.Printf("IP deneme sayısı alınamadı: %v", err)
+		} else if !result.Allowed {
+			return nil, &AttemptLimitError{
+				Message:    "Bu IP adresinden çok fazla hatalı deneme yapıldı",
+				RetryAfter: result.RetryAfter,
+			}
+		}
+		attempt.ipMember = member
+	}
+
+	if attempt.account != "" {
+		result, member, err := g.repo.SlidingWindowReserve(accountAttemptsKey(scope, attempt.account), g.cfg.MaxAccountFailures, g.cfg.FailureWindow)
+		if err != nil {
+			log.Printf("Hesap deneme sayısı alınamadı: %v", err)
+		} else if !result.Allowed {
+			g.release(ipAttemptsKey(scope, ip), attempt.ipMember)
+			return nil, &AttemptLimitError{
+				Message:    "Bu hesap için çok fazla deneme yapıldı, lütfen daha sonra tekrar deneyin",
+				RetryAfter: result.RetryAfter,
+			}
+		} else {
+			attempt.accountMember = member
+			attempt.failures = result.Limit - result.Remaining
+		}
+	}
+	return attempt, nil
+}
+
+// release ayrılmış bir denemeyi pencereden çıkarır
+func (g *LoginGuard) release(key, member string) {
+	if err := g.repo.SlidingWindowRelease(key, member); err != nil {
+		log.Printf("Deneme sayacı güncellenemedi: %v", err)
+	}
+}
+
+// RegisterFailure Check'te ayrılan denemeyi başarısız olarak bırakır, gerekiyorsa bekleme süresi koyar
+// veya hesabı kilitler. Hesap bu deneme ile kilitlendiyse true döner.
+func (g *LoginGuard) RegisterFailure(attempt *GuardAttempt) (bool, error) {
+	if attempt.account == "" || attempt.accountMember == "" {
+		return false, nil
+	}
+	scope, account := attempt.scope, attempt.account
+
+	if attempt.failures >= int64(g.cfg.MaxAccountFailures) {
+		if err := g.repo.Client.Set(accountLockKey(account), scope, g.cfg.LockoutDuration).Err(); err != nil {
+			return false, err
+		}
+		// Kilit süresi dolduğunda sayaç sıfırdan başlar
+		return true, g.repo.Client.Del(accountAttemptsKey(scope, account), attemptDelayKey(scope, account)).Err()
+	}
+
+	if delay := g.delayFor(attempt.failures); delay > 0 {
+		if err := g.repo.Client.Set(attemptDelayKey(scope, account), attempt.failures, delay).Err(); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// RegisterIPFailure başarısız denemeyi yalnızca IP penceresinde bırakır; hesap penceresindeki ayırma geri alınır.
+// Hatalı kodları kendi içinde sınırlayan işlemler hesabı kilitlememek için kullanır.
+func (g *LoginGuard) RegisterIPFailure(attempt *GuardAttempt) {
+	g.release(accountAttemptsKey(attempt.scope, attempt.account), attempt.accountMember)
+}
+
+// delayFor eşik aşıldıktan sonraki her hatada bekleme süresini ikiye katlar
+func (g *LoginGuard) delayFor(failures int64) time.Duration {
+	over := failures - int64(g.cfg.DelayAfterFailures)
+	if over < 0 || g.cfg.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := g.cfg.BaseDelay
+	for i := int64(0); i < over && delay < g.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if g.cfg.MaxDelay > 0 && delay > g.cfg.MaxDelay {
+		delay = g.cfg.MaxDelay
+	}
+	return delay
+}
+
+// Cancel sonucu deneme sayılmayan işlemlerde (ör. istek kuralları reddedildiğinde) ayrılan hakkı geri verir
+func (g *LoginGuard) Cancel(attempt *GuardAttempt) {
+	g.release(ipAttemptsKey(attempt.scope, attempt.ip), attempt.ipMember)
+	g.release(accountAttemptsKey(attempt.scope, attempt.account), attempt.accountMember)
+}
+
+// RegisterSuccess başarılı işlemden sonra denemeyi IP penceresinden çıkarır ve hesabın sayaçlarını temizler
+func (g *LoginGuard) RegisterSuccess(attempt *GuardAttempt) {
+	g.release(ipAttemptsKey(attempt.scope, attempt.ip), attempt.ipMember)
+	if attempt.account == "" {
+		return
+	}
+	if err := g.repo.Client.Del(accountAttemptsKey(attempt.scope, attempt.account), attemptDelayKey(attempt.scope, attempt.account)).Err(); err != nil {
+		log.Printf("Deneme sayacı temizlenemedi: %v", err)
+	}
+}
+
+// LockRemaining hesabın kilidinin açılmasına kalan süreyi döner; kilitli değilse 0 döner
+func (g *LoginGuard) LockRemaining(account string) (time.Duration, error) {
+	remaining, err := g.repo.Client.TTL(accountLockKey(normalizeAccount(account))).Result()
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+// Unlock hesabın kilidini ve tüm işlemlerdeki deneme sayaçlarını kaldırır
+func (g *LoginGuard) Unlock(account string) error {
+	account = normalizeAccount(account)
+	if account == "" {
+		return fmt.Errorf("hesap bilgisi gerekli")
+	}
+
+	keys := []string{accountLockKey(account)}
+	for _, scope := range []string{GuardSignIn, GuardActivation, GuardResetPassword, GuardMagicLogin} {
+		keys = append(keys, accountAttemptsKey(scope, account), attemptDelayKey(scope, account))
+	}
+	return g.repo.Client.Del(keys...).Err()
+}
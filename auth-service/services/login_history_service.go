@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-redis/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// loginAlertTTL "bu ben değilim" bağlantısının geçerlilik süresidir; oturum bu sürede zaten
+	// kapanmış olabilir, bağlantı yine de girişi bildirilmiş olarak işaretler
+	loginAlertTTL = 7 * 24 * time.Hour
+
+	DefaultLoginHistoryLimit = 20
+	MaxLoginHistoryLimit     = 100
+)
+
+var ErrLoginAlertInvalid = errors.New("bağlantı geçersiz veya daha önce kullanılmış")
+
+// LoginAttempt başarılı bir girişin kaydedilecek bilgileridir
+type LoginAttempt struct {
+	UserID    string
+	Email     string
+	Username  string
+	SessionID string
+	DeviceID  string
+	UserAgent string
+	IP        string
+	Method    string
+}
+
+// LoginHistoryService başarılı girişleri cihaz parmak iziyle kaydeder ve kullanıcının daha önce
+// görülmemiş bir cihazdan giriş yapması durumunda "new_device_login" uyarısı gönderir
+type LoginHistoryService struct {
+	repo        *repository.LoginHistoryRepository
+	sessionRepo *redisrepo.RedisRepository
+	rabbitMQ    *messaging.RabbitMQ
+	config      config.LoginHistoryConfig
+}
+
+func NewLoginHistoryService(repo *repository.LoginHistoryRepository, sessionRepo *redisrepo.RedisRepository, rabbitMQ *messaging.RabbitMQ, cfg config.LoginHistoryConfig) *LoginHistoryService {
+	return &LoginHistoryService{
+		repo:        repo,
+		sessionRepo: sessionRepo,
+		rabbitMQ:    rabbitMQ,
+		config:      cfg,
+	}
+}
+
+// NewDeviceID tarayıcıyı tanımlayan device_id çerezi için rastgele bir değer üretir
+func NewDeviceID() (string, error) {
+	return generateOpaqueToken()
+}
+
+// DeviceCookieTTL device_id çerezinin süresini döner
+func (s *LoginHistoryService) DeviceCookieTTL() time.Duration {
+	return s.config.DeviceCookieTTL
+}
+
+// DeviceFingerprint device_id çerezi, tarayıcı bilgisi ve IP ağından cihazın parmak izini üretir.
+// Aynı ağdaki adres değişikliklerinin (DHCP, IPv6 gizlilik adresleri) yeni cihaz sayılmaması için
+// IPv4 adreslerinin /24, IPv6 adreslerinin /64 ağı kullanılır.
+func DeviceFingerprint(deviceID, userAgent, ip string) string {
+	sum := sha256.Sum256([]byte(deviceID + "\n" + userAgent + "\n" + ipNetwork(ip)))
+	return hex.EncodeToString(sum[:])
+}
+
+func ipNetwork(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(64, 128)).String()
+}
+
+// RecordLogin girişi kaydeder. Cihaz ilk kez görüldüyse ve kullanıcının başka bilinen bir cihazı varsa
+// kullanıcıya uyarı e-postası gönderilir; hesabın ilk girişi uyarı oluşturmaz.
+func (s *LoginHistoryService) RecordLogin(attempt LoginAttempt) (*models.LoginRecord, error) {
+	userID, err := primitive.ObjectIDFromHex(attempt.UserID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now()
+	fingerprint := DeviceFingerprint(attempt.DeviceID, attempt.UserAgent, attempt.IP)
+	newDevice, err := s.repo.TouchDevice(userID, fingerprint, now, now.Add(s.config.DeviceRetention))
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.LoginRecord{
+		UserID:      userID,
+		Fingerprint: fingerprint,
+		Method:      attempt.Method,
+		Device:      attempt.UserAgent,
+		IP:          attempt.IP,
+		NewDevice:   newDevice,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.config.HistoryRetention),
+	}
+	if err := s.repo.Create(record); err != nil {
+		return nil, err
+	}
+
+	if newDevice && s.config.AlertsEnabled {
+		known, err := s.repo.HasOtherDevices(userID, fingerprint)
+		if err != nil {
+			log.Printf("Kullanıcının bilinen cihazları okunamadı: %v", err)
+		} else if known {
+			s.alert(record, attempt)
+		}
+	}
+	return record, nil
+}
+
+// alert yeni cihazdan yapılan girişi, oturumu kapatan tek kullanımlık bir bağlantıyla birlikte
+// email-service'e "new_device_login" mesajı olarak gönderir
+func (s *LoginHistoryService) alert(record *models.LoginRecord, attempt LoginAttempt) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		log.Printf("Giriş uyarısı tokeni oluşturulamadı: %v", err)
+		return
+	}
+	err = s.sessionRepo.SetLoginAlert(hashCode(token), map[string]string{
+		"user_id":    attempt.UserID,
+		"session_id": attempt.SessionID,
+		"login_id":   record.ID.Hex(),
+	}, loginAlertTTL)
+	if err != nil {
+		log.Printf("Giriş uyarısı kaydedilemedi: %v", err)
+		return
+	}
+
+	alertMessage := messaging.Message{
+		Type:      "new_device_login",
+		ToService: messaging.EmailService,
+		Data: map[string]interface{}{
+			"user_id":       attempt.UserID,
+			"email":         attempt.Email,
+			"userName":      attempt.Username,
+			"template_name": "new_device_login.html",
+			"device":        record.Device,
+			"ip":            record.IP,
+			"method":        record.Method,
+			"loginAt":       record.CreatedAt,
+			"revoke_url":    s.config.AlertBaseURL + "?token=" + url.QueryEscape(token),
+		},
+	}
+	if err := s.rabbitMQ.PublishMessage(context.Background(), alertMessage); err != nil {
+		log.Printf("Yeni cihaz uyarısı gönderilemedi: %v", err)
+	}
+}
+
+// ListLogins kullanıcının son girişlerini döner
+func (s *LoginHistoryService) ListLogins(userID string, limit int) ([]models.LoginRecord, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.repo.ListUserLogins(objID, int64(limit))
+}
+
+// RevokeFromAlert uyarı e-postasındaki token ile girişte açılan oturumu kapatır ve girişi bildirilmiş
+// olarak işaretler; oturumu kapatılan kullanıcının ID'sini döner
+func (s *LoginHistoryService) RevokeFromAlert(token string) (string, error) {
+	data, err := s.sessionRepo.TakeLoginAlert(hashCode(token))
+	if err != nil {
+		if err == redis.Nil {
+			return "", ErrLoginAlertInvalid
+		}
+		return "", err
+	}
+
+	userID := data["user_id"]
+	if err := s.sessionRepo.RevokeSession(userID, data["session_id"]); err != nil && !errors.Is(err, redisrepo.ErrSessionNotFound) {
+		return "", err
+	}
+	if loginID, err := primitive.ObjectIDFromHex(data["login_id"]); err == nil {
+		if err := s.repo.MarkReported(loginID, time.Now()); err != nil {
+			log.Printf("Giriş bildirilmiş olarak işaretlenemedi: %v", err)
+		}
+	}
+	return userID, nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrMagicLoginTooSoon = errors.New("yeni giriş bağlantısı istemeden önce lütfen biraz bekleyin")
+	ErrMagicLoginInvalid = errors.New("giriş bağlantısı veya kodu geçersiz ya da süresi dolmuş")
+)
+
+// MagicLoginEmail kullanıcıya gönderilecek giriş bağlantısını ve kodunu taşır
+type MagicLoginEmail struct {
+	Email    string
+	Username string
+	Link     string
+	Code     string
+}
+
+// MagicLinkService e-posta ile gönderilen tek kullanımlık bağlantı veya kodla şifresiz girişi yönetir
+type MagicLinkService struct {
+	collection *mongo.Collection
+	repo       *repository.MagicLoginRepository
+	config     config.MagicLinkConfig
+}
+
+func NewMagicLinkService(cfg config.MagicLinkConfig) *MagicLinkService {
+	collection, _ := database.GetCollection("authDB", "users")
+	return &MagicLinkService{
+		collection: collection,
+		repo:       repository.NewMagicLoginRepository(database.RedisClient),
+		config:     cfg,
+	}
+}
+
+// RequestLogin isteği başlatan tarayıcıya bağlanacak tokeni döner.
+// E-posta kayıtlı değilse de token döner, böylece hesaplar tespit edilemez; bu durumda gönderilecek e-posta nil olur.
+func (s *MagicLinkService) RequestLogin(email string) (*MagicLoginEmail, string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, "", ErrMagicLoginInvalid
+	}
+
+	// Bekleme süresi kayıtlı olmayan adresler için de uygulanır, böylece 429 yanıtından hesap tespit edilemez
+	acquired, err := s.repo.AcquireCooldown(strings.ToLower(email), s.config.ResendCooldown)
+	if err != nil {
+		return nil, "", err
+	}
+	if !acquired {
+		return nil, "", ErrMagicLoginTooSoon
+	}
+
+	browserToken, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = s.collection.FindOne(ctx, bson.M{"email": email, "isDeleted": bson.M{"$ne": true}}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, browserToken, nil
+		}
+		return nil, "", err
+	}
+
+	linkToken, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	code, err := GenerateActivationCode()
+	if err != nil {
+		return nil, "", err
+	}
+
+	request := &models.MagicLoginRequest{
+		UserID:    user.ID.Hex(),
+		Email:     user.Email,
+		LinkHash:  hashCode(linkToken),
+		CodeHash:  hashCode(code),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Save(hashCode(browserToken), request, s.config.TokenTTL); err != nil {
+		return nil, "", fmt.Errorf("giriş isteği kaydedilemedi: %v", err)
+	}
+
+	loginURL, err := url.Parse(s.config.BaseURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("giriş bağlantısı adresi geçersiz: %v", err)
+	}
+	query := loginURL.Query()
+	query.Set("token", linkToken)
+	loginURL.RawQuery = query.Encode()
+
+	return &MagicLoginEmail{
+		Email:    user.Email,
+		Username: user.Username,
+		Link:     loginURL.String(),
+		Code:     code,
+	}, browserToken, nil
+}
+
+// PendingEmail tarayıcıya bağlı bekleyen isteğin e-posta adresini döner; deneme sınırlarında hesap anahtarı olarak kullanılır
+func (s *MagicLinkService) PendingEmail(browserToken string) (string, error) {
+	request, err := s.repo.Get(hashCode(browserToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrMagicLoginNotFound) {
+			return "", ErrMagicLoginInvalid
+		}
+		return "", err
+	}
+	return request.Email, nil
+}
+
+// Redeem bağlantı tokenini veya kodu tarayıcı tokeniyle birlikte doğrular ve isteği tek kullanımlık olarak tüketir
+func (s *MagicLinkService) Redeem(browserToken, linkToken, code string) (*models.User, error) {
+	if browserToken == "" || (linkToken == "" && code == "") {
+		return nil, ErrMagicLoginInvalid
+	}
+
+	browserHash := hashCode(browserToken)
+	request, err := s.repo.Get(browserHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrMagicLoginNotFound) {
+			return nil, ErrMagicLoginInvalid
+		}
+		return nil, err
+	}
+
+	// Deneme önce sayılır; eşzamanlı tahminler sayacı okuyup sınırı birlikte aşamaz
+	attempts, err := s.repo.IncrementAttempts(browserHash)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > int64(s.config.MaxAttempts) {
+		s.repo.Delete(browserHash)
+		return nil, ErrMagicLoginInvalid
+	}
+
+	expected, given := request.CodeHash, hashCode(strings.TrimSpace(code))
+	if linkToken != "" {
+		expected, given = request.LinkHash, hashCode(linkToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(given)) != 1 {
+		if attempts >= int64(s.config.MaxAttempts) {
+			s.repo.Delete(browserHash)
+		}
+		return nil, ErrMagicLoginInvalid
+	}
+
+	// İstek tek kullanımlıktır; eşzamanlı iki istekten yalnızca biri kaydı silebilir
+	deleted, err := s.repo.Delete(browserHash)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, ErrMagicLoginInvalid
+	}
+
+	objID, err := primitive.ObjectIDFromHex(request.UserID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user models.User
+	if err := s.collection.FindOne(ctx, bson.M{"_id": objID, "isDeleted": bson.M{"$ne": true}}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
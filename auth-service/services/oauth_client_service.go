@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrOAuthClientNotFound = errors.New("OAuth istemcisi bulunamadı")
+
+// OAuthClientService authDB'de kayıtlı OAuth2 istemcilerini yönetir ve doğrular
+type OAuthClientService struct {
+	collection *mongo.Collection
+}
+
+func NewOAuthClientService() *OAuthClientService {
+	collection, _ := database.GetCollection("authDB", "oauth_clients")
+	return &OAuthClientService{collection: collection}
+}
+
+// CreateClient yeni bir istemci kaydeder; gizli anahtar yalnızca bu çağrıda düz metin olarak döner
+func (s *OAuthClientService) CreateClient(input *dto.CreateOAuthClientDto, createdBy string) (*models.OAuthClient, string, error) {
+	for _, redirectURI := range input.RedirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Fragment != "" {
+			return nil, "", oauthError("invalid_redirect_uri", fmt.Sprintf("geçersiz yönlendirme adresi: %s", redirectURI))
+		}
+	}
+	usesCode := false
+	for _, grantType := range input.GrantTypes {
+		switch grantType {
+		case models.GrantAuthorizationCode:
+			usesCode = true
+		case models.GrantRefreshToken:
+		case models.GrantClientCredentials:
+			if input.Public {
+				return nil, "", oauthError("invalid_client_metadata", "public istemciler client_credentials kullanamaz")
+			}
+		default:
+			return nil, "", oauthError("invalid_client_metadata", fmt.Sprintf("desteklenmeyen grant tipi: %s", grantType))
+		}
+	}
+
+	if usesCode && len(input.RedirectURIs) == 0 {
+		return nil, "", oauthError("invalid_redirect_uri", "authorization_code akışı için en az bir yönlendirme adresi gereklidir")
+	}
+
+	clientID, err := newTokenID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	client := &models.OAuthClient{
+		ClientID:     clientID,
+		Name:         input.Name,
+		RedirectURIs: input.RedirectURIs,
+		GrantTypes:   input.GrantTypes,
+		Scopes:       input.Scopes,
+		Public:       input.Public,
+		FirstParty:   input.FirstParty,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if objID, err := primitive.ObjectIDFromHex(createdBy); err == nil {
+		client.CreatedBy = objID
+	}
+
+	var secret string
+	if !client.Public {
+		if secret, err = generateOpaqueToken(); err != nil {
+			return nil, "", err
+		}
+		client.ClientSecretHash = hashCode(secret)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.InsertOne(ctx, client)
+	if err != nil {
+		return nil, "", fmt.Errorf("istemci kaydedilemedi: %v", err)
+	}
+	client.ID = result.InsertedID.(primitive.ObjectID)
+	return client, secret, nil
+}
+
+// ListClients kayıtlı tüm istemcileri döner
+func (s *OAuthClientService) ListClients() ([]models.OAuthClient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	clients := []models.OAuthClient{}
+	if err := cursor.All(ctx, &clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// DeleteClient istemciyi siler; istemcinin yeni token alması hemen engellenir
+func (s *OAuthClientService) DeleteClient(clientID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"clientId": clientID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrOAuthClientNotFound
+	}
+	return nil
+}
+
+// FindClient istemciyi client_id ile bulur
+func (s *OAuthClientService) FindClient(clientID string) (*models.OAuthClient, error) {
+	if clientID == "" {
+		return nil, oauthError("invalid_request", "client_id eksik")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var client models.OAuthClient
+	if err := s.collection.FindOne(ctx, bson.M{"clientId": clientID}).Decode(&client); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, oauthError("invalid_client", "bilinmeyen istemci")
+		}
+		return nil, oauthServerError(err)
+	}
+	return &client, nil
+}
+
+// AuthenticateClient token uç noktalarında istemciyi doğrular.
+// Public istemciler yalnızca client_id gönderir; diğerleri gizli anahtarını da göndermek zorundadır.
+func (s *OAuthClientService) AuthenticateClient(clientID, clientSecret string) (*models.OAuthClient, error) {
+	client, err := s.FindClient(clientID)
+	if err != nil {
+		var oauthErr *OAuthError
+		if errors.As(err, &oauthErr) && oauthErr.Code == "invalid_request" {
+			return nil, oauthError("invalid_client", "istemci kimlik bilgileri eksik")
+		}
+		return nil, err
+	}
+
+	if client.Public {
+		if clientSecret != "" {
+			return nil, oauthError("invalid_client", "public istemci gizli anahtar kullanamaz")
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(hashCode(clientSecret)), []byte(client.ClientSecretHash)) != 1 {
+		return nil, oauthError("invalid_client", "istemci kimlik doğrulaması başarısız")
+	}
+	return client, nil
+}
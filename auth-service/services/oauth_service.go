@@ -0,0 +1,722 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	tokenUseAccess = "access"
+	tokenUseID     = "id"
+
+	PKCEMethodS256 = "S256"
+)
+
+// OIDC keşif belgesinde yayınlanan ve userinfo tarafından anlaşılan kapsamlar
+var SupportedScopes = []string{models.ScopeOpenID, models.ScopeProfile, models.ScopeEmail}
+
+// OAuthError RFC 6749'daki hata kodlarını taşır; istemciye "error" ve "error_description" olarak döner
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// Status hatanın HTTP durum kodunu döner
+func (e *OAuthError) Status() int {
+	switch e.Code {
+	case "invalid_client":
+		return http.StatusUnauthorized
+	case "server_error":
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+func oauthServerError(err error) *OAuthError {
+	return &OAuthError{Code: "server_error", Description: err.Error()}
+}
+
+// OAuthService yerleşik OAuth2 / OpenID Connect sunucusunun yetkilendirme, token ve onay işlemlerini yürütür
+type OAuthService struct {
+	userCollection    *mongo.Collection
+	consentCollection *mongo.Collection
+	refreshCollection *mongo.Collection
+	clientService     *OAuthClientService
+	oauthRepo         *repository.OAuthRepository
+	jwtHelper         *JwtHelperService
+	issuer            string
+	config            config.OAuthConfig
+}
+
+func NewOAuthService(clientService *OAuthClientService, jwtHelper *JwtHelperService, cfg config.Config) *OAuthService {
+	db := database.MongoClient.Database("authDB")
+	return &OAuthService{
+		userCollection:    db.Collection("users"),
+		consentCollection: db.Collection("oauth_consents"),
+		refreshCollection: db.Collection("oauth_refresh_tokens"),
+		clientService:     clientService,
+		oauthRepo:         repository.NewOAuthRepository(database.RedisClient),
+		jwtHelper:         jwtHelper,
+		issuer:            cfg.JWT.Issuer,
+		config:            cfg.OAuth,
+	}
+}
+
+// Issuer tokenlerin "iss" değerini döner
+func (s *OAuthService) Issuer() string {
+	return s.issuer
+}
+
+func parseScopes(scope string) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, value := range strings.Fields(scope) {
+		if !seen[value] {
+			seen[value] = true
+			scopes = append(scopes, value)
+		}
+	}
+	return scopes
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, value := range scopes {
+		if value == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// scopesSubset requested içindeki her kapsamın allowed içinde olup olmadığını döner
+func scopesSubset(requested, allowed []string) bool {
+	for _, scope := range requested {
+		if !hasScope(allowed, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateAuthorizeRequest /oauth/authorize parametrelerini doğrular.
+// İstemci veya yönlendirme adresi geçersizse istek nil döner ve hata kullanıcıya gösterilmelidir;
+// diğer hatalarda istek döner ve hata istemcinin yönlendirme adresine iletilmelidir.
+func (s *OAuthService) ValidateAuthorizeRequest(input *dto.OAuthAuthorizeDto) (*models.OAuthClient, *models.OAuthAuthorizationRequest, error) {
+	client, err := s.clientService.FindClient(input.ClientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if input.RedirectURI == "" && len(client.RedirectURIs) == 1 {
+		input.RedirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(input.RedirectURI) {
+		return nil, nil, oauthError("invalid_request", "redirect_uri istemci için kayıtlı değil")
+	}
+
+	request := &models.OAuthAuthorizationRequest{
+		ClientID:            client.ClientID,
+		RedirectURI:         input.RedirectURI,
+		Scopes:              parseScopes(input.Scope),
+		State:               input.State,
+		Nonce:               input.Nonce,
+		CodeChallenge:       input.CodeChallenge,
+		CodeChallengeMethod: input.CodeChallengeMethod,
+	}
+
+	if input.ResponseType != "code" {
+		return client, request, oauthError("unsupported_response_type", "yalnızca response_type=code desteklenir")
+	}
+	if !client.AllowsGrant(models.GrantAuthorizationCode) {
+		return client, request, oauthError("unauthorized_client", "istemci authorization_code akışını kullanamaz")
+	}
+	if len(request.Scopes) == 0 {
+		return client, request, oauthError("invalid_scope", "en az bir kapsam istenmelidir")
+	}
+	if !scopesSubset(request.Scopes, client.Scopes) {
+		return client, request, oauthError("invalid_scope", "istenen kapsamlar istemciye tanımlı değil")
+	}
+
+	// Gizli anahtarı olmayan istemciler için PKCE zorunludur; "plain" yöntemi kabul edilmez
+	if request.CodeChallenge == "" {
+		if client.Public {
+			return client, request, oauthError("invalid_request", "public istemciler code_challenge göndermelidir")
+		}
+	} else {
+		if request.CodeChallengeMethod == "" {
+			request.CodeChallengeMethod = PKCEMethodS256
+		}
+		if request.CodeChallengeMethod != PKCEMethodS256 {
+			return client, request, oauthError("invalid_request", "yalnızca S256 code_challenge_method desteklenir")
+		}
+	}
+	return client, request, nil
+}
+
+// NeedsConsent kullanıcının istenen kapsamları istemciye daha önce onaylayıp onaylamadığını kontrol eder
+func (s *OAuthService) NeedsConsent(client *models.OAuthClient, userID string, scopes []string) (bool, error) {
+	if client.FirstParty {
+		return false, nil
+	}
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var consent models.OAuthConsent
+	err = s.consentCollection.FindOne(ctx, bson.M{"userId": objID, "clientId": client.ClientID}).Decode(&consent)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return true, nil
+		}
+		return true, err
+	}
+	return !scopesSubset(scopes, consent.Scopes), nil
+}
+
+// IssueCode kısa ömürlü, tek kullanımlık yetkilendirme kodu üretir
+func (s *OAuthService) IssueCode(request *models.OAuthAuthorizationRequest) (string, error) {
+	code, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.oauthRepo.SaveCode(hashCode(code), request, s.config.AuthorizationCodeTTL); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// CreateConsentRequest onay ekranında kullanıcının kararını bekleyecek isteği saklar
+func (s *OAuthService) CreateConsentRequest(request *models.OAuthAuthorizationRequest) (string, error) {
+	consentID, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.oauthRepo.SaveConsentRequest(consentID, request, s.config.ConsentTTL); err != nil {
+		return "", err
+	}
+	return consentID, nil
+}
+
+// GetConsentRequest onay ekranında gösterilecek isteği ve istemciyi döner
+func (s *OAuthService) GetConsentRequest(consentID, userID string) (*models.OAuthAuthorizationRequest, *models.OAuthClient, error) {
+	request, err := s.oauthRepo.GetConsentRequest(consentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Onay isteği yalnızca isteği başlatan kullanıcı tarafından cevaplanabilir
+	if request.UserID != userID {
+		return nil, nil, repository.ErrConsentRequestNotFound
+	}
+	client, err := s.clientService.FindClient(request.ClientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return request, client, nil
+}
+
+// CompleteConsent kullanıcının kararını uygular; onaylandıysa izni kaydeder ve yetkilendirme kodu döner
+func (s *OAuthService) CompleteConsent(consentID, userID string, approved bool) (*models.OAuthAuthorizationRequest, string, error) {
+	request, err := s.oauthRepo.TakeConsentRequest(consentID)
+	if err != nil {
+		return nil, "", err
+	}
+	if request.UserID != userID {
+		return nil, "", repository.ErrConsentRequestNotFound
+	}
+	if !approved {
+		return request, "", nil
+	}
+
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err = s.consentCollection.UpdateOne(ctx,
+		bson.M{"userId": objID, "clientId": request.ClientID},
+		bson.M{
+			"$addToSet":    bson.M{"scopes": bson.M{"$each": request.Scopes}},
+			"$set":         bson.M{"updatedAt": now},
+			"$setOnInsert": bson.M{"createdAt": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("onay kaydedilemedi: %v", err)
+	}
+
+	code, err := s.IssueCode(request)
+	if err != nil {
+		return nil, "", err
+	}
+	return request, code, nil
+}
+
+// ListConsents kullanıcının onay verdiği istemcileri döner
+func (s *OAuthService) ListConsents(userID string) ([]models.OAuthConsent, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := s.consentCollection.Find(ctx, bson.M{"userId": objID})
+	if err != nil {
+		return nil, err
+	}
+	consents := []models.OAuthConsent{}
+	if err := cursor.All(ctx, &consents); err != nil {
+		return nil, err
+	}
+	return consents, nil
+}
+
+// RevokeConsent istemciye verilen izni ve istemcinin kullanıcı adına aldığı yenileme tokenlerini iptal eder
+func (s *OAuthService) RevokeConsent(userID, clientID string) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.consentCollection.DeleteOne(ctx, bson.M{"userId": objID, "clientId": clientID}); err != nil {
+		return err
+	}
+	_, err = s.refreshCollection.UpdateMany(ctx,
+		bson.M{"userId": objID, "clientId": clientID},
+		bson.M{"$set": bson.M{"revoked": true}},
+	)
+	return err
+}
+
+// verifyPKCE code_verifier değerinin S256 özetini yetkilendirme isteğindeki code_challenge ile karşılaştırır
+func verifyPKCE(request *models.OAuthAuthorizationRequest, verifier string) bool {
+	if request.CodeChallenge == "" {
+		return verifier == ""
+	}
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(request.CodeChallenge)) == 1
+}
+
+// ExchangeCode authorization_code grant'ini işler
+func (s *OAuthService) ExchangeCode(client *models.OAuthClient, code, redirectURI, codeVerifier string) (*dto.OAuthTokenResponse, error) {
+	if !client.AllowsGrant(models.GrantAuthorizationCode) {
+		return nil, oauthError("unauthorized_client", "istemci authorization_code akışını kullanamaz")
+	}
+
+	request, err := s.oauthRepo.TakeCode(hashCode(code))
+	if err != nil {
+		if err == repository.ErrAuthorizationCodeNotFound {
+			return nil, oauthError("invalid_grant", err.Error())
+		}
+		return nil, oauthServerError(err)
+	}
+	if request.ClientID != client.ClientID {
+		return nil, oauthError("invalid_grant", "kod bu istemciye verilmedi")
+	}
+	if request.RedirectURI != redirectURI {
+		return nil, oauthError("invalid_grant", "redirect_uri yetkilendirme isteğiyle eşleşmiyor")
+	}
+	if !verifyPKCE(request, codeVerifier) {
+		return nil, oauthError("invalid_grant", "code_verifier geçersiz")
+	}
+
+	user, err := s.findActiveUser(request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(client, user, request.Scopes, request.Nonce, request.AuthTime, "")
+}
+
+// RefreshTokens refresh_token grant'ini işler. Kullanılan token yenisiyle değiştirilir;
+// daha önce kullanılmış bir token tekrar gelirse çalınmış sayılır ve tüm token ailesi iptal edilir.
+func (s *OAuthService) RefreshTokens(client *models.OAuthClient, refreshToken, scope string) (*dto.OAuthTokenResponse, error) {
+	if !client.AllowsGrant(models.GrantRefreshToken) {
+		return nil, oauthError("unauthorized_client", "istemci refresh_token akışını kullanamaz")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var stored models.OAuthRefreshToken
+	err := s.refreshCollection.FindOne(ctx, bson.M{"tokenHash": hashCode(refreshToken)}).Decode(&stored)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, oauthError("invalid_grant", "yenileme tokeni geçersiz")
+		}
+		return nil, oauthServerError(err)
+	}
+	if stored.ClientID != client.ClientID {
+		return nil, oauthError("invalid_grant", "yenileme tokeni bu istemciye verilmedi")
+	}
+	if stored.Revoked || time.Now().After(stored.ExpiresAt) {
+		return nil, oauthError("invalid_grant", "yenileme tokeni geçersiz")
+	}
+
+	// Tokeni atomik olarak kullanıldı işaretle; eşzamanlı iki istekten yalnızca biri başarılı olur
+	result, err := s.refreshCollection.UpdateOne(ctx,
+		bson.M{"_id": stored.ID, "used": false, "revoked": false},
+		bson.M{"$set": bson.M{"used": true}},
+	)
+	if err != nil {
+		return nil, oauthServerError(err)
+	}
+	if result.ModifiedCount == 0 {
+		if _, err := s.refreshCollection.UpdateMany(ctx,
+			bson.M{"familyId": stored.FamilyID},
+			bson.M{"$set": bson.M{"revoked": true}},
+		); err != nil {
+			return nil, oauthServerError(err)
+		}
+		return nil, oauthError("invalid_grant", "yenileme tokeni daha önce kullanılmış")
+	}
+
+	scopes := stored.Scopes
+	if requested := parseScopes(scope); len(requested) > 0 {
+		if !scopesSubset(requested, stored.Scopes) {
+			return nil, oauthError("invalid_scope", "istenen kapsamlar ilk yetkilendirmeyi aşıyor")
+		}
+		scopes = requested
+	}
+
+	user, err := s.findActiveUser(stored.UserID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(client, user, scopes, "", stored.AuthTime, stored.FamilyID)
+}
+
+// ClientCredentials client_credentials grant'ini işler; token kullanıcı yerine istemcinin kendisini temsil eder
+func (s *OAuthService) ClientCredentials(client *models.OAuthClient, scope string) (*dto.OAuthTokenResponse, error) {
+	if client.Public || !client.AllowsGrant(models.GrantClientCredentials) {
+		return nil, oauthError("unauthorized_client", "istemci client_credentials akışını kullanamaz")
+	}
+
+	scopes := parseScopes(scope)
+	if len(scopes) == 0 {
+		for _, allowed := range client.Scopes {
+			if allowed != models.ScopeOpenID && allowed != models.ScopeProfile && allowed != models.ScopeEmail {
+				scopes = append(scopes, allowed)
+			}
+		}
+	}
+	if !scopesSubset(scopes, client.Scopes) || hasScope(scopes, models.ScopeOpenID) {
+		return nil, oauthError("invalid_scope", "istenen kapsamlar istemciye tanımlı değil")
+	}
+
+	accessToken, err := s.signAccessToken(client.ClientID, client.ClientID, scopes)
+	if err != nil {
+		return nil, oauthServerError(err)
+	}
+	return &dto.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.config.AccessTokenTTL.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+func (s *OAuthService) findActiveUser(userID string) (*models.User, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, oauthError("invalid_grant", "kullanıcı bulunamadı")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user models.User
+	if err := s.userCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, oauthError("invalid_grant", "kullanıcı bulunamadı")
+		}
+		return nil, oauthServerError(err)
+	}
+	if user.IsDeleted {
+		return nil, oauthError("invalid_grant", "kullanıcı bulunamadı")
+	}
+	if user.Restriction.Active(time.Now()) {
+		return nil, oauthError("invalid_grant", "kullanıcı hesabı kısıtlanmış")
+	}
+	if user.Deletion.Pending() {
+		return nil, oauthError("invalid_grant", "kullanıcı hesabı silinmek üzere")
+	}
+	return &user, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *OAuthService) signAccessToken(subject, clientID string, scopes []string) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	return s.jwtHelper.SignToken(map[string]interface{}{
+		"sub":       subject,
+		"aud":       clientID,
+		"client_id": clientID,
+		"scope":     strings.Join(scopes, " "),
+		"jti":       jti,
+		"token_use": tokenUseAccess,
+	}, s.config.AccessTokenTTL)
+}
+
+// issueTokens erişim tokeni, istemci izin veriyorsa yenileme tokeni ve "openid" kapsamı varsa ID token üretir
+func (s *OAuthService) issueTokens(client *models.OAuthClient, user *models.User, scopes []string, nonce string, authTime time.Time, familyID string) (*dto.OAuthTokenResponse, error) {
+	accessToken, err := s.signAccessToken(user.ID.Hex(), client.ClientID, scopes)
+	if err != nil {
+		return nil, oauthServerError(err)
+	}
+	response := &dto.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.config.AccessTokenTTL.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}
+
+	if client.AllowsGrant(models.GrantRefreshToken) {
+		refreshToken, err := s.createRefreshToken(client, user, scopes, authTime, familyID)
+		if err != nil {
+			return nil, oauthServerError(err)
+		}
+		response.RefreshToken = refreshToken
+	}
+
+	if hasScope(scopes, models.ScopeOpenID) {
+		claims := map[string]interface{}{
+			"sub":       user.ID.Hex(),
+			"aud":       client.ClientID,
+			"azp":       client.ClientID,
+			"auth_time": authTime.Unix(),
+			"token_use": tokenUseID,
+		}
+		if nonce != "" {
+			claims["nonce"] = nonce
+		}
+		for key, value := range userClaims(user, scopes) {
+			claims[key] = value
+		}
+		idToken, err := s.jwtHelper.SignToken(claims, s.config.IDTokenTTL)
+		if err != nil {
+			return nil, oauthServerError(err)
+		}
+		response.IDToken = idToken
+	}
+	return response, nil
+}
+
+func (s *OAuthService) createRefreshToken(client *models.OAuthClient, user *models.User, scopes []string, authTime time.Time, familyID string) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if familyID == "" {
+		if familyID, err = newTokenID(); err != nil {
+			return "", err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	_, err = s.refreshCollection.InsertOne(ctx, models.OAuthRefreshToken{
+		TokenHash: hashCode(token),
+		FamilyID:  familyID,
+		ClientID:  client.ClientID,
+		UserID:    user.ID,
+		Scopes:    scopes,
+		AuthTime:  authTime,
+		ExpiresAt: now.Add(s.config.RefreshTokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// userClaims kapsamlara göre ID token ve userinfo yanıtına eklenecek kullanıcı bilgilerini döner
+func userClaims(user *models.User, scopes []string) map[string]interface{} {
+	claims := map[string]interface{}{"sub": user.ID.Hex()}
+	if hasScope(scopes, models.ScopeProfile) {
+		claims["preferred_username"] = user.Username
+		claims["name"] = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		claims["given_name"] = user.FirstName
+		claims["family_name"] = user.LastName
+		claims["updated_at"] = user.UpdatedAt.Unix()
+		if user.ProfilePhoto != nil {
+			claims["picture"] = *user.ProfilePhoto
+		}
+	}
+	if hasScope(scopes, models.ScopeEmail) {
+		claims["email"] = user.Email
+		// Hesaplar e-posta ile gönderilen kodla aktifleştirildiği için adres doğrulanmıştır
+		claims["email_verified"] = true
+	}
+	return claims
+}
+
+// VerifyAccessToken erişim tokeninin imzasını, süresini ve iptal durumunu kontrol eder
+func (s *OAuthService) VerifyAccessToken(token string) (map[string]interface{}, error) {
+	claims, err := s.jwtHelper.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if use, _ := claims["token_use"].(string); use != tokenUseAccess {
+		return nil, fmt.Errorf("token bir erişim tokeni değil")
+	}
+	jti, _ := claims["jti"].(string)
+	revoked, err := s.oauthRepo.IsAccessTokenRevoked(jti)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("token iptal edilmiş")
+	}
+	return claims, nil
+}
+
+// UserInfo erişim tokeninin kapsamlarına göre kullanıcı bilgilerini döner
+func (s *OAuthService) UserInfo(claims map[string]interface{}) (map[string]interface{}, error) {
+	scopes := parseScopes(fmt.Sprint(claims["scope"]))
+	if !hasScope(scopes, models.ScopeOpenID) {
+		return nil, oauthError("insufficient_scope", "userinfo için openid kapsamı gereklidir")
+	}
+	subject, _ := claims["sub"].(string)
+	user, err := s.findActiveUser(subject)
+	if err != nil {
+		return nil, oauthError("invalid_token", "kullanıcı bulunamadı")
+	}
+	return userClaims(user, scopes), nil
+}
+
+// Introspect RFC 7662'ye göre tokenin geçerli olup olmadığını ve ait olduğu bilgileri döner
+func (s *OAuthService) Introspect(client *models.OAuthClient, token, tokenTypeHint string) *dto.OAuthIntrospectionResponse {
+	inactive := &dto.OAuthIntrospectionResponse{Active: false}
+
+	if tokenTypeHint != "refresh_token" {
+		if claims, err := s.VerifyAccessToken(token); err == nil {
+			response := &dto.OAuthIntrospectionResponse{
+				Active:    true,
+				TokenType: "access_token",
+				Scope:     fmt.Sprint(claims["scope"]),
+				ClientID:  fmt.Sprint(claims["client_id"]),
+				Sub:       fmt.Sprint(claims["sub"]),
+				Aud:       fmt.Sprint(claims["aud"]),
+				Iss:       fmt.Sprint(claims["iss"]),
+				Jti:       fmt.Sprint(claims["jti"]),
+			}
+			if exp, ok := claims["exp"].(float64); ok {
+				response.Exp = int64(exp)
+			}
+			if iat, ok := claims["iat"].(float64); ok {
+				response.Iat = int64(iat)
+			}
+			if user, err := s.findActiveUser(response.Sub); err == nil {
+				response.Username = user.Username
+			}
+			return response
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Yenileme tokenleri yalnızca ait oldukları istemciye açıklanır
+	var stored models.OAuthRefreshToken
+	err := s.refreshCollection.FindOne(ctx, bson.M{"tokenHash": hashCode(token), "clientId": client.ClientID}).Decode(&stored)
+	if err != nil || stored.Used || stored.Revoked || time.Now().After(stored.ExpiresAt) {
+		return inactive
+	}
+	return &dto.OAuthIntrospectionResponse{
+		Active:    true,
+		TokenType: "refresh_token",
+		Scope:     strings.Join(stored.Scopes, " "),
+		ClientID:  stored.ClientID,
+		Sub:       stored.UserID.Hex(),
+		Exp:       stored.ExpiresAt.Unix(),
+		Iat:       stored.CreatedAt.Unix(),
+		Iss:       s.issuer,
+	}
+}
+
+// Revoke RFC 7009'a göre tokeni iptal eder. Bilinmeyen tokenler hata sayılmaz.
+func (s *OAuthService) Revoke(client *models.OAuthClient, token, tokenTypeHint string) error {
+	if tokenTypeHint != "access_token" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var stored models.OAuthRefreshToken
+		err := s.refreshCollection.FindOne(ctx, bson.M{"tokenHash": hashCode(token), "clientId": client.ClientID}).Decode(&stored)
+		if err == nil {
+			_, err = s.refreshCollection.UpdateMany(ctx,
+				bson.M{"familyId": stored.FamilyID},
+				bson.M{"$set": bson.M{"revoked": true}},
+			)
+			return err
+		}
+		if err != mongo.ErrNoDocuments {
+			return err
+		}
+	}
+
+	claims, err := s.jwtHelper.VerifyToken(token)
+	if err != nil {
+		return nil
+	}
+	if clientID, _ := claims["client_id"].(string); clientID != client.ClientID {
+		return nil
+	}
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+	return s.oauthRepo.RevokeAccessToken(jti, time.Until(time.Unix(int64(exp), 0)))
+}
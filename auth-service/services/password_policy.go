@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+)
+
+// Politika kuralları; istemciler hangi kuralın ihlal edildiğini bu adlarla ayırt eder
+const (
+	RuleMinLength   = "min_length"
+	RuleUppercase   = "uppercase"
+	RuleLowercase   = "lowercase"
+	RuleDigit       = "digit"
+	RuleSymbol      = "symbol"
+	RuleStrength    = "strength"
+	RuleUserInfo    = "user_info"
+	RuleBreached    = "breached"
+	breachedPrefixN = 5
+)
+
+// PasswordViolation ihlal edilen tek bir politika kuralını tanımlar
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError şifrenin ihlal ettiği tüm kuralları taşır
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return "şifre politikaya uygun değil: " + strings.Join(messages, "; ")
+}
+
+// IsPasswordPolicyError hatanın bir şifre politikası hatası olup olmadığını döner
+func IsPasswordPolicyError(err error) (*PasswordPolicyError, bool) {
+	var policyErr *PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return policyErr, true
+	}
+	return nil, false
+}
+
+// PasswordPolicy kayıt, sıfırlama ve şifre değiştirmede ortak kullanılan şifre kurallarını uygular
+type PasswordPolicy struct {
+	cfg config.PasswordPolicyConfig
+
+	// Dosyadan yüklenen sızdırılmış şifre özetleri: ilk 5 hex karakter -> kalan 35 karakter
+	breached map[string]map[string]struct{}
+	// Dizin verildiyse her önek için ayrı bir dosya (HIBP "range" biçimi) istek anında okunur
+	breachedDir string
+}
+
+// NewPasswordPolicy politikayı oluşturur ve varsa sızdırılmış şifre listesini yükler
+func NewPasswordPolicy(cfg config.PasswordPolicyConfig) *PasswordPolicy {
+	p := &PasswordPolicy{cfg: cfg}
+	if cfg.BreachedPasswordsPath == "" {
+		return p
+	}
+
+	info, err := os.Stat(cfg.BreachedPasswordsPath)
+	if err != nil {
+		log.Printf("Sızdırılmış şifre listesi bulunamadı, kontrol kapalı: %v", err)
+		return p
+	}
+	if info.IsDir() {
+		p.breachedDir = cfg.BreachedPasswordsPath
+		return p
+	}
+
+	breached, err := loadBreachedHashes(cfg.BreachedPasswordsPath)
+	if err != nil {
+		log.Printf("Sızdırılmış şifre listesi yüklenemedi, kontrol kapalı: %v", err)
+		return p
+	}
+	p.breached = breached
+	log.Printf("Sızdırılmış şifre listesi yüklendi: %d önek", len(breached))
+	return p
+}
+
+// loadBreachedHashes "SHA1" veya "SHA1:SAYI" satırlarından oluşan dosyayı öneklere göre gruplayarak yükler
+func loadBreachedHashes(path string) (map[string]map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	breached := make(map[string]map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		hash := strings.ToUpper(strings.TrimSpace(strings.SplitN(scanner.Text(), ":", 2)[0]))
+		if len(hash) != sha1.Size*2 {
+			continue
+		}
+		prefix, suffix := hash[:breachedPrefixN], hash[breachedPrefixN:]
+		if breached[prefix] == nil {
+			breached[prefix] = make(map[string]struct{})
+		}
+		breached[prefix][suffix] = struct{}{}
+	}
+	return breached, scanner.Err()
+}
+
+// Validate şifreyi tüm kurallara karşı kontrol eder; ihlal varsa hepsini içeren *PasswordPolicyError döner.
+// username ve email, şifrenin kullanıcı bilgisini içerip içermediğini kontrol etmek için kullanılır.
+func (p *PasswordPolicy) Validate(password, username, email string) error {
+	var violations []PasswordViolation
+	add := func(rule, message string) {
+		violations = append(violations, PasswordViolation{Rule: rule, Message: message})
+	}
+
+	if utf8.RuneCountInString(password) < p.cfg.MinLength {
+		add(RuleMinLength, fmt.Sprintf("şifre en az %d karakter olmalıdır", p.cfg.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	if p.cfg.RequireUppercase && !hasUpper {
+		add(RuleUppercase, "şifre en az bir büyük harf içermelidir")
+	}
+	if p.cfg.RequireLowercase && !hasLower {
+		add(RuleLowercase, "şifre en az bir küçük harf içermelidir")
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		add(RuleDigit, "şifre en az bir rakam içermelidir")
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		add(RuleSymbol, "şifre en az bir özel karakter içermelidir")
+	}
+
+	if p.cfg.DisallowUserInfo && containsUserInfo(password, username, email) {
+		add(RuleUserInfo, "şifre kullanıcı adınızı veya e-posta adresinizi içermemelidir")
+	}
+
+	if score := PasswordStrength(password, username, email); score < p.cfg.MinStrengthScore {
+		add(RuleStrength, fmt.Sprintf("şifre yeterince güçlü değil (puan %d/4, en az %d gerekli)", score, p.cfg.MinStrengthScore))
+	}
+
+	if p.isBreached(password) {
+		add(RuleBreached, "bu şifre daha önce sızdırılmış şifre listelerinde yer alıyor")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// containsUserInfo şifrenin kullanıcı adını veya e-postanın yerel kısmını içerip içermediğini kontrol eder
+func containsUserInfo(password, username, email string) bool {
+	lowered := strings.ToLower(password)
+
+	candidates := []string{strings.ToLower(username)}
+	if at := strings.Index(email, "@"); at > 0 {
+		candidates = append(candidates, strings.ToLower(email[:at]))
+	}
+	for _, candidate := range candidates {
+		// Çok kısa parçalar rastlantısal eşleşmelere yol açar
+		if utf8.RuneCountInString(candidate) >= 3 && strings.Contains(lowered, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBreached şifrenin SHA-1 özetinin sızdırılmış listede olup olmadığını kontrol eder
+func (p *PasswordPolicy) isBreached(password string) bool {
+	if p.breached == nil && p.breachedDir == "" {
+		return false
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:breachedPrefixN], hash[breachedPrefixN:]
+
+	if p.breached != nil {
+		_, found := p.breached[prefix][suffix]
+		return found
+	}
+
+	// Her önek dosyası "SUFFIX:SAYI" satırları içerir; şifrenin tamamı hiçbir zaman diske veya ağa çıkmaz
+	file, err := os.Open(filepath.Join(p.breachedDir, prefix))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Sızdırılmış şifre dosyası okunamadı: %v", err)
+		}
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.EqualFold(strings.TrimSpace(strings.SplitN(scanner.Text(), ":", 2)[0]), suffix) {
+			return true
+		}
+	}
+	return false
+}
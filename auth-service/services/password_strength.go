@@ -0,0 +1,179 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// zxcvbn'deki gibi tahmin sayısının (log10) puan eşikleri: 0 çok zayıf, 4 çok güçlü
+var strengthThresholds = []float64{3, 6, 8, 10}
+
+// Sık kullanılan şifre ve kelimeler; eşleşen parça tek bir sözlük kelimesi kadar değerlendirilir
+var commonPasswordWords = []string{
+	"password", "passw0rd", "admin", "welcome", "letmein", "monkey", "dragon", "master",
+	"sunshine", "princess", "football", "baseball", "iloveyou", "trustno1", "superman",
+	"batman", "shadow", "michael", "charlie", "jordan", "secret", "login", "hello",
+	"freedom", "whatever", "qazwsx", "starwars", "computer", "internet", "summer",
+	"winter", "spring", "autumn", "love", "test", "user", "root", "guest", "default",
+	"sifre", "parola", "merhaba", "galatasaray", "fenerbahce", "besiktas", "trabzonspor",
+	"istanbul", "ankara", "turkiye", "askim", "canim", "bebegim",
+}
+
+var keyboardRows = []string{
+	"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm", "qwertzuiop", "azertyuiop",
+}
+
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i",
+)
+
+// PasswordStrength şifrenin tahmin edilmesinin ne kadar zor olduğunu 0-4 arası puanlar.
+// Sözlük kelimeleri, tekrarlar, ardışık karakterler, klavye dizileri, yıllar ve
+// kullanıcı bilgileri kaba kuvvetten çok daha az tahminle bulunabileceği için düşük değerlendirilir.
+func PasswordStrength(password, username, email string) int {
+	if password == "" {
+		return 0
+	}
+
+	userWords := []string{strings.ToLower(username)}
+	if at := strings.Index(email, "@"); at > 0 {
+		userWords = append(userWords, strings.ToLower(email[:at]))
+	}
+
+	runes := []rune(password)
+	lowered := []rune(strings.ToLower(password))
+	deleeted := []rune(leetReplacer.Replace(strings.ToLower(password)))
+	charsetLog := math.Log10(float64(charsetSize(runes)))
+
+	guessesLog := 0.0
+	for i := 0; i < len(runes); {
+		length, cost := bestMatch(lowered, deleeted, i, userWords)
+		if length == 0 {
+			guessesLog += charsetLog
+			i++
+			continue
+		}
+		guessesLog += cost
+		i += length
+	}
+
+	score := 0
+	for _, threshold := range strengthThresholds {
+		if guessesLog >= threshold {
+			score++
+		}
+	}
+	return score
+}
+
+// bestMatch i konumundan başlayan en uzun tahmin edilebilir deseni ve o desenin tahmin maliyetini (log10) döner
+func bestMatch(lowered, deleeted []rune, i int, userWords []string) (int, float64) {
+	bestLength, bestCost := 0, 0.0
+	consider := func(length int, cost float64) {
+		if length > bestLength {
+			bestLength, bestCost = length, cost
+		}
+	}
+
+	rest := string(lowered[i:])
+	restDeleeted := ""
+	if len(deleeted) == len(lowered) {
+		restDeleeted = string(deleeted[i:])
+	}
+
+	for _, word := range commonPasswordWords {
+		if strings.HasPrefix(rest, word) || (restDeleeted != "" && strings.HasPrefix(restDeleeted, word)) {
+			consider(len([]rune(word)), math.Log10(float64(len(commonPasswordWords)))+1)
+		}
+	}
+	for _, word := range userWords {
+		if len([]rune(word)) >= 3 && strings.HasPrefix(rest, word) {
+			consider(len([]rune(word)), 1)
+		}
+	}
+
+	// Aynı karakterin tekrarı (aaaa, 1111)
+	repeat := 1
+	for i+repeat < len(lowered) && lowered[i+repeat] == lowered[i] {
+		repeat++
+	}
+	if repeat >= 3 {
+		consider(repeat, 1+math.Log10(float64(repeat)))
+	}
+
+	// Artan veya azalan ardışık karakterler (abcd, 4321)
+	for _, step := range []rune{1, -1} {
+		run := 1
+		for i+run < len(lowered) && lowered[i+run]-lowered[i+run-1] == step {
+			run++
+		}
+		if run >= 3 {
+			consider(run, 1+math.Log10(float64(run)))
+		}
+	}
+
+	// Klavye dizileri (qwerty, asdf)
+	for _, row := range keyboardRows {
+		for length := len(rest); length >= 4; length-- {
+			if length <= len(row) && strings.Contains(row, rest[:length]) {
+				consider(length, 1+math.Log10(float64(length)))
+				break
+			}
+		}
+	}
+
+	// 1900-2099 arası yıllar
+	if len(lowered) >= i+4 && isYear(lowered[i:i+4]) {
+		consider(4, math.Log10(200))
+	}
+
+	return bestLength, bestCost
+}
+
+func isYear(digits []rune) bool {
+	for _, r := range digits {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	century := string(digits[:2])
+	return century == "19" || century == "20"
+}
+
+// charsetSize şifrede kullanılan karakter sınıflarına göre kaba kuvvet alfabesinin büyüklüğünü döner
+func charsetSize(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	size := 0
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if symbol {
+		size += 33
+	}
+	if other {
+		size += 100
+	}
+	return size
+}
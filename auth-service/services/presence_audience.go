@@ -0,0 +1,121 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+)
+
+type audienceEntry struct {
+	userIDs   map[string]bool
+	expiresAt time.Time
+}
+
+// PresenceAudience bir kullanıcının durumunu takip edebileceği kişileri belirler. Sohbetler
+// chat-service'e ait olduğu için liste onun iç endpointinden alınır ve kısa süre önbellekte tutulur.
+type PresenceAudience struct {
+	url        string
+	secret     string
+	cacheTTL   time.Duration
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]audienceEntry
+}
+
+func NewPresenceAudience(cfg config.PresenceConfig, secret string) *PresenceAudience {
+	return &PresenceAudience{
+		url:        cfg.AudienceURL,
+		secret:     secret,
+		cacheTTL:   cfg.AudienceCacheTTL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		cache:      make(map[string]audienceEntry),
+	}
+}
+
+// Contacts kullanıcının en az bir sohbeti paylaştığı kişileri döner
+func (a *PresenceAudience) Contacts(userID string) (map[string]bool, error) {
+	a.mu.Lock()
+	entry, ok := a.cache[userID]
+	a.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.userIDs, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(a.url, userID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(middlewares.InternalSecretHeader, a.secret)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sohbet arkadaşları alınamadı: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sohbet arkadaşları alınamadı: HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		UserIDs []string `json:"userIds"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("sohbet arkadaşları çözümlenemedi: %w", err)
+	}
+
+	userIDs := make(map[string]bool, len(body.UserIDs))
+	for _, id := range body.UserIDs {
+		if id != userID {
+			userIDs[id] = true
+		}
+	}
+
+	a.mu.Lock()
+	now := time.Now()
+	for key, cached := range a.cache {
+		if now.After(cached.expiresAt) {
+			delete(a.cache, key)
+		}
+	}
+	a.cache[userID] = audienceEntry{userIDs: userIDs, expiresAt: now.Add(a.cacheTTL)}
+	a.mu.Unlock()
+	return userIDs, nil
+}
+
+// Allowed istenen kişilerden takip edilebilenleri en fazla limit kadar döner;
+// requested boşsa tüm sohbet arkadaşları döner
+func (a *PresenceAudience) Allowed(userID string, requested []string, limit int) ([]string, error) {
+	contacts, err := a.Contacts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := []string{}
+	if len(requested) == 0 {
+		for id := range contacts {
+			if len(allowed) >= limit {
+				break
+			}
+			allowed = append(allowed, id)
+		}
+		return allowed, nil
+	}
+
+	seen := map[string]bool{}
+	for _, id := range requested {
+		if len(allowed) >= limit {
+			break
+		}
+		if contacts[id] && !seen[id] {
+			seen[id] = true
+			allowed = append(allowed, id)
+		}
+	}
+	return allowed, nil
+}
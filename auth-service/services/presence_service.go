@@ -0,0 +1,164 @@
+package services
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+)
+
+// PresenceService kullanıcıların websocket bağlantılarını tüm servis örnekleri arasında Redis'te sayar;
+// kullanıcı ilk bağlantısını açtığında çevrimiçi, son bağlantısı kapandığında veya kalp atışı
+// kesildiğinde çevrimdışı olur
+type PresenceService struct {
+	redisRepo *redisrepo.RedisRepository
+	userRepo  *repository.UserRepository
+	audience  *PresenceAudience
+	config    config.PresenceConfig
+}
+
+func NewPresenceService(redisRepo *redisrepo.RedisRepository, userRepo *repository.UserRepository, audience *PresenceAudience, cfg config.PresenceConfig) *PresenceService {
+	return &PresenceService{
+		redisRepo: redisRepo,
+		userRepo:  userRepo,
+		audience:  audience,
+		config:    cfg,
+	}
+}
+
+// HeartbeatInterval sunucunun bağlantılara ping göndermesi gereken sıklıktır
+func (s *PresenceService) HeartbeatInterval() time.Duration {
+	return s.config.HeartbeatInterval
+}
+
+// ConnectionTTL kalp atışı gelmeyen bağlantının düşmüş sayılacağı süredir
+func (s *PresenceService) ConnectionTTL() time.Duration {
+	return s.config.ConnectionTTL
+}
+
+// Connect yeni bir bağlantı kaydeder ve bağlantı kimliğini döner
+func (s *PresenceService) Connect(userID string) (string, error) {
+	connectionID, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.Heartbeat(userID, connectionID); err != nil {
+		return "", err
+	}
+	return connectionID, nil
+}
+
+// Heartbeat bağlantının süresini uzatır; bağlantı temizlenmişse yeniden ekler
+func (s *PresenceService) Heartbeat(userID, connectionID string) error {
+	cameOnline, err := s.redisRepo.TouchPresenceConnection(userID, connectionID, s.config.ConnectionTTL)
+	if err != nil {
+		return err
+	}
+	if cameOnline {
+		s.changed(userID)
+	}
+	return nil
+}
+
+// Disconnect bağlantıyı siler; kullanıcının son bağlantısıysa kullanıcı çevrimdışı olur
+func (s *PresenceService) Disconnect(userID, connectionID string) error {
+	wentOffline, err := s.redisRepo.RemovePresenceConnection(userID, connectionID)
+	if err != nil {
+		return err
+	}
+	if wentOffline {
+		s.changed(userID)
+	}
+	return nil
+}
+
+// SetStatus kullanıcının seçtiği durumu ve özel durum metnini kaydeder
+func (s *PresenceService) SetStatus(userID string, input *dto.UpdateStatusDto) (*redisrepo.Presence, error) {
+	var textExpiresAt time.Time
+	text := strings.TrimSpace(input.StatusText)
+	if text != "" && input.StatusTextExpiresInMinutes > 0 {
+		textExpiresAt = time.Now().Add(time.Duration(input.StatusTextExpiresInMinutes) * time.Minute)
+	}
+
+	if err := s.redisRepo.SetPresenceStatus(userID, input.Status, text, textExpiresAt); err != nil {
+		return nil, err
+	}
+	return s.changed(userID), nil
+}
+
+// GetPresence kullanıcının kendi durumunu döner
+func (s *PresenceService) GetPresence(userID string) (*redisrepo.Presence, error) {
+	return s.redisRepo.GetPresence(userID)
+}
+
+// Subscribable viewerID'nin durumunu takip edebileceği kullanıcıları döner; requested boşsa
+// tüm sohbet arkadaşları, değilse istenenlerden sohbet arkadaşı olanlar döner. watching bağlantının
+// halihazırda takip ettiği kullanıcı sayısıdır, toplam MaxSubscriptions'ı geçemez.
+func (s *PresenceService) Subscribable(viewerID string, requested []string, watching int) ([]string, error) {
+	return s.audience.Allowed(viewerID, requested, s.config.MaxSubscriptions-watching)
+}
+
+// Snapshot kullanıcıların diğer kullanıcılara görünen güncel durumlarını döner
+func (s *PresenceService) Snapshot(userIDs []string) ([]*redisrepo.Presence, error) {
+	presences, err := s.redisRepo.GetPresences(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, presence := range presences {
+		presences[i] = presence.Public()
+	}
+	return presences, nil
+}
+
+// changed güncel durumu veritabanına yazar ve tüm servis örneklerine yayınlar
+func (s *PresenceService) changed(userID string) *redisrepo.Presence {
+	presence, err := s.redisRepo.GetPresence(userID)
+	if err != nil {
+		log.Printf("Kullanıcı durumu okunamadı: %v", err)
+		return nil
+	}
+
+	// Veritabanında diğer kullanıcıların göreceği durum tutulur
+	public := presence.Public()
+	if err := s.userRepo.UpdateUserPresence(userID, public.Status, public.LastSeenAt); err != nil {
+		log.Printf("Kullanıcı durumu güncellenemedi: %v", err)
+	}
+	publicChanged, err := s.redisRepo.SwapPublicPresence(public)
+	if err != nil {
+		log.Printf("Kullanıcı durumu kaydedilemedi: %v", err)
+		publicChanged = true
+	}
+	if err := s.redisRepo.PublishPresence(presence, publicChanged); err != nil {
+		log.Printf("Kullanıcı durumu yayınlanamadı: %v", err)
+	}
+	return presence
+}
+
+// StartCleanup kalp atışı kesilmiş bağlantıları düzenli aralıklarla temizler; stop kapatılınca durur.
+// Temizlik her servis örneğinde çalışabilir, bir kullanıcının çevrimdışı olması yalnızca bir kez yayınlanır.
+func (s *PresenceService) StartCleanup(stop <-chan struct{}) {
+	if s.config.CleanupInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			userIDs, err := s.redisRepo.ExpireStalePresence()
+			if err != nil {
+				log.Printf("Düşmüş bağlantılar temizlenemedi: %v", err)
+			}
+			for _, userID := range userIDs {
+				s.changed(userID)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/go-redis/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	totpIssuer                = "GoMicroservice"
+	recoveryCodeCount         = 10
+	twoFactorChallengeTTL     = 5 * time.Minute
+	maxTwoFactorAttempts      = 5
+	twoFactorChallengePrefix  = "2fa_challenge:"
+	twoFactorAttemptKeyPrefix = "2fa_challenge_attempts:"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("iki adımlı doğrulama zaten etkin")
+	ErrTwoFactorNotEnabled     = errors.New("iki adımlı doğrulama etkin değil")
+	ErrTwoFactorNotEnrolled    = errors.New("önce iki adımlı doğrulama kurulumu başlatılmalı")
+	ErrInvalidTwoFactorCode    = errors.New("doğrulama kodu hatalı")
+	ErrInvalidPassword         = errors.New("şifre hatalı")
+	ErrChallengeNotFound       = errors.New("doğrulama isteği bulunamadı veya süresi doldu")
+)
+
+type TwoFactorService struct {
+	collection  *mongo.Collection
+	redisClient *redis.Client
+}
+
+func NewTwoFactorService() *TwoFactorService {
+	collection, _ := database.GetCollection("authDB", "users")
+	return &TwoFactorService{
+		collection:  collection,
+		redisClient: database.RedisClient,
+	}
+}
+
+func (s *TwoFactorService) findUser(userID string) (*models.User, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("geçersiz kullanıcı ID'si")
+	}
+
+	var user models.User
+	if err := s.collection.FindOne(context.Background(), bson.M{"_id": objID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("kullanıcı bulunamadı")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// BeginEnrollment yeni bir TOTP anahtarı üretir; anahtar ilk kod doğrulanana kadar beklemede kalır
+func (s *TwoFactorService) BeginEnrollment(userID string) (string, string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TwoFactor != nil && user.TwoFactor.Enabled {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"twoFactor.enabled":       false,
+		"twoFactor.pendingSecret": secret,
+	}}
+	if _, err := s.collection.UpdateOne(context.Background(), bson.M{"_id": user.ID}, update); err != nil {
+		return "", "", fmt.Errorf("iki adımlı doğrulama kaydedilemedi: %v", err)
+	}
+
+	return secret, TOTPURI(totpIssuer, user.Email, secret), nil
+}
+
+// ConfirmEnrollment ilk kodu doğrular, 2FA'yı etkinleştirir ve kurtarma kodlarını döner
+func (s *TwoFactorService) ConfirmEnrollment(userID, code string) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactor == nil || user.TwoFactor.PendingSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := ValidateTOTP(user.TwoFactor.PendingSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"twoFactor": models.TwoFactorSettings{
+		Enabled:       true,
+		Secret:        user.TwoFactor.PendingSecret,
+		RecoveryCodes: hashes,
+		LastUsedStep:  step,
+		EnabledAt:     &now,
+	}}}
+	if _, err := s.collection.UpdateOne(context.Background(), bson.M{"_id": user.ID}, update); err != nil {
+		return nil, fmt.Errorf("iki adımlı doğrulama etkinleştirilemedi: %v", err)
+	}
+	return codes, nil
+}
+
+func (s *TwoFactorService) verifyPassword(user *models.User, password string) error {
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// Disable şifre doğrulandıktan sonra 2FA'yı kapatır
+func (s *TwoFactorService) Disable(userID, password string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyPassword(user, password); err != nil {
+		return err
+	}
+	if user.TwoFactor == nil || !user.TwoFactor.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	_, err = s.collection.UpdateOne(context.Background(), bson.M{"_id": user.ID}, bson.M{"$unset": bson.M{"twoFactor": ""}})
+	return err
+}
+
+// RegenerateRecoveryCodes şifre doğrulandıktan sonra eski kurtarma kodlarını geçersiz kılar
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID, password string) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyPassword(user, password); err != nil {
+		return nil, err
+	}
+	if user.TwoFactor == nil || !user.TwoFactor.Enabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	update := bson.M{"$set": bson.M{"twoFactor.recoveryCodes": hashes}}
+	if _, err := s.collection.UpdateOne(context.Background(), bson.M{"_id": user.ID}, update); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CreateChallenge şifresi doğrulanmış kullanıcı için kısa ömürlü bir "2FA bekleniyor" tokeni üretir
+func (s *TwoFactorService) CreateChallenge(userID string) (string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.redisClient.Set(twoFactorChallengePrefix+token, userID, twoFactorChallengeTTL).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// VerifyChallenge TOTP ya da kurtarma kodunu doğrular ve başarılıysa kullanıcıyı döner
+func (s *TwoFactorService) VerifyChallenge(challengeToken, code, recoveryCode string) (*models.User, error) {
+	userID, err := s.redisClient.Get(twoFactorChallengePrefix + challengeToken).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrChallengeNotFound
+		}
+		return nil, err
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactor == nil || !user.TwoFactor.Enabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	// Deneme kod kontrolünden önce sayılır; aynı challenge ile eşzamanlı istekler sınırı aşamaz
+	attemptKey := twoFactorAttemptKeyPrefix + challengeToken
+	attempts, err := s.redisClient.Incr(attemptKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	s.redisClient.Expire(attemptKey, twoFactorChallengeTTL)
+	if attempts > maxTwoFactorAttempts {
+		s.redisClient.Del(twoFactorChallengePrefix+challengeToken, attemptKey)
+		return nil, ErrChallengeNotFound
+	}
+
+	var verified bool
+	if recoveryCode != "" {
+		verified, err = s.consumeRecoveryCode(user, recoveryCode)
+	} else {
+		verified, err = s.consumeTOTP(user, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !verified {
+		if attempts >= maxTwoFactorAttempts {
+			s.redisClient.Del(twoFactorChallengePrefix+challengeToken, attemptKey)
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	// Challenge tek kullanımlıktır
+	deleted, err := s.redisClient.Del(twoFactorChallengePrefix + challengeToken).Result()
+	if err != nil {
+		return nil, err
+	}
+	s.redisClient.Del(attemptKey)
+	if deleted == 0 {
+		return nil, ErrChallengeNotFound
+	}
+	return user, nil
+}
+
+// consumeTOTP kodu doğrular; aynı zaman adımının tekrar kullanılmasını atomik olarak engeller
+func (s *TwoFactorService) consumeTOTP(user *models.User, code string) (bool, error) {
+	step, ok := ValidateTOTP(user.TwoFactor.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	filter := bson.M{
+		"_id": user.ID,
+		"$or": []bson.M{
+			{"twoFactor.lastUsedStep": bson.M{"$lt": step}},
+			{"twoFactor.lastUsedStep": bson.M{"$exists": false}},
+		},
+	}
+	result, err := s.collection.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"twoFactor.lastUsedStep": step}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// consumeRecoveryCode kurtarma kodunu listeden çıkararak tek kullanımlık olmasını sağlar
+func (s *TwoFactorService) consumeRecoveryCode(user *models.User, recoveryCode string) (bool, error) {
+	hash := hashCode(normalizeRecoveryCode(recoveryCode))
+	result, err := s.collection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": user.ID, "twoFactor.recoveryCodes": hash},
+		bson.M{"$pull": bson.M{"twoFactor.recoveryCodes": hash}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrCannotModifySelf = errors.New("bu işlemi kendi hesabınız üzerinde yapamazsınız")
+	ErrNotRestricted    = errors.New("hesapta etkin bir kısıtlama yok")
+	ErrUnknownRole      = errors.New("geçersiz rol")
+)
+
+// AccountRestrictedError askıya alınmış veya yasaklanmış bir hesapla oturum açılmaya çalışıldığında döner
+type AccountRestrictedError struct {
+	Restriction *models.AccountRestriction
+}
+
+func (e *AccountRestrictedError) Error() string {
+	message := "Hesabınız askıya alındı"
+	if e.Restriction.Status == models.RestrictionBanned {
+		message = "Hesabınız yasaklandı"
+	}
+	if e.Restriction.Reason != "" {
+		message += ": " + e.Restriction.Reason
+	}
+	if e.Restriction.ExpiresAt != nil {
+		message += fmt.Sprintf(" (bitiş: %s)", e.Restriction.ExpiresAt.Format("02.01.2006 15:04"))
+	}
+	return message
+}
+
+// CheckAccountAccess hesapta etkin bir kısıtlama varsa AccountRestrictedError döner
+func CheckAccountAccess(restriction *models.AccountRestriction) error {
+	if restriction.Active(time.Now()) {
+		return &AccountRestrictedError{Restriction: restriction}
+	}
+	return nil
+}
+
+// UserAdminService yöneticilerin kullanıcı hesaplarını aramasını, kısıtlamasını, rollerini
+// değiştirmesini ve oturumlarını sonlandırmasını sağlar
+type UserAdminService struct {
+	userRepo    *repository.UserRepository
+	sessionRepo *redisrepo.RedisRepository
+}
+
+func NewUserAdminService(userRepo *repository.UserRepository, sessionRepo *redisrepo.RedisRepository) *UserAdminService {
+	return &UserAdminService{userRepo: userRepo, sessionRepo: sessionRepo}
+}
+
+// searchFilter arama filtrelerini Mongo sorgusuna çevirir; süresi dolmuş kısıtlamalar etkin sayılır
+func searchFilter(input *dto.AdminUserSearchDto, now time.Time) bson.M {
+	var conditions []bson.M
+
+	if query := strings.TrimSpace(input.Query); query != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+		or := []bson.M{
+			{"username": pattern},
+			{"email": pattern},
+			{"firstName": pattern},
+			{"lastName": pattern},
+		}
+		if objID, err := primitive.ObjectIDFromHex(query); err == nil {
+			or = append(or, bson.M{"_id": objID})
+		}
+		conditions = append(conditions, bson.M{"$or": or})
+	}
+
+	if input.Role != "" {
+		conditions = append(conditions, bson.M{"roles": input.Role})
+	}
+
+	restrictionActive := bson.M{"$or": []bson.M{
+		{"restriction.expiresAt": bson.M{"$exists": false}},
+		{"restriction.expiresAt": bson.M{"$gt": now}},
+	}}
+	switch input.Status {
+	case "deleted":
+		conditions = append(conditions, bson.M{"isDeleted": true})
+	case "active":
+		conditions = append(conditions, bson.M{"isDeleted": bson.M{"$ne": true}}, bson.M{"$or": []bson.M{
+			{"restriction": bson.M{"$exists": false}},
+			{"restriction.expiresAt": bson.M{"$lte": now}},
+		}})
+	case string(models.RestrictionSuspended), string(models.RestrictionBanned):
+		conditions = append(conditions,
+			bson.M{"isDeleted": bson.M{"$ne": true}},
+			bson.M{"restriction.status": input.Status},
+			restrictionActive,
+		)
+	default:
+		conditions = append(conditions, bson.M{"isDeleted": bson.M{"$ne": true}})
+	}
+
+	return bson.M{"$and": conditions}
+}
+
+// SearchUsers filtrelere uyan kullanıcıları sayfalayarak döner
+func (s *UserAdminService) SearchUsers(input *dto.AdminUserSearchDto) (*dto.AdminUserListResponse, error) {
+	skip := int64((input.Page - 1) * input.Limit)
+	users, total, err := s.userRepo.SearchUsers(searchFilter(input, time.Now()), skip, int64(input.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AdminUserListResponse{
+		Users: make([]*dto.AdminUserResponse, 0, len(users)),
+		Total: total,
+		Page:  input.Page,
+		Limit: input.Limit,
+	}
+	for i := range users {
+		response.Users = append(response.Users, dto.NewAdminUserResponse(&users[i]))
+	}
+	return response, nil
+}
+
+// GetUser kullanıcının hesap bilgilerini ve aktif oturumlarını döner
+func (s *UserAdminService) GetUser(userID string) (*dto.AdminUserDetailResponse, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.ListUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AdminUserDetailResponse{
+		User:     dto.NewAdminUserResponse(user),
+		Sessions: sessions,
+	}, nil
+}
+
+// RestrictUser hesabı askıya alır veya yasaklar; kullanıcının tüm oturumları sonlandırılır ve
+// API tokenleri kısıtlama süresince çalışmaz
+func (s *UserAdminService) RestrictUser(adminID, userID string, input *dto.RestrictUserDto) (*models.User, error) {
+	if adminID == userID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	restriction := &models.AccountRestriction{
+		Status:    input.Status,
+		Reason:    strings.TrimSpace(input.Reason),
+		CreatedBy: adminID,
+		CreatedAt: time.Now(),
+	}
+	if input.ExpiresInHours > 0 {
+		expiresAt := restriction.CreatedAt.Add(time.Duration(input.ExpiresInHours) * time.Hour)
+		restriction.ExpiresAt = &expiresAt
+	}
+	if err := s.userRepo.UpdateUserRestriction(userID, restriction); err != nil {
+		return nil, err
+	}
+	user.Restriction = restriction
+
+	if _, err := s.sessionRepo.RevokeUserSessions(userID, ""); err != nil {
+		log.Printf("Kısıtlanan kullanıcının oturumları sonlandırılamadı: %v", err)
+	}
+	if err := s.sessionRepo.DeleteUserAPITokens(userID); err != nil {
+		log.Printf("Kısıtlanan kullanıcının API tokenleri önbellekten silinemedi: %v", err)
+	}
+	return user, nil
+}
+
+// LiftRestriction hesaptaki kısıtlamayı kaldırır; API tokenleri bir sonraki eşitlemede yeniden çalışır
+func (s *UserAdminService) LiftRestriction(userID string) (*models.User, *models.AccountRestriction, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Restriction == nil {
+		return nil, nil, ErrNotRestricted
+	}
+
+	previous := user.Restriction
+	if err := s.userRepo.UpdateUserRestriction(userID, nil); err != nil {
+		return nil, nil, err
+	}
+	user.Restriction = nil
+	return user, previous, nil
+}
+
+// UpdateRoles kullanıcının rollerini değiştirir ve açık oturumlarıyla tokenlerine hemen yansıtır.
+// knownRoles yetki dosyasında tanımlı rollerdir.
+func (s *UserAdminService) UpdateRoles(adminID, userID string, roles []models.UserRole, knownRoles map[models.UserRole][]models.Permission) (*models.User, error) {
+	if adminID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	unique := make([]models.UserRole, 0, len(roles))
+	seen := map[models.UserRole]bool{}
+	for _, role := range roles {
+		if _, ok := knownRoles[role]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRole, role)
+		}
+		if !seen[role] {
+			seen[role] = true
+			unique = append(unique, role)
+		}
+	}
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateUserRoles(userID, unique); err != nil {
+		return nil, err
+	}
+	user.Roles = unique
+
+	rolesJSON, err := json.Marshal(unique)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.UpdateUserSessions(userID, map[string]string{"roles": string(rolesJSON)}); err != nil {
+		log.Printf("Oturum rolleri güncellenemedi: %v", err)
+	}
+	if err := s.sessionRepo.UpdateUserAPITokens(userID, map[string]string{"roles": string(rolesJSON)}); err != nil {
+		log.Printf("API token rolleri güncellenemedi: %v", err)
+	}
+	return user, nil
+}
+
+// ForceLogout kullanıcının tüm oturumlarını sonlandırır ve sonlandırılan oturum sayısını döner
+func (s *UserAdminService) ForceLogout(userID string) (int, error) {
+	if _, err := s.findUser(userID); err != nil {
+		return 0, err
+	}
+	return s.sessionRepo.RevokeUserSessions(userID, "")
+}
+
+func (s *UserAdminService) findUser(userID string) (*models.User, error) {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
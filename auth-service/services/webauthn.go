@@ -0,0 +1,373 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// WebAuthn Level 2 (https://www.w3.org/TR/webauthn-2/) kayıt ve doğrulama adımları.
+// Attestation güven zinciri doğrulanmaz; seçeneklerde attestation "none" istenir ve
+// yalnızca "none" ile kendi anahtarıyla imzalanmış "packed" biçimi kabul edilir.
+
+// authenticatorData bayrakları
+const (
+	webauthnFlagUserPresent    = 0x01
+	webauthnFlagUserVerified   = 0x04
+	webauthnFlagBackupEligible = 0x08
+	webauthnFlagBackupState    = 0x10
+	webauthnFlagAttestedData   = 0x40
+	webauthnFlagExtensionData  = 0x80
+)
+
+// Desteklenen COSE algoritmaları, tercih sırasıyla
+const (
+	COSEAlgES256 int64 = -7
+	COSEAlgEdDSA int64 = -8
+	COSEAlgRS256 int64 = -257
+)
+
+var webauthnSupportedAlgorithms = []int64{COSEAlgES256, COSEAlgEdDSA, COSEAlgRS256}
+
+const (
+	webauthnCeremonyCreate = "webauthn.create"
+	webauthnCeremonyGet    = "webauthn.get"
+	maxCredentialIDLength  = 1023
+	minRSAKeyBits          = 2048
+)
+
+var (
+	ErrWebAuthnInvalid        = errors.New("passkey doğrulaması başarısız")
+	ErrWebAuthnCloned         = errors.New("passkey imza sayacı geriledi, kimlik doğrulayıcı kopyalanmış olabilir")
+	ErrWebAuthnUnsupportedKey = errors.New("desteklenmeyen passkey anahtar türü")
+)
+
+func webauthnError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrWebAuthnInvalid, fmt.Sprintf(format, args...))
+}
+
+// WebAuthnRelyingParty passkeylerin bağlı olduğu alan adını ve kabul edilen kökenleri tanımlar
+type WebAuthnRelyingParty struct {
+	ID      string // Ör. "example.com"; kimlik doğrulayıcı bu değerin SHA-256 özetini imzalar
+	Name    string
+	Origins []string // Ör. "https://example.com"
+}
+
+// AuthenticatorData kimlik doğrulayıcının imzaladığı veriyi temsil eder
+type AuthenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	// Yalnızca kayıt sırasında bulunur
+	AAGUID              []byte
+	CredentialID        []byte
+	CredentialPublicKey []byte // COSE_Key biçiminde
+}
+
+func (d *AuthenticatorData) UserPresent() bool    { return d.Flags&webauthnFlagUserPresent != 0 }
+func (d *AuthenticatorData) UserVerified() bool   { return d.Flags&webauthnFlagUserVerified != 0 }
+func (d *AuthenticatorData) BackupEligible() bool { return d.Flags&webauthnFlagBackupEligible != 0 }
+func (d *AuthenticatorData) BackupState() bool    { return d.Flags&webauthnFlagBackupState != 0 }
+
+// WebAuthnRegistration doğrulanmış bir kayıt işleminden saklanacak bilgileri taşır
+type WebAuthnRegistration struct {
+	CredentialID      []byte
+	PublicKey         []byte // COSE_Key biçiminde
+	Algorithm         int64
+	SignCount         uint32
+	AAGUID            []byte
+	UserVerified      bool
+	BackupEligible    bool
+	BackupState       bool
+	AttestationFormat string
+}
+
+type collectedClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// ParseAuthenticatorData authenticatorData baytlarını çözer; fazladan bayt içeren veriyi reddeder
+func ParseAuthenticatorData(raw []byte) (*AuthenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, webauthnError("authenticatorData çok kısa")
+	}
+	data := &AuthenticatorData{
+		RPIDHash:  raw[:32],
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[37:]
+
+	if data.Flags&webauthnFlagAttestedData != 0 {
+		if len(rest) < 18 {
+			return nil, webauthnError("attestedCredentialData çok kısa")
+		}
+		data.AAGUID = rest[:16]
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength > maxCredentialIDLength || len(rest) < idLength {
+			return nil, webauthnError("geçersiz credential ID uzunluğu")
+		}
+		data.CredentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		// COSE anahtarının uzunluğu ancak CBOR çözülerek bulunabilir
+		_, remaining, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, webauthnError("credential anahtarı çözülemedi: %v", err)
+		}
+		data.CredentialPublicKey = rest[:len(rest)-len(remaining)]
+		rest = remaining
+	}
+
+	if data.Flags&webauthnFlagExtensionData != 0 {
+		_, remaining, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, webauthnError("uzantı verisi çözülemedi: %v", err)
+		}
+		rest = remaining
+	}
+
+	if len(rest) != 0 {
+		return nil, webauthnError("authenticatorData sonunda fazladan veri var")
+	}
+	return data, nil
+}
+
+// verifyClientData tarayıcının ürettiği clientDataJSON'u tören tipi, challenge ve köken açısından doğrular
+func (rp *WebAuthnRelyingParty) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var clientData collectedClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return webauthnError("clientDataJSON çözülemedi")
+	}
+	if clientData.Type != ceremony {
+		return webauthnError("beklenmeyen tören tipi %q", clientData.Type)
+	}
+
+	received, err := base64.RawURLEncoding.DecodeString(clientData.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(received, challenge) != 1 {
+		return webauthnError("challenge eşleşmiyor")
+	}
+	if !slices.Contains(rp.Origins, clientData.Origin) {
+		return webauthnError("izin verilmeyen köken %q", clientData.Origin)
+	}
+	if clientData.CrossOrigin {
+		return webauthnError("çapraz köken iframe içinden yapılan istekler kabul edilmez")
+	}
+	return nil
+}
+
+// verifyAuthenticatorData RP ID özetini ve kullanıcı varlığı/doğrulaması bayraklarını kontrol eder
+func (rp *WebAuthnRelyingParty) verifyAuthenticatorData(data *AuthenticatorData, requireUserVerification bool) error {
+	expected := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(data.RPIDHash, expected[:]) != 1 {
+		return webauthnError("RP ID özeti eşleşmiyor")
+	}
+	if !data.UserPresent() {
+		return webauthnError("kullanıcı varlığı doğrulanmadı")
+	}
+	if requireUserVerification && !data.UserVerified() {
+		return webauthnError("kullanıcı doğrulaması (PIN/biyometri) gerekli")
+	}
+	if data.BackupState() && !data.BackupEligible() {
+		return webauthnError("geçersiz yedekleme bayrakları")
+	}
+	return nil
+}
+
+// VerifyRegistration navigator.credentials.create() yanıtını doğrular ve saklanacak credential bilgisini döner
+func (rp *WebAuthnRelyingParty) VerifyRegistration(challenge, clientDataJSON, attestationObject []byte, requireUserVerification bool) (*WebAuthnRegistration, error) {
+	if err := rp.verifyClientData(clientDataJSON, webauthnCeremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, rest, err := decodeCBOR(attestationObject)
+	if err != nil || len(rest) != 0 {
+		return nil, webauthnError("attestationObject çözülemedi")
+	}
+	attestation, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, webauthnError("attestationObject harita olmalı")
+	}
+	format, _ := attestation["fmt"].(string)
+	rawAuthData, _ := attestation["authData"].([]byte)
+	statement, _ := attestation["attStmt"].(map[interface{}]interface{})
+	if format == "" || rawAuthData == nil || statement == nil {
+		return nil, webauthnError("attestationObject eksik alan içeriyor")
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return nil, err
+	}
+	if authData.CredentialID == nil {
+		return nil, webauthnError("kayıt yanıtında credential bilgisi yok")
+	}
+
+	publicKey, algorithm, err := parseCOSEKey(authData.CredentialPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	switch format {
+	case "none":
+		if len(statement) != 0 {
+			return nil, webauthnError("none biçiminde attStmt boş olmalı")
+		}
+	case "packed":
+		// Yalnızca self attestation: imza credential'ın kendi anahtarıyla atılır
+		if _, hasCertificate := statement["x5c"]; hasCertificate {
+			return nil, webauthnError("sertifikalı attestation desteklenmiyor")
+		}
+		statementAlg, _ := statement["alg"].(int64)
+		signature, _ := statement["sig"].([]byte)
+		if statementAlg != algorithm || signature == nil {
+			return nil, webauthnError("packed attestation geçersiz")
+		}
+		signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+		if err := verifyCOSESignature(publicKey, algorithm, signed, signature); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, webauthnError("desteklenmeyen attestation biçimi %q", format)
+	}
+
+	return &WebAuthnRegistration{
+		CredentialID:      append([]byte(nil), authData.CredentialID...),
+		PublicKey:         append([]byte(nil), authData.CredentialPublicKey...),
+		Algorithm:         algorithm,
+		SignCount:         authData.SignCount,
+		AAGUID:            append([]byte(nil), authData.AAGUID...),
+		UserVerified:      authData.UserVerified(),
+		BackupEligible:    authData.BackupEligible(),
+		BackupState:       authData.BackupState(),
+		AttestationFormat: format,
+	}, nil
+}
+
+// VerifyAssertion navigator.credentials.get() yanıtının imzasını saklı COSE anahtarıyla doğrular.
+// İmza sayacı saklı değerden büyük değilse (ikisi de 0 olmadıkça) ErrWebAuthnCloned döner.
+func (rp *WebAuthnRelyingParty) VerifyAssertion(challenge, clientDataJSON, rawAuthData, signature, coseKey []byte, storedSignCount uint32, requireUserVerification bool) (*AuthenticatorData, error) {
+	if err := rp.verifyClientData(clientDataJSON, webauthnCeremonyGet, challenge); err != nil {
+		return nil, err
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return nil, err
+	}
+
+	publicKey, algorithm, err := parseCOSEKey(coseKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+	if err := verifyCOSESignature(publicKey, algorithm, signed, signature); err != nil {
+		return nil, err
+	}
+
+	if (authData.SignCount != 0 || storedSignCount != 0) && authData.SignCount <= storedSignCount {
+		return nil, ErrWebAuthnCloned
+	}
+	return authData, nil
+}
+
+// parseCOSEKey COSE_Key (RFC 9053) biçimindeki açık anahtarı çözer
+func parseCOSEKey(raw []byte) (crypto.PublicKey, int64, error) {
+	decoded, rest, err := decodeCBOR(raw)
+	if err != nil || len(rest) != 0 {
+		return nil, 0, webauthnError("COSE anahtarı çözülemedi")
+	}
+	key, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, webauthnError("COSE anahtarı harita olmalı")
+	}
+
+	keyType, _ := key[int64(1)].(int64)
+	algorithm, _ := key[int64(3)].(int64)
+
+	switch {
+	case keyType == 2 && algorithm == COSEAlgES256:
+		curve, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		y, _ := key[int64(-3)].([]byte)
+		if curve != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, ErrWebAuthnUnsupportedKey
+		}
+		// Noktanın eğri üzerinde olduğu ecdh ile doğrulanır
+		point := append(append([]byte{0x04}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, 0, webauthnError("geçersiz P-256 anahtarı")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, algorithm, nil
+
+	case keyType == 1 && algorithm == COSEAlgEdDSA:
+		curve, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		if curve != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, ErrWebAuthnUnsupportedKey
+		}
+		return ed25519.PublicKey(x), algorithm, nil
+
+	case keyType == 3 && algorithm == COSEAlgRS256:
+		modulus, _ := key[int64(-1)].([]byte)
+		exponent, _ := key[int64(-2)].([]byte)
+		if len(exponent) == 0 || len(exponent) > 4 {
+			return nil, 0, ErrWebAuthnUnsupportedKey
+		}
+		publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(new(big.Int).SetBytes(exponent).Int64())}
+		if publicKey.N.BitLen() < minRSAKeyBits || publicKey.E < 3 {
+			return nil, 0, ErrWebAuthnUnsupportedKey
+		}
+		return publicKey, algorithm, nil
+
+	default:
+		return nil, 0, ErrWebAuthnUnsupportedKey
+	}
+}
+
+func verifyCOSESignature(publicKey crypto.PublicKey, algorithm int64, signed, signature []byte) error {
+	digest := sha256.Sum256(signed)
+	valid := false
+	switch algorithm {
+	case COSEAlgES256:
+		if key, ok := publicKey.(*ecdsa.PublicKey); ok {
+			valid = ecdsa.VerifyASN1(key, digest[:], signature)
+		}
+	case COSEAlgEdDSA:
+		if key, ok := publicKey.(ed25519.PublicKey); ok {
+			valid = ed25519.Verify(key, signed, signature)
+		}
+	case COSEAlgRS256:
+		if key, ok := publicKey.(*rsa.PublicKey); ok {
+			valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+		}
+	}
+	if !valid {
+		return webauthnError("imza geçersiz")
+	}
+	return nil
+}
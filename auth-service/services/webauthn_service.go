@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/config"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/dto"
+	"github.com/MKMuhammetKaradag/go-microservice/auth-service/repository"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	webauthnChallengeBytes = 32
+	maxPasskeyNameLength   = 64
+	webauthnCredentialType = "public-key"
+	userVerificationNeeded = "required"
+	defaultPasskeyName     = "Passkey"
+)
+
+var (
+	ErrWebAuthnCredentialNotFound = errors.New("passkey bulunamadı")
+	ErrWebAuthnCredentialExists   = errors.New("bu passkey zaten kayıtlı")
+)
+
+var base64URL = base64.RawURLEncoding
+
+// WebAuthnService passkey kayıt ve giriş törenlerini yürütür, credentialları authDB'de saklar
+type WebAuthnService struct {
+	users        *mongo.Collection
+	credentials  *mongo.Collection
+	repo         *repository.WebAuthnRepository
+	relyingParty *WebAuthnRelyingParty
+	config       config.WebAuthnConfig
+}
+
+func NewWebAuthnService(cfg config.WebAuthnConfig) *WebAuthnService {
+	users, _ := database.GetCollection("authDB", "users")
+	credentials, _ := database.GetCollection("authDB", "webauthn_credentials")
+	return &WebAuthnService{
+		users:        users,
+		credentials:  credentials,
+		repo:         repository.NewWebAuthnRepository(database.RedisClient),
+		relyingParty: &WebAuthnRelyingParty{ID: cfg.RPID, Name: cfg.RPName, Origins: cfg.Origins},
+		config:       cfg,
+	}
+}
+
+// decodeBase64URL tarayıcıların bazen eklediği dolgu karakterlerini de kabul eder
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64URL.DecodeString(strings.TrimRight(value, "="))
+}
+
+// startSession yeni bir challenge üretir ve töreni Redis'e kaydeder
+func (s *WebAuthnService) startSession(session *models.WebAuthnSession) (string, string, error) {
+	challenge := make([]byte, webauthnChallengeBytes)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", "", err
+	}
+	sessionID, err := generateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	session.Challenge = base64URL.EncodeToString(challenge)
+	session.UserVerification = s.config.UserVerification
+	if err := s.repo.SaveSession(sessionID, session, s.config.ChallengeTTL); err != nil {
+		return "", "", err
+	}
+	return sessionID, session.Challenge, nil
+}
+
+// takeSession töreni tek kullanımlık olarak alır ve tipini kontrol eder
+func (s *WebAuthnService) takeSession(sessionID, ceremony string) (*models.WebAuthnSession, []byte, error) {
+	session, err := s.repo.TakeSession(sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.Ceremony != ceremony {
+		return nil, nil, repository.ErrWebAuthnSessionNotFound
+	}
+	challenge, err := decodeBase64URL(session.Challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, challenge, nil
+}
+
+func (s *WebAuthnService) findUser(filter bson.M) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter["isDeleted"] = bson.M{"$ne": true}
+	var user models.User
+	if err := s.users.FindOne(ctx, filter).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListCredentials kullanıcının kayıtlı passkeylerini döner
+func (s *WebAuthnService) ListCredentials(userID string) ([]models.WebAuthnCredential, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := s.credentials.Find(ctx, bson.M{"userId": objID}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	credentials := []models.WebAuthnCredential{}
+	if err := cursor.All(ctx, &credentials); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// DeleteCredential kullanıcının passkeylerinden birini siler
+func (s *WebAuthnService) DeleteCredential(userID, credentialID string) error {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	objID, err := primitive.ObjectIDFromHex(credentialID)
+	if err != nil {
+		return ErrWebAuthnCredentialNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.credentials.DeleteOne(ctx, bson.M{"_id": objID, "userId": userObjID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
+
+func credentialDescriptors(credentials []models.WebAuthnCredential) []dto.WebAuthnCredentialDescriptor {
+	descriptors := make([]dto.WebAuthnCredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		descriptors = append(descriptors, dto.WebAuthnCredentialDescriptor{
+			Type:       webauthnCredentialType,
+			ID:         credential.CredentialID,
+			Transports: credential.Transports,
+		})
+	}
+	return descriptors
+}
+
+// BeginRegistration oturum açmış kullanıcı için passkey oluşturma seçeneklerini üretir
+func (s *WebAuthnService) BeginRegistration(userID string) (*dto.WebAuthnCreationResponse, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := s.findUser(bson.M{"_id": objID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Aynı kimlik doğrulayıcıda ikinci bir passkey oluşturulmasını engeller
+	existing, err := s.ListCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, challenge, err := s.startSession(&models.WebAuthnSession{Ceremony: webauthnCeremonyCreate, UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("passkey isteği oluşturulamadı: %v", err)
+	}
+
+	parameters := make([]dto.WebAuthnCredentialParameter, 0, len(webauthnSupportedAlgorithms))
+	for _, algorithm := range webauthnSupportedAlgorithms {
+		parameters = append(parameters, dto.WebAuthnCredentialParameter{Type: webauthnCredentialType, Alg: algorithm})
+	}
+	displayName := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if displayName == "" {
+		displayName = user.Username
+	}
+
+	return &dto.WebAuthnCreationResponse{
+		SessionID: sessionID,
+		PublicKey: dto.WebAuthnCreationOptions{
+			RP: dto.WebAuthnRelyingPartyEntity{ID: s.config.RPID, Name: s.config.RPName},
+			// Kullanıcı tanıtıcısı kişisel veri içermemeli; veritabanı ID'si kullanılır
+			User:               dto.WebAuthnUserEntity{ID: base64URL.EncodeToString([]byte(userID)), Name: user.Email, DisplayName: displayName},
+			Challenge:          challenge,
+			PubKeyCredParams:   parameters,
+			Timeout:            s.config.ChallengeTTL.Milliseconds(),
+			ExcludeCredentials: credentialDescriptors(existing),
+			AuthenticatorSelection: dto.WebAuthnAuthenticatorSelection{
+				ResidentKey:      "preferred",
+				UserVerification: s.config.UserVerification,
+			},
+			Attestation: "none",
+		},
+	}, nil
+}
+
+// FinishRegistration kimlik doğrulayıcının yanıtını doğrular ve passkey'i kaydeder
+func (s *WebAuthnService) FinishRegistration(userID string, input *dto.WebAuthnRegisterFinishDto) (*models.WebAuthnCredential, error) {
+	session, challenge, err := s.takeSession(input.SessionID, webauthnCeremonyCreate)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, repository.ErrWebAuthnSessionNotFound
+	}
+	if input.Credential.Type != webauthnCredentialType {
+		return nil, webauthnError("credential tipi %q olmalı", webauthnCredentialType)
+	}
+
+	clientDataJSON, err := decodeBase64URL(input.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, webauthnError("clientDataJSON base64url olmalı")
+	}
+	attestationObject, err := decodeBase64URL(input.Credential.Response.AttestationObject)
+	if err != nil {
+		return nil, webauthnError("attestationObject base64url olmalı")
+	}
+
+	registration, err := s.relyingParty.VerifyRegistration(challenge, clientDataJSON, attestationObject, session.UserVerification == userVerificationNeeded)
+	if err != nil {
+		return nil, err
+	}
+	credentialID := base64URL.EncodeToString(registration.CredentialID)
+	if rawID, err := decodeBase64URL(input.Credential.RawID); err != nil || base64URL.EncodeToString(rawID) != credentialID {
+		return nil, webauthnError("rawId kimlik doğrulayıcı verisiyle eşleşmiyor")
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	if len([]rune(name)) > maxPasskeyNameLength {
+		name = string([]rune(name)[:maxPasskeyNameLength])
+	}
+
+	objID, _ := primitive.ObjectIDFromHex(userID)
+	credential := &models.WebAuthnCredential{
+		UserID:         objID,
+		CredentialID:   credentialID,
+		PublicKey:      registration.PublicKey,
+		Algorithm:      registration.Algorithm,
+		SignCount:      int64(registration.SignCount),
+		Transports:     input.Credential.Response.Transports,
+		AAGUID:         hex.EncodeToString(registration.AAGUID),
+		Name:           name,
+		BackupEligible: registration.BackupEligible,
+		BackupState:    registration.BackupState,
+		CreatedAt:      time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.credentials.InsertOne(ctx, credential)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrWebAuthnCredentialExists
+		}
+		return nil, fmt.Errorf("passkey kaydedilemedi: %v", err)
+	}
+	credential.ID = result.InsertedID.(primitive.ObjectID)
+	return credential, nil
+}
+
+// BeginLogin passkey ile giriş seçeneklerini üretir.
+// E-posta verilmezse veya kayıtlı değilse allowCredentials boş döner ve tarayıcı cihazdaki keşfedilebilir passkeyleri sunar;
+// böylece yanıttan hesabın varlığı anlaşılmaz.
+func (s *WebAuthnService) BeginLogin(email string) (*dto.WebAuthnRequestResponse, error) {
+	allowed := []models.WebAuthnCredential{}
+	if email = strings.TrimSpace(email); email != "" {
+		user, err := s.findUser(bson.M{"email": email})
+		if err != nil && !errors.Is(err, ErrUserNotFound) {
+			return nil, err
+		}
+		if user != nil {
+			if allowed, err = s.ListCredentials(user.ID.Hex()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	allowedIDs := make([]string, 0, len(allowed))
+	for _, credential := range allowed {
+		allowedIDs = append(allowedIDs, credential.CredentialID)
+	}
+	sessionID, challenge, err := s.startSession(&models.WebAuthnSession{Ceremony: webauthnCeremonyGet, AllowedCredentialIDs: allowedIDs})
+	if err != nil {
+		return nil, fmt.Errorf("passkey isteği oluşturulamadı: %v", err)
+	}
+
+	return &dto.WebAuthnRequestResponse{
+		SessionID: sessionID,
+		PublicKey: dto.WebAuthnRequestOptions{
+			Challenge:        challenge,
+			Timeout:          s.config.ChallengeTTL.Milliseconds(),
+			RPID:             s.config.RPID,
+			AllowCredentials: credentialDescriptors(allowed),
+			UserVerification: s.config.UserVerification,
+		},
+	}, nil
+}
+
+// FinishLogin imzayı doğrular, imza sayacını günceller ve kullanıcıyı döner.
+// İkinci değer kimlik doğrulayıcının kullanıcıyı PIN/biyometri ile doğrulayıp doğrulamadığını belirtir.
+func (s *WebAuthnService) FinishLogin(input *dto.WebAuthnLoginFinishDto) (*models.User, bool, error) {
+	session, challenge, err := s.takeSession(input.SessionID, webauthnCeremonyGet)
+	if err != nil {
+		return nil, false, err
+	}
+	if input.Credential.Type != webauthnCredentialType {
+		return nil, false, webauthnError("credential tipi %q olmalı", webauthnCredentialType)
+	}
+
+	rawID, err := decodeBase64URL(input.Credential.RawID)
+	if err != nil {
+		return nil, false, webauthnError("rawId base64url olmalı")
+	}
+	credentialID := base64URL.EncodeToString(rawID)
+	if len(session.AllowedCredentialIDs) > 0 && !slices.Contains(session.AllowedCredentialIDs, credentialID) {
+		return nil, false, webauthnError("bu passkey istenen hesaba ait değil")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var credential models.WebAuthnCredential
+	if err := s.credentials.FindOne(ctx, bson.M{"credentialId": credentialID}).Decode(&credential); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, false, ErrWebAuthnCredentialNotFound
+		}
+		return nil, false, err
+	}
+
+	// Keşfedilebilir passkeyler kullanıcı tanıtıcısını döner; kayıtlı sahiple eşleşmelidir
+	if input.Credential.Response.UserHandle != "" {
+		userHandle, err := decodeBase64URL(input.Credential.Response.UserHandle)
+		if err != nil || string(userHandle) != credential.UserID.Hex() {
+			return nil, false, webauthnError("kullanıcı tanıtıcısı eşleşmiyor")
+		}
+	}
+
+	clientDataJSON, err := decodeBase64URL(input.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, false, webauthnError("clientDataJSON base64url olmalı")
+	}
+	authenticatorData, err := decodeBase64URL(input.Credential.Response.AuthenticatorData)
+	if err != nil {
+		return nil, false, webauthnError("authenticatorData base64url olmalı")
+	}
+	signature, err := decodeBase64URL(input.Credential.Response.Signature)
+	if err != nil {
+		return nil, false, webauthnError("signature base64url olmalı")
+	}
+
+	authData, err := s.relyingParty.VerifyAssertion(challenge, clientDataJSON, authenticatorData, signature, credential.PublicKey,
+		uint32(credential.SignCount), session.UserVerification == userVerificationNeeded)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Sayaç eşzamanlı kullanımda geri gitmesin diye yalnızca okunan değer değişmemişse güncellenir
+	now := time.Now()
+	result, err := s.credentials.UpdateOne(ctx,
+		bson.M{"_id": credential.ID, "signCount": credential.SignCount},
+		bson.M{"$set": bson.M{"signCount": int64(authData.SignCount), "backupState": authData.BackupState(), "lastUsedAt": now}},
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, false, ErrWebAuthnCloned
+	}
+
+	user, err := s.findUser(bson.M{"_id": credential.UserID})
+	if err != nil {
+		return nil, false, err
+	}
+	return user, authData.UserVerified(), nil
+}
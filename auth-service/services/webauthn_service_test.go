@@ -0,0 +1,318 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+func testRelyingParty() *WebAuthnRelyingParty {
+	return &WebAuthnRelyingParty{ID: testRPID, Name: "Example", Origins: []string{testOrigin}}
+}
+
+// cborPair sıralı CBOR haritası için anahtar/değer çiftidir
+type cborPair struct {
+	key, value interface{}
+}
+
+// encodeCBOR testlerin ihtiyaç duyduğu CBOR alt kümesini (tam sayı, bayt/metin dizisi, harita) kodlar
+func encodeCBOR(value interface{}) []byte {
+	header := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n <= 0xff:
+			return []byte{major<<5 | 24, byte(n)}
+		case n <= 0xffff:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		default:
+			return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+		}
+	}
+	switch v := value.(type) {
+	case int:
+		if v < 0 {
+			return header(1, uint64(-1-v))
+		}
+		return header(0, uint64(v))
+	case int64:
+		return encodeCBOR(int(v))
+	case []byte:
+		return append(header(2, uint64(len(v))), v...)
+	case string:
+		return append(header(3, uint64(len(v))), v...)
+	case []cborPair:
+		encoded := header(5, uint64(len(v)))
+		for _, pair := range v {
+			encoded = append(encoded, encodeCBOR(pair.key)...)
+			encoded = append(encoded, encodeCBOR(pair.value)...)
+		}
+		return encoded
+	}
+	panic("desteklenmeyen CBOR değeri")
+}
+
+// softAuthenticator ES256 anahtarıyla çalışan yazılımsal bir kimlik doğrulayıcıdır
+type softAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialID := make([]byte, 16)
+	rand.Read(credentialID)
+	return &softAuthenticator{t: t, key: key, credentialID: credentialID}
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	return encodeCBOR([]cborPair{
+		{1, 2},                 // kty: EC2
+		{3, int(COSEAlgES256)}, // alg
+		{-1, 1},                // crv: P-256
+		{-2, a.key.X.FillBytes(make([]byte, 32))},
+		{-3, a.key.Y.FillBytes(make([]byte, 32))},
+	})
+}
+
+// ceremony tarayıcının ve kimlik doğrulayıcının ürettiği değerleri belirler; testler tek bir alanı bozar
+type ceremony struct {
+	challenge []byte
+	origin    string
+	rpID      string
+	flags     byte
+	signCount uint32
+}
+
+func newCeremony(challenge []byte) ceremony {
+	return ceremony{
+		challenge: challenge,
+		origin:    testOrigin,
+		rpID:      testRPID,
+		flags:     webauthnFlagUserPresent | webauthnFlagUserVerified,
+		signCount: 1,
+	}
+}
+
+func newChallenge(t *testing.T) []byte {
+	t.Helper()
+	challenge := make([]byte, webauthnChallengeBytes)
+	if _, err := rand.Read(challenge); err != nil {
+		t.Fatal(err)
+	}
+	return challenge
+}
+
+func (c ceremony) clientDataJSON(t *testing.T, ceremonyType string) []byte {
+	t.Helper()
+	data, err := json.Marshal(collectedClientData{
+		Type:      ceremonyType,
+		Challenge: base64URL.EncodeToString(c.challenge),
+		Origin:    c.origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func (a *softAuthenticator) authenticatorData(c ceremony, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(c.rpID))
+	flags := c.flags
+	if attested {
+		flags |= webauthnFlagAttestedData
+	}
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, c.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func (a *softAuthenticator) sign(authData, clientDataJSON []byte) []byte {
+	a.t.Helper()
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return signature
+}
+
+// register navigator.credentials.create() yanıtını üretir; format "none" veya self attestation ile "packed" olabilir
+func (a *softAuthenticator) register(t *testing.T, c ceremony, format string) (clientDataJSON, attestationObject []byte) {
+	t.Helper()
+	clientDataJSON = c.clientDataJSON(t, webauthnCeremonyCreate)
+	authData := a.authenticatorData(c, true)
+	statement := []cborPair{}
+	if format == "packed" {
+		statement = []cborPair{{"alg", int(COSEAlgES256)}, {"sig", a.sign(authData, clientDataJSON)}}
+	}
+	attestationObject = encodeCBOR([]cborPair{
+		{"fmt", format},
+		{"attStmt", statement},
+		{"authData", authData},
+	})
+	return clientDataJSON, attestationObject
+}
+
+// assert navigator.credentials.get() yanıtını üretir
+func (a *softAuthenticator) assert(t *testing.T, c ceremony) (clientDataJSON, authData, signature []byte) {
+	t.Helper()
+	clientDataJSON = c.clientDataJSON(t, webauthnCeremonyGet)
+	authData = a.authenticatorData(c, false)
+	return clientDataJSON, authData, a.sign(authData, clientDataJSON)
+}
+
+func TestWebAuthnRegistrationAndAssertion(t *testing.T) {
+	rp := testRelyingParty()
+	for _, format := range []string{"none", "packed"} {
+		t.Run(format, func(t *testing.T) {
+			authenticator := newSoftAuthenticator(t)
+			challenge := newChallenge(t)
+			clientDataJSON, attestationObject := authenticator.register(t, newCeremony(challenge), format)
+
+			registration, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject, true)
+			if err != nil {
+				t.Fatalf("VerifyRegistration: %v", err)
+			}
+			if string(registration.CredentialID) != string(authenticator.credentialID) ||
+				registration.Algorithm != COSEAlgES256 || registration.SignCount != 1 ||
+				!registration.UserVerified || registration.AttestationFormat != format {
+				t.Fatalf("kayıt bilgisi beklendiği gibi değil: %+v", registration)
+			}
+
+			challenge = newChallenge(t)
+			c := newCeremony(challenge)
+			c.signCount = 2
+			clientDataJSON, authData, signature := authenticator.assert(t, c)
+			parsed, err := rp.VerifyAssertion(challenge, clientDataJSON, authData, signature, registration.PublicKey, registration.SignCount, true)
+			if err != nil {
+				t.Fatalf("VerifyAssertion: %v", err)
+			}
+			if parsed.SignCount != 2 || !parsed.UserVerified() {
+				t.Fatalf("doğrulanan authenticatorData beklendiği gibi değil: %+v", parsed)
+			}
+		})
+	}
+}
+
+func TestWebAuthnRegistrationRejectsTamperedCeremony(t *testing.T) {
+	rp := testRelyingParty()
+	tests := []struct {
+		name                    string
+		mutate                  func(c *ceremony)
+		requireUserVerification bool
+	}{
+		{name: "challenge farklı", mutate: func(c *ceremony) { c.challenge = []byte("another-challenge") }},
+		{name: "köken farklı", mutate: func(c *ceremony) { c.origin = "https://evil.example.com" }},
+		{name: "RP ID özeti farklı", mutate: func(c *ceremony) { c.rpID = "evil.example.com" }},
+		{name: "kullanıcı varlığı bayrağı yok", mutate: func(c *ceremony) { c.flags = webauthnFlagUserVerified }},
+		{name: "kullanıcı doğrulaması gerekli ama yok", mutate: func(c *ceremony) { c.flags = webauthnFlagUserPresent }, requireUserVerification: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := newSoftAuthenticator(t)
+			challenge := newChallenge(t)
+			c := newCeremony(challenge)
+			tt.mutate(&c)
+			clientDataJSON, attestationObject := authenticator.register(t, c, "packed")
+
+			if _, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject, tt.requireUserVerification); !errors.Is(err, ErrWebAuthnInvalid) {
+				t.Fatalf("ErrWebAuthnInvalid bekleniyordu, %v döndü", err)
+			}
+		})
+	}
+}
+
+func TestWebAuthnAssertionRejectsTamperedCeremony(t *testing.T) {
+	rp := testRelyingParty()
+	otherAuthenticator := newSoftAuthenticator(t)
+	tests := []struct {
+		name   string
+		mutate func(c *ceremony)
+		// signer verilirse imza kayıtlı anahtar yerine bu kimlik doğrulayıcıyla atılır
+		signer *softAuthenticator
+	}{
+		{name: "challenge farklı", mutate: func(c *ceremony) { c.challenge = []byte("another-challenge") }},
+		{name: "köken farklı", mutate: func(c *ceremony) { c.origin = "https://evil.example.com" }},
+		{name: "RP ID özeti farklı", mutate: func(c *ceremony) { c.rpID = "evil.example.com" }},
+		{name: "kullanıcı varlığı bayrağı yok", mutate: func(c *ceremony) { c.flags = webauthnFlagUserVerified }},
+		{name: "imza başka anahtarla atılmış", mutate: func(c *ceremony) {}, signer: otherAuthenticator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := newSoftAuthenticator(t)
+			challenge := newChallenge(t)
+			c := newCeremony(challenge)
+			tt.mutate(&c)
+			signer := authenticator
+			if tt.signer != nil {
+				signer = tt.signer
+			}
+			clientDataJSON, authData, signature := signer.assert(t, c)
+
+			if _, err := rp.VerifyAssertion(challenge, clientDataJSON, authData, signature, authenticator.coseKey(), 0, false); !errors.Is(err, ErrWebAuthnInvalid) {
+				t.Fatalf("ErrWebAuthnInvalid bekleniyordu, %v döndü", err)
+			}
+		})
+	}
+}
+
+func TestWebAuthnAssertionSignCount(t *testing.T) {
+	rp := testRelyingParty()
+	authenticator := newSoftAuthenticator(t)
+	tests := []struct {
+		name       string
+		stored     uint32
+		received   uint32
+		wantCloned bool
+	}{
+		{name: "sayaç artmış", stored: 5, received: 6},
+		{name: "sayaç aynı kalmış", stored: 5, received: 5, wantCloned: true},
+		{name: "sayaç gerilemiş", stored: 5, received: 3, wantCloned: true},
+		{name: "sayaç desteklenmiyor", stored: 0, received: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge := newChallenge(t)
+			c := newCeremony(challenge)
+			c.signCount = tt.received
+			clientDataJSON, authData, signature := authenticator.assert(t, c)
+
+			_, err := rp.VerifyAssertion(challenge, clientDataJSON, authData, signature, authenticator.coseKey(), tt.stored, false)
+			if tt.wantCloned {
+				if !errors.Is(err, ErrWebAuthnCloned) {
+					t.Fatalf("ErrWebAuthnCloned bekleniyordu, %v döndü", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyAssertion: %v", err)
+			}
+		})
+	}
+}
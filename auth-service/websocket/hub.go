@@ -2,31 +2,59 @@
 package websocket
 
 import (
+	"encoding/json"
 	"log"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
 	"github.com/gorilla/websocket"
 )
 
+const writeWait = 10 * time.Second
+
+// Client bir kullanıcının tek bir websocket bağlantısıdır; kullanıcı birden fazla sekme
+// veya cihazdan bağlanabilir
 type Client struct {
+	ID     string // Presence kaydındaki bağlantı kimliği
 	UserID string
 	Conn   *websocket.Conn
+
+	writeMu sync.Mutex
+}
+
+// WriteJSON bağlantıya mesaj yazar; gorilla/websocket aynı anda tek yazıcıya izin verdiği için kilitlenir
+func (c *Client) WriteJSON(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.Conn.WriteJSON(v)
+}
+
+// WritePing bağlantıya ping gönderir; yanıt olarak gelen pong kalp atışı sayılır
+func (c *Client) WritePing() error {
+	return c.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
 }
 
 type Hub struct {
-	Clients    map[string]*Client
+	Clients    map[string]map[*Client]bool
 	Register   chan *Client
 	Unregister chan *Client
 	Mutex      sync.RWMutex
+
+	// Watchers takip edilen kullanıcı ID'sinden onun durumunu takip eden bağlantılara eşlemedir
+	Watchers map[string]map[*Client]bool
+	// watching her bağlantının takip ettiği kullanıcılardır; bağlantı kapanınca aboneliklerini silmek için tutulur
+	watching map[*Client]map[string]bool
 }
 
 func NewHub() *Hub {
 	return &Hub{
-		Clients:    make(map[string]*Client),
+		Clients:    make(map[string]map[*Client]bool),
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
+		Watchers:   make(map[string]map[*Client]bool),
+		watching:   make(map[*Client]map[string]bool),
 	}
 }
 
@@ -35,19 +63,124 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.Register:
 			h.Mutex.Lock()
-			h.Clients[client.UserID] = client
+			if h.Clients[client.UserID] == nil {
+				h.Clients[client.UserID] = make(map[*Client]bool)
+			}
+			h.Clients[client.UserID][client] = true
 			h.Mutex.Unlock()
 		case client := <-h.Unregister:
 			h.Mutex.Lock()
-			delete(h.Clients, client.UserID)
+			if clients, ok := h.Clients[client.UserID]; ok {
+				delete(clients, client)
+				if len(clients) == 0 {
+					delete(h.Clients, client.UserID)
+				}
+			}
+			h.unwatchLocked(client, nil)
 			h.Mutex.Unlock()
 			client.Conn.Close()
 		}
 	}
 }
 
+// Watch bağlantıyı verilen kullanıcıların durum değişikliklerine abone eder
+func (h *Hub) Watch(client *Client, userIDs []string) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	if h.watching[client] == nil {
+		h.watching[client] = make(map[string]bool)
+	}
+	for _, userID := range userIDs {
+		if h.Watchers[userID] == nil {
+			h.Watchers[userID] = make(map[*Client]bool)
+		}
+		h.Watchers[userID][client] = true
+		h.watching[client][userID] = true
+	}
+}
+
+// Unwatch bağlantının verilen kullanıcılara aboneliğini kaldırır; userIDs boşsa tüm abonelikleri kaldırır
+func (h *Hub) Unwatch(client *Client, userIDs []string) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+	h.unwatchLocked(client, userIDs)
+}
+
+// WatchCount bağlantının takip ettiği kullanıcı sayısını döner
+func (h *Hub) WatchCount(client *Client) int {
+	h.Mutex.RLock()
+	defer h.Mutex.RUnlock()
+	return len(h.watching[client])
+}
+
+func (h *Hub) unwatchLocked(client *Client, userIDs []string) {
+	if len(userIDs) == 0 {
+		for userID := range h.watching[client] {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	for _, userID := range userIDs {
+		if watchers, ok := h.Watchers[userID]; ok {
+			delete(watchers, client)
+			if len(watchers) == 0 {
+				delete(h.Watchers, userID)
+			}
+		}
+		delete(h.watching[client], userID)
+	}
+	if len(h.watching[client]) == 0 {
+		delete(h.watching, client)
+	}
+}
+
+// SendToUser mesajı kullanıcının bu örnekteki tüm bağlantılarına gönderir
+func (h *Hub) SendToUser(userID string, message interface{}) {
+	h.Mutex.RLock()
+	clients := make([]*Client, 0, len(h.Clients[userID]))
+	for client := range h.Clients[userID] {
+		clients = append(clients, client)
+	}
+	h.Mutex.RUnlock()
+	send(clients, message)
+}
+
+// SendToWatchers mesajı kullanıcının durumunu takip eden bu örnekteki tüm bağlantılara gönderir
+func (h *Hub) SendToWatchers(userID string, message interface{}) {
+	h.Mutex.RLock()
+	clients := make([]*Client, 0, len(h.Watchers[userID]))
+	for client := range h.Watchers[userID] {
+		clients = append(clients, client)
+	}
+	h.Mutex.RUnlock()
+	send(clients, message)
+}
+
+func send(clients []*Client, message interface{}) {
+	for _, client := range clients {
+		if err := client.WriteJSON(message); err != nil {
+			log.Println("WebSocket write error:", err)
+		}
+	}
+}
+
+// statusUpdateEvent istemcilere gönderilen durum değişikliği mesajıdır
+func statusUpdateEvent(presence *redisrepo.Presence) map[string]interface{} {
+	return map[string]interface{}{
+		"event":               "status_update",
+		"userID":              presence.UserID,
+		"status":              presence.Status,
+		"statusText":          presence.StatusText,
+		"statusTextExpiresAt": presence.StatusTextExpiresAt,
+		"lastSeenAt":          presence.LastSeenAt,
+	}
+}
+
+// ListenRedisStatus tüm servis örneklerinden yayınlanan durum değişikliklerini dinler; değişikliği
+// kullanıcının kendi cihazlarına olduğu gibi, onu takip edenlere ise gizlilik uygulanmış haliyle iletir.
+// Görünür durum değişmediyse (ör. görünmez kullanıcı bağlandıysa) takip edenlere bir şey gönderilmez.
 func (h *Hub) ListenRedisStatus(redisRepo *redisrepo.RedisRepository) {
-	pubsub := redisRepo.Client.Subscribe("user_status")
+	pubsub := redisRepo.Client.Subscribe(redisrepo.PresenceChannel)
 	defer pubsub.Close()
 
 	for {
@@ -57,20 +190,13 @@ func (h *Hub) ListenRedisStatus(redisRepo *redisrepo.RedisRepository) {
 			continue
 		}
 
-		parts := strings.Split(msg.Payload, ":")
-		if len(parts) != 2 {
+		var event redisrepo.PresenceEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil || event.Presence == nil {
 			continue
 		}
-		userID, status := parts[0], parts[1]
-
-		h.Mutex.RLock()
-		if client, ok := h.Clients[userID]; ok {
-			client.Conn.WriteJSON(map[string]string{
-				"event":  "status_update",
-				"userID": userID,
-				"status": status,
-			})
+		h.SendToUser(event.Presence.UserID, statusUpdateEvent(event.Presence))
+		if event.PublicChanged {
+			h.SendToWatchers(event.Presence.UserID, statusUpdateEvent(event.Presence.Public()))
 		}
-		h.Mutex.RUnlock()
 	}
 }
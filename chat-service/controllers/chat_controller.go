@@ -8,6 +8,7 @@ import (
 	_ "github.com/MKMuhammetKaradag/go-microservice/chat-service/docs"
 	"github.com/MKMuhammetKaradag/go-microservice/chat-service/dto"
 	"github.com/MKMuhammetKaradag/go-microservice/chat-service/services"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
@@ -38,7 +39,7 @@ func NewChatController(rabbitMQ *messaging.RabbitMQ, sessionRepo *redisrepo.Redi
 
 type ChatResponse struct {
 	Message string `json:"message"`
-	chat    string `json:"chat"`
+	Chat    string `json:"chat"`
 }
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -206,7 +207,8 @@ func (ctrl *ChatController) AddParticipants(w http.ResponseWriter, r *http.Reque
 		respondWithError(w, http.StatusBadRequest, "Geçersiz veri")
 		return
 	}
-	chat, err := ctrl.chatService.AddParticipants(userID, &input)
+	chat, err := ctrl.chatService.AddParticipants(userID, &input, middlewares.HasPermission(r, models.PermChatManageAny))
+	recordParticipantsChange(r, audit.ChatParticipantsAdded, input.ChatID, input.Participants, err)
 	if err != nil {
 		respondWithError(w, http.StatusConflict, err.Error())
 		return
@@ -237,7 +239,8 @@ func (ctrl *ChatController) RemoveParticipants(w http.ResponseWriter, r *http.Re
 		respondWithError(w, http.StatusBadRequest, "Geçersiz veri")
 		return
 	}
-	chat, err := ctrl.chatService.RemoveParticipants(userID, &input)
+	chat, err := ctrl.chatService.RemoveParticipants(userID, &input, middlewares.HasPermission(r, models.PermChatManageAny))
+	recordParticipantsChange(r, audit.ChatParticipantsRemoved, input.ChatID, input.Participants, err)
 	if err != nil {
 		respondWithError(w, http.StatusConflict, err.Error())
 		return
@@ -249,6 +252,26 @@ func (ctrl *ChatController) RemoveParticipants(w http.ResponseWriter, r *http.Re
 		"chat":    chat,
 	})
 }
+
+// recordParticipantsChange sohbet katılımcılarının değiştirilmesini denetim kaydına yazar; işlemi yapanın
+// tüm sohbetleri yönetme yetkisi olup olmadığı da kaydedilir
+func recordParticipantsChange(r *http.Request, eventType audit.EventType, chatID primitive.ObjectID, participants []primitive.ObjectID, err error) {
+	event := audit.Event{
+		Type:       eventType,
+		TargetType: audit.TargetChat,
+		TargetID:   chatID.Hex(),
+		Details: map[string]interface{}{
+			"participants": participants,
+			"manageAny":    middlewares.HasPermission(r, models.PermChatManageAny),
+		},
+	}
+	if err != nil {
+		event.Result = audit.ResultFailure
+		event.Reason = err.Error()
+	}
+	audit.Record(r, event)
+}
+
 func (ctrl *ChatController) LeaveChat(w http.ResponseWriter, r *http.Request) {
 	userData, ok := middlewares.GetUserData(r)
 	// fmt.Println("hello", userData)
@@ -316,3 +339,45 @@ func (ctrl *ChatController) GetChatMessages(w http.ResponseWriter, r *http.Reque
 		"messsages": messages,
 	})
 }
+
+// @Summary      Mesaj Sil (Yönetici)
+// @Description  chat:delete-any yetkisine sahip kullanıcıların herhangi bir mesajı silmesini sağlar
+// @Tags         Chat
+// @Produce      json
+// @Param        messageID path string true "Mesaj ID"
+// @Success      200  {object}  ChatResponse
+// @Failure      403  {object}  ErrorResponse
+// @Router       /chat/admin/messages/{messageID} [delete]
+func (ctrl *ChatController) DeleteAnyMessage(w http.ResponseWriter, r *http.Request) {
+	messageID := chi.URLParam(r, "messageID")
+	if err := ctrl.chatService.DeleteAnyMessage(messageID); err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"message": "mesaj silindi",
+	})
+}
+
+// @Summary      Sohbet Arkadaşları (iç)
+// @Description  Kullanıcının en az bir sohbeti paylaştığı kullanıcıların ID'lerini döner; auth-service durum aboneliklerinde kullanır
+// @Tags         Internal
+// @Produce      json
+// @Param        X-Internal-Secret  header  string  true  "Servisler arası gizli anahtar"
+// @Param        userID             path    string  true  "Kullanıcı ID"
+// @Success      200  {object}  map[string][]string
+// @Failure      400  {object}  ErrorResponse
+// @Router       /internal/users/{userID}/coparticipants [get]
+func (ctrl *ChatController) GetCoParticipants(w http.ResponseWriter, r *http.Request) {
+	userIDs, err := ctrl.chatService.GetCoParticipants(chi.URLParam(r, "userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	render.JSON(w, r, map[string]interface{}{
+		"userIds": userIDs,
+	})
+}
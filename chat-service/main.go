@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -32,7 +34,7 @@ func main() {
 	// fmt.Println(a)
 
 	config := messaging.NewDefaultConfig()
-	config.RetryTypes = []string{"user_created"}
+	config.RetryTypes = []string{"user_created", "user_email_changed", "user_banned", "user_suspended", "user_reinstated", "user_deleted", "data_export_requested"}
 	redisRepo := redisrepo.NewRedisRepository(database.RedisClient) // Redis repository oluşturuldu
 	var err error
 	rabbitMQ, err := messaging.NewRabbitMQ(config, messaging.ChatService)
@@ -47,6 +49,18 @@ func main() {
 			fmt.Println(msg)
 			return handleUserCreated(msg)
 		}
+		if msg.Type == "user_email_changed" {
+			return handleUserEmailChanged(msg)
+		}
+		if msg.Type == "user_banned" || msg.Type == "user_suspended" || msg.Type == "user_reinstated" {
+			return handleUserRestrictionChanged(msg)
+		}
+		if msg.Type == "user_deleted" {
+			return handleUserDeleted(rabbitMQ, msg)
+		}
+		if msg.Type == "data_export_requested" {
+			return handleDataExportRequested(rabbitMQ, msg)
+		}
 		return nil
 	})
 	port := 8083
@@ -96,3 +110,131 @@ func handleUserCreated(msg messaging.Message) error {
 	log.Printf("Yeni kullanıcı oluşturuldu: %s", user.Email)
 	return nil
 }
+
+func handleUserEmailChanged(msg messaging.Message) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("geçersiz mesaj formatı")
+	}
+
+	userID, _ := data["user_id"].(string)
+	email, emailOk := data["email"].(string)
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil || !emailOk {
+		return fmt.Errorf("geçersiz e-posta değişikliği mesajı: %+v", data)
+	}
+
+	if err := repository.UpdateUserEmail(objectID, email); err != nil {
+		return fmt.Errorf("kullanıcı e-postası güncellenemedi: %v", err)
+	}
+
+	log.Printf("Kullanıcı e-postası güncellendi: %s", userID)
+	return nil
+}
+
+// handleUserRestrictionChanged auth-service'te hesaba uygulanan kısıtlamayı kullanıcı kopyasına yansıtır;
+// yasaklı kullanıcıların mesajları kısıtlama sürdüğü sürece listelenmez
+func handleUserRestrictionChanged(msg messaging.Message) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("geçersiz mesaj formatı")
+	}
+
+	userID, _ := data["user_id"].(string)
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("geçersiz kısıtlama mesajı: %+v", data)
+	}
+
+	var restriction *models.AccountRestriction
+	if msg.Type != "user_reinstated" {
+		raw, err := json.Marshal(data["restriction"])
+		if err != nil {
+			return fmt.Errorf("geçersiz kısıtlama mesajı: %v", err)
+		}
+		if err := json.Unmarshal(raw, &restriction); err != nil || restriction == nil {
+			return fmt.Errorf("geçersiz kısıtlama mesajı: %+v", data)
+		}
+	}
+
+	if err := repository.UpdateUserRestriction(objectID, restriction); err != nil {
+		return fmt.Errorf("kullanıcı kısıtlaması güncellenemedi: %v", err)
+	}
+
+	log.Printf("Kullanıcı kısıtlaması güncellendi (%s): %s", msg.Type, userID)
+	return nil
+}
+
+// handleUserDeleted silinen hesabın sohbet verisini temizler ve auth-service'e onay gönderir;
+// mesaj tekrar gelirse de aynı onay gönderilir
+func handleUserDeleted(rabbitMQ *messaging.RabbitMQ, msg messaging.Message) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("geçersiz mesaj formatı")
+	}
+
+	userID, _ := data["user_id"].(string)
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("geçersiz kullanıcı silme mesajı: %+v", data)
+	}
+
+	if err := repository.EraseUser(objectID); err != nil {
+		return fmt.Errorf("kullanıcının sohbet verisi silinemedi: %v", err)
+	}
+
+	ack := messaging.Message{
+		Type:      "user_deletion_acknowledged",
+		ToService: messaging.AuthService,
+		Data: map[string]interface{}{
+			"user_id": userID,
+			"service": string(messaging.ChatService),
+		},
+	}
+	if err := rabbitMQ.PublishMessage(context.Background(), ack); err != nil {
+		return fmt.Errorf("silme onayı gönderilemedi: %v", err)
+	}
+
+	log.Printf("Kullanıcının sohbet verisi silindi: %s", userID)
+	return nil
+}
+
+// handleDataExportRequested kullanıcının sohbetlerini ve mesajlarını dışa aktarma parçası olarak auth-service'e gönderir
+func handleDataExportRequested(rabbitMQ *messaging.RabbitMQ, msg messaging.Message) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("geçersiz mesaj formatı")
+	}
+
+	exportID, _ := data["export_id"].(string)
+	userID, _ := data["user_id"].(string)
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil || exportID == "" {
+		return fmt.Errorf("geçersiz dışa aktarma mesajı: %+v", data)
+	}
+
+	chats, messages, err := repository.ExportUserData(objectID)
+	if err != nil {
+		return err
+	}
+
+	part := messaging.Message{
+		Type:      "data_export_part",
+		ToService: messaging.AuthService,
+		Data: map[string]interface{}{
+			"export_id": exportID,
+			"user_id":   userID,
+			"service":   string(messaging.ChatService),
+			"data": map[string]interface{}{
+				"chats":    chats,
+				"messages": messages,
+			},
+		},
+	}
+	if err := rabbitMQ.PublishMessage(context.Background(), part); err != nil {
+		return fmt.Errorf("dışa aktarma parçası gönderilemedi: %v", err)
+	}
+
+	log.Printf("Dışa aktarma parçası gönderildi: %s", exportID)
+	return nil
+}
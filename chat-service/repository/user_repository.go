@@ -7,7 +7,10 @@ import (
 
 	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var userCollection *mongo.Collection
@@ -22,3 +25,102 @@ func CreateUser(user *models.User) error {
 	return err
 }
 
+// UpdateUserEmail auth-service'te değişen e-posta adresini kullanıcı kopyasına yansıtır
+func UpdateUserEmail(userID primitive.ObjectID, email string) error {
+	userCollection = database.MongoClient.Database("chatDB").Collection("users")
+	_, err := userCollection.UpdateOne(context.Background(),
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"email": email, "updatedAt": time.Now()}},
+	)
+	return err
+}
+
+// UpdateUserRestriction auth-service'te hesaba uygulanan kısıtlamayı kullanıcı kopyasına yansıtır;
+// restriction nil ise kısıtlama kaldırılır
+func UpdateUserRestriction(userID primitive.ObjectID, restriction *models.AccountRestriction) error {
+	userCollection = database.MongoClient.Database("chatDB").Collection("users")
+	update := bson.M{"$unset": bson.M{"restriction": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	if restriction != nil {
+		update = bson.M{"$set": bson.M{"restriction": restriction, "updatedAt": time.Now()}}
+	}
+	_, err := userCollection.UpdateOne(context.Background(), bson.M{"_id": userID}, update)
+	return err
+}
+
+// deletedSender silinen kullanıcıların mesajlarında gönderici olarak kullanılır; hiçbir kullanıcıyla eşleşmediği
+// için mesajlar listelenirken gönderici bilgisi boş döner
+var deletedSender = primitive.NilObjectID
+
+// EraseUser auth-service'te silinen hesabın chatDB'deki izlerini temizler: mesajlarının göndericisi
+// anonimleştirilir, kullanıcı sohbetlerin katılımcı ve yönetici listelerinden çıkarılır, katılımcısı
+// kalmayan sohbetler mesajlarıyla birlikte silinir ve kullanıcı kopyası kaldırılır. Tekrar çağrılması güvenlidir.
+func EraseUser(userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := database.MongoClient.Database("chatDB")
+	chats := db.Collection("chats")
+	messages := db.Collection("messages")
+	now := time.Now()
+
+	if _, err := messages.UpdateMany(ctx,
+		bson.M{"sender": userID},
+		bson.M{"$set": bson.M{"sender": deletedSender, "updatedAt": now}},
+	); err != nil {
+		return fmt.Errorf("mesajlar anonimleştirilemedi: %v", err)
+	}
+
+	if _, err := chats.UpdateMany(ctx,
+		bson.M{"$or": []bson.M{{"participants": userID}, {"admins": userID}}},
+		bson.M{"$pull": bson.M{"participants": userID, "admins": userID}, "$set": bson.M{"updatedAt": now}},
+	); err != nil {
+		return fmt.Errorf("kullanıcı sohbetlerden çıkarılamadı: %v", err)
+	}
+
+	emptyChatIDs, err := chats.Distinct(ctx, "_id", bson.M{"participants": bson.M{"$size": 0}})
+	if err != nil {
+		return fmt.Errorf("boş sohbetler bulunamadı: %v", err)
+	}
+	if len(emptyChatIDs) > 0 {
+		if _, err := messages.DeleteMany(ctx, bson.M{"chat": bson.M{"$in": emptyChatIDs}}); err != nil {
+			return fmt.Errorf("boş sohbetlerin mesajları silinemedi: %v", err)
+		}
+		if _, err := chats.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": emptyChatIDs}}); err != nil {
+			return fmt.Errorf("boş sohbetler silinemedi: %v", err)
+		}
+	}
+
+	userCollection = db.Collection("users")
+	if _, err := userCollection.DeleteOne(ctx, bson.M{"_id": userID}); err != nil {
+		return fmt.Errorf("kullanıcı kopyası silinemedi: %v", err)
+	}
+	return nil
+}
+
+// ExportUserData kişisel veri dışa aktarması için kullanıcının katıldığı sohbetleri ve gönderdiği mesajları döner
+func ExportUserData(userID primitive.ObjectID) ([]bson.M, []bson.M, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := database.MongoClient.Database("chatDB")
+	chats := []bson.M{}
+	cursor, err := db.Collection("chats").Find(ctx, bson.M{"participants": userID},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, nil, fmt.Errorf("sohbetler okunamadı: %v", err)
+	}
+	if err := cursor.All(ctx, &chats); err != nil {
+		return nil, nil, fmt.Errorf("sohbetler okunamadı: %v", err)
+	}
+
+	messages := []bson.M{}
+	cursor, err = db.Collection("messages").Find(ctx, bson.M{"sender": userID},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, nil, fmt.Errorf("mesajlar okunamadı: %v", err)
+	}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, nil, fmt.Errorf("mesajlar okunamadı: %v", err)
+	}
+	return chats, messages, nil
+}
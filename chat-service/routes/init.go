@@ -2,14 +2,19 @@ package routes
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/MKMuhammetKaradag/go-microservice/chat-service/controllers"
 	"github.com/MKMuhammetKaradag/go-microservice/chat-service/repository"
 	"github.com/MKMuhammetKaradag/go-microservice/chat-service/websocket"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/audit"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/authclient"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus"
@@ -75,15 +80,35 @@ func (rw *responseWriter) WriteHeader(code int) {
 }
 func CreateServer(rabbitMQ *messaging.RabbitMQ, chatRepo *repository.ChatRepository, sessionRepo *redisrepo.RedisRepository) *chi.Mux {
 	chatController := controllers.NewChatController(rabbitMQ, sessionRepo)
-	authMiddleware := middlewares.NewAuthMiddleware(sessionRepo)
+	// AUTH_MODE=introspection ile oturumlar Redis yerine auth-service'e sorulur
+	authMiddleware := middlewares.NewAuthMiddleware(authclient.NewClient(sessionRepo, authclient.ConfigFromEnv())).
+		WithImpersonationAudit(sessionRepo, "chat")
+	authorizer := middlewares.NewAuthorizer(middlewares.PermissionsFilePath())
+	rateLimiter := middlewares.NewRateLimiter(sessionRepo, "chat")
+	go authorizer.WatchConfig(10*time.Second, nil)
 	hub := websocket.NewHub()
 	go hub.Run()
 	go hub.ListenRedisSendMessage(sessionRepo)
 	wsController := controllers.NewWebSocketController(hub, chatRepo, sessionRepo)
+	auditStore := audit.NewStore()
+	if err := auditStore.EnsureIndexes(); err != nil {
+		log.Printf("Denetim kaydı indeksleri oluşturulamadı: %v", err)
+	}
 	r := chi.NewRouter()
 	r.Use(middlewares.Logger)
 	r.Use(PrometheusMiddleware)
+	r.Use(authorizer.Middleware)
+	r.Use(audit.NewRecorder(auditStore, "chat").Middleware)
 	r.Mount("/metrics", promhttp.Handler())
+	// İç endpointler yalnızca servisler arası kullanım içindir, nginx tarafından dışarı açılmaz
+	if secret := os.Getenv("INTERNAL_API_SECRET"); secret != "" {
+		r.Route("/internal", func(r chi.Router) {
+			r.Use(middlewares.RequireInternalSecret(secret))
+			r.Get("/users/{userID}/coparticipants", chatController.GetCoParticipants)
+		})
+	} else {
+		log.Println("INTERNAL_API_SECRET tanımlı değil, iç endpointler kapalı")
+	}
 	r.Route("/chat", func(r chi.Router) {
 		r.Get("/chat", func(w http.ResponseWriter, r *http.Request) {
 
@@ -101,13 +126,20 @@ func CreateServer(rabbitMQ *messaging.RabbitMQ, chatRepo *repository.ChatReposit
 			protectedRouter.Post("/create", chatController.CreateChat)
 			protectedRouter.Get("/{chatID}", chatController.CreateChat)
 			protectedRouter.Get("/myChats", chatController.GetMyChats)
-			protectedRouter.Post("/message/create", chatController.SendMessage)
+			protectedRouter.With(rateLimiter.Limit(middlewares.RateLimitRule{
+				Name:      "send_message",
+				Algorithm: middlewares.TokenBucket,
+				Limit:     20,
+				Window:    time.Minute,
+				KeyFunc:   middlewares.KeyByUserOrIP,
+			})).Post("/message/create", chatController.SendMessage)
 			protectedRouter.Post("/addParticipants", chatController.AddParticipants)
 			protectedRouter.Post("/removeParticipants", chatController.RemoveParticipants)
 			protectedRouter.Post("/leave/{chatID}", chatController.LeaveChat)
 			protectedRouter.Get("/chatDetail", chatController.GetChatUsers)
 			protectedRouter.Get("/chatlisten/{chatID}", wsController.HandleWebSocket)
 			protectedRouter.Get("/messages", chatController.GetChatMessages)
+			protectedRouter.With(middlewares.RequirePermission(models.PermChatDeleteAny)).Delete("/admin/messages/{messageID}", chatController.DeleteAnyMessage)
 		})
 	})
 
@@ -258,17 +258,26 @@ func (s *ChatService) GetMyChatsWithUsersAggregation(userID string) (*dto.ChatWi
 	return chat, nil
 }
 
-func (s *ChatService) AddParticipants(userID string, input *dto.ChatAddParticipants) (*string, error) {
+// chatAdminFilter sohbet yöneticisi olmayan kullanıcılar için admin şartı ekler;
+// chat:manage-any yetkisine sahip kullanıcılar tüm sohbetleri yönetebilir
+func chatAdminFilter(chatID, userObjID primitive.ObjectID, canManageAny bool) bson.M {
+	if canManageAny {
+		return bson.M{"_id": chatID}
+	}
+	return bson.M{
+		"_id":    chatID,
+		"admins": userObjID,
+	}
+}
+
+func (s *ChatService) AddParticipants(userID string, input *dto.ChatAddParticipants, canManageAny bool) (*string, error) {
 
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, fmt.Errorf("geçersiz userID: %v", err)
 	}
 	fmt.Println(input.ChatID, userID, input.Participants)
-	chatFilter := bson.M{
-		"_id":    input.ChatID,
-		"admins": userObjID,
-	}
+	chatFilter := chatAdminFilter(input.ChatID, userObjID, canManageAny)
 
 	var existingChat struct {
 		Participants []primitive.ObjectID `bson:"participants"`
@@ -340,17 +349,14 @@ func (s *ChatService) AddParticipants(userID string, input *dto.ChatAddParticipa
 
 	return &successMsg, nil
 }
-func (s *ChatService) RemoveParticipants(userID string, input *dto.ChatRemoveParticipants) (*string, error) {
+func (s *ChatService) RemoveParticipants(userID string, input *dto.ChatRemoveParticipants, canManageAny bool) (*string, error) {
 
 	userObjID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, fmt.Errorf("geçersiz userID: %v", err)
 	}
 
-	chatFilter := bson.M{
-		"_id":    input.ChatID,
-		"admins": userObjID,
-	}
+	chatFilter := chatAdminFilter(input.ChatID, userObjID, canManageAny)
 
 	var existingChat struct {
 		Participants []primitive.ObjectID `bson:"participants"`
@@ -549,7 +555,8 @@ func (s *ChatService) GetChatMessages(userID string, input *dto.GetChatMessagesI
 
 	pipeline := mongo.Pipeline{
 
-		bson.D{{Key: "$match", Value: bson.M{"chat": input.ChatID}}},
+		// Moderatörlerin sildiği mesajlar katılımcılara gösterilmez
+		bson.D{{Key: "$match", Value: bson.M{"chat": input.ChatID, "isDeleted": bson.M{"$ne": true}}}},
 
 		bson.D{{Key: "$lookup", Value: bson.M{
 			"from":         "users",
@@ -558,7 +565,12 @@ func (s *ChatService) GetChatMessages(userID string, input *dto.GetChatMessagesI
 			"as":           "senderDetail",
 		}}},
 		bson.D{{Key: "$unwind", Value: bson.M{"path": "$senderDetail", "preserveNullAndEmptyArrays": true}}}, // senderDetail'i obje haline getir
-		bson.D{{Key: "$sort", Value: bson.M{"createdAt": -1}}},                                               // Tarihe göre sıralama (-1: DESC, 1: ASC)
+		// Yasağı süren kullanıcıların mesajları gizlenir; yasak kalkınca veya süresi dolunca yeniden görünür
+		bson.D{{Key: "$match", Value: bson.M{"$or": []bson.M{
+			{"senderDetail.restriction.status": bson.M{"$ne": models.RestrictionBanned}},
+			{"senderDetail.restriction.expiresAt": bson.M{"$lte": time.Now()}},
+		}}}},
+		bson.D{{Key: "$sort", Value: bson.M{"createdAt": -1}}}, // Tarihe göre sıralama (-1: DESC, 1: ASC)
 
 		bson.D{{Key: "$skip", Value: skip}}, // Belirtilen sayıda belgeyi atla
 
@@ -597,3 +609,51 @@ func (s *ChatService) GetChatMessages(userID string, input *dto.GetChatMessagesI
 	return &results, nil
 
 }
+
+// DeleteAnyMessage herhangi bir sohbetteki mesajı yumuşak silme ile siler (chat:delete-any yetkisi gerekir)
+func (s *ChatService) DeleteAnyMessage(messageID string) error {
+	messageObjID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return fmt.Errorf("geçersiz messageID: %v", err)
+	}
+
+	now := time.Now()
+	result, err := s.messageCollection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": messageObjID, "isDeleted": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"isDeleted": true, "deletedAt": now, "updatedAt": now}},
+	)
+	if err != nil {
+		return fmt.Errorf("mesaj silinemedi: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("mesaj bulunamadı")
+	}
+	return nil
+}
+
+// GetCoParticipants kullanıcının en az bir sohbeti paylaştığı diğer kullanıcıların ID'lerini döner
+func (s *ChatService) GetCoParticipants(userID string) ([]string, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("geçersiz userID: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	values, err := s.chatCollection.Distinct(ctx, "participants", bson.M{"participants": userObjID})
+	if err != nil {
+		return nil, fmt.Errorf("veritabanı hatası: %v", err)
+	}
+
+	userIDs := []string{}
+	for _, value := range values {
+		participantID, ok := value.(primitive.ObjectID)
+		if !ok || participantID == userObjID {
+			continue
+		}
+		userIDs = append(userIDs, participantID.Hex())
+	}
+	return userIDs, nil
+}
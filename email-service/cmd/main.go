@@ -8,6 +8,7 @@ import (
 	"net/smtp"
 	"os"
 	"text/template"
+	"time"
 
 	"github.com/MKMuhammetKaradag/go-microservice/email-service/routes"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/messaging"
@@ -17,11 +18,24 @@ import (
 type EmailData struct {
 	ActivationCode string
 	UserName       string
+	LockedUntil    string
+	NewEmail       string
+	LoginCode      string
+	LoginURL       string
+	ResetURL       string
+	ScheduledFor   string
+	DownloadURL    string
+	ExpiresAt      string
+	Device         string
+	IP             string
+	LoginAt        string
+	RevokeURL      string
+	InviteURL      string
 }
 
 func main() {
 	config := messaging.NewDefaultConfig()
-	config.RetryTypes = []string{"active_user", "forgot_password"}
+	config.RetryTypes = []string{"active_user", "forgot_password", "user_locked", "verify_email_change", "user_email_changed", "magic_login", "account_deletion_scheduled", "account_deleted", "data_export_ready", "new_device_login", "user_invited"}
 	rabbit, err := messaging.NewRabbitMQ(config, messaging.EmailService)
 	if err != nil {
 		log.Fatal("RabbitMQ bağlantı hatası:", err)
@@ -30,9 +44,11 @@ func main() {
 
 	// Mesaj dinleyiciyi başlat
 	err = rabbit.ConsumeMessages(func(msg messaging.Message) error {
-		if msg.Type == "active_user" || msg.Type == "forgot_password" {
-			fmt.Println("active_user  or forgot_password  geldi")
-			fmt.Println(msg)
+		switch msg.Type {
+		case "active_user", "forgot_password", "user_locked", "verify_email_change", "user_email_changed", "magic_login",
+			"account_deletion_scheduled", "account_deleted", "data_export_ready", "new_device_login", "user_invited":
+			fmt.Println(msg.Type, " geldi")
+			fmt.Println(msg.Redacted())
 			// return nil
 			return handleSendEmail(msg)
 		}
@@ -85,6 +101,17 @@ func sendEmail(subject, body, recipient string) error {
 	}
 	return nil
 }
+
+// formatDate mesajdaki RFC3339 biçimindeki zamanı e-postada gösterilecek biçime çevirir
+func formatDate(value interface{}) string {
+	text, _ := value.(string)
+	parsed, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return text
+	}
+	return parsed.Format("02.01.2006 15:04")
+}
+
 func handleSendEmail(msg messaging.Message) error {
 	data, ok := msg.Data.(map[string]interface{})
 	if !ok {
@@ -95,9 +122,33 @@ func handleSendEmail(msg messaging.Message) error {
 	activationCode, codeOk := data["activation_code"].(string)
 	templateName, templateOk := data["template_name"].(string)
 	userName, userNameOk := data["userName"].(string)
+	lockedUntil, _ := data["locked_until"].(string)
+	newEmail, _ := data["email"].(string)
+	loginCode, _ := data["login_code"].(string)
+	loginURL, _ := data["login_url"].(string)
+	resetURL, _ := data["reset_url"].(string)
+	scheduledFor := formatDate(data["scheduledFor"])
+	downloadURL, _ := data["download_url"].(string)
+	expiresAt := formatDate(data["expiresAt"])
+	device, _ := data["device"].(string)
+	ip, _ := data["ip"].(string)
+	loginAt := formatDate(data["loginAt"])
+	revokeURL, _ := data["revoke_url"].(string)
+	inviteURL, _ := data["invite_url"].(string)
+
+	// Bildirim e-postalarında aktivasyon kodu bulunmaz
+	switch msg.Type {
+	case "user_locked", "user_email_changed", "forgot_password", "magic_login", "account_deletion_scheduled", "account_deleted", "data_export_ready", "new_device_login", "user_invited":
+		codeOk = true
+	}
+
+	// E-posta değişikliği bildirimi hesabın eski adresine gönderilir
+	if msg.Type == "user_email_changed" {
+		email, emailOk = data["old_email"].(string)
+	}
 
 	if !emailOk || !codeOk || !templateOk || !userNameOk {
-		log.Printf("Eksik email, aktivasyon kodu veya şablon adı: %+v", data)
+		log.Printf("Eksik email, aktivasyon kodu veya şablon adı: %+v", msg.Redacted().Data)
 	}
 
 	var subject string
@@ -106,6 +157,24 @@ func handleSendEmail(msg messaging.Message) error {
 		subject = "Hesap Aktivasyonu"
 	case "forgot_password":
 		subject = "Şifre Sıfırlama"
+	case "user_locked":
+		subject = "Hesabınız Geçici Olarak Kilitlendi"
+	case "verify_email_change":
+		subject = "E-posta Adresinizi Doğrulayın"
+	case "user_email_changed":
+		subject = "E-posta Adresiniz Değiştirildi"
+	case "magic_login":
+		subject = "Giriş Bağlantınız"
+	case "account_deletion_scheduled":
+		subject = "Hesabınız Silinmek Üzere Planlandı"
+	case "account_deleted":
+		subject = "Hesabınız Silindi"
+	case "data_export_ready":
+		subject = "Verileriniz İndirilmeye Hazır"
+	case "new_device_login":
+		subject = "Yeni Cihazdan Giriş Yapıldı"
+	case "user_invited":
+		subject = "Kayıt Olmaya Davet Edildiniz"
 	default:
 		log.Printf("Desteklenmeyen komut: %v", msg.Type)
 	}
@@ -114,6 +183,19 @@ func handleSendEmail(msg messaging.Message) error {
 	emailData := EmailData{
 		ActivationCode: activationCode,
 		UserName:       userName,
+		LockedUntil:    lockedUntil,
+		NewEmail:       newEmail,
+		LoginCode:      loginCode,
+		LoginURL:       loginURL,
+		ResetURL:       resetURL,
+		ScheduledFor:   scheduledFor,
+		DownloadURL:    downloadURL,
+		ExpiresAt:      expiresAt,
+		Device:         device,
+		IP:             ip,
+		LoginAt:        loginAt,
+		RevokeURL:      revokeURL,
+		InviteURL:      inviteURL,
 	}
 
 	// Şablonu oluştur
@@ -0,0 +1,81 @@
+// Package audit servislerin güvenlik açısından önemli işlemlerini (girişler, şifre sıfırlama, rol
+// değişikliği, oturum iptali, sohbet yönetimi) tek bir yalnızca-ekleme denetim kaydına yazar.
+package audit
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EventType string
+
+const (
+	// SignIn başarılı veya başarısız giriş denemesidir; giriş yöntemi details.method alanındadır
+	SignIn EventType = "auth.sign_in"
+	// PasswordResetRequested şifre sıfırlama e-postası istenmesidir
+	PasswordResetRequested EventType = "auth.password_reset_requested"
+	// PasswordReset sıfırlama tokeniyle şifrenin değiştirilmesidir
+	PasswordReset EventType = "auth.password_reset"
+	// RoleChanged bir yöneticinin kullanıcının rollerini değiştirmesidir
+	RoleChanged EventType = "auth.role_changed"
+	// SessionRevoked kullanıcının veya bir yöneticinin oturum sonlandırmasıdır
+	SessionRevoked EventType = "auth.session_revoked"
+	// InviteCreated kayıt daveti oluşturulmasıdır; davetle atanacak roller details.roles alanındadır
+	InviteCreated EventType = "auth.invite_created"
+	// InviteRevoked kayıt davetinin iptal edilmesidir
+	InviteRevoked EventType = "auth.invite_revoked"
+	// ChatParticipantsAdded sohbete katılımcı eklenmesidir
+	ChatParticipantsAdded EventType = "chat.participants_added"
+	// ChatParticipantsRemoved sohbetten katılımcı çıkarılmasıdır
+	ChatParticipantsRemoved EventType = "chat.participants_removed"
+)
+
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultFailure Result = "failure"
+)
+
+// Hedef türleri
+const (
+	TargetUser    = "user"
+	TargetAccount = "account"
+	TargetSession = "session"
+	TargetChat    = "chat"
+	TargetInvite  = "invite"
+)
+
+// Event denetim kaydındaki tek bir işlemdir. ActorID işlemi yapan kullanıcıdır; giriş denemelerinde
+// olduğu gibi kimliği henüz bilinmiyorsa boştur ve hedef denenen hesabın e-posta adresidir.
+// İşlem kullanıcı adına açılmış bir oturumla yapıldıysa ImpersonatorID oturumu açan yöneticidir.
+type Event struct {
+	ID             primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Type           EventType              `json:"type" bson:"type"`
+	Service        string                 `json:"service" bson:"service"`
+	ActorID        string                 `json:"actorId,omitempty" bson:"actorId,omitempty"`
+	ImpersonatorID string                 `json:"impersonatorId,omitempty" bson:"impersonatorId,omitempty"`
+	TargetType     string                 `json:"targetType,omitempty" bson:"targetType,omitempty"`
+	TargetID       string                 `json:"targetId,omitempty" bson:"targetId,omitempty"`
+	IP             string                 `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent      string                 `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
+	Result         Result                 `json:"result" bson:"result"`
+	Reason         string                 `json:"reason,omitempty" bson:"reason,omitempty"`
+	Details        map[string]interface{} `json:"details,omitempty" bson:"details,omitempty"`
+	CreatedAt      time.Time              `json:"createdAt" bson:"createdAt"`
+}
+
+// Filter denetim kaydı sorgusudur; boş alanlar filtrelenmez. Before verilirse yalnızca bu ID'den
+// eski kayıtlar döner, böylece sonuçlar sayfalanabilir.
+type Filter struct {
+	ActorID  string
+	TargetID string
+	Types    []EventType
+	Result   Result
+	Service  string
+	From     time.Time
+	To       time.Time
+	Before   primitive.ObjectID
+	Limit    int64
+}
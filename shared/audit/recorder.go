@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+)
+
+type contextKey struct{}
+
+// Recorder servisin denetim kayıtlarını yazar; Middleware ile isteğe eklenir ve handlerlar
+// Record ile kayıt yazar, böylece controllerlara ayrıca bağımlılık olarak verilmesi gerekmez
+type Recorder struct {
+	store   *Store
+	service string
+}
+
+func NewRecorder(store *Store, service string) *Recorder {
+	return &Recorder{store: store, service: service}
+}
+
+// Middleware recorder'ı istek bağlamına ekler
+func (rec *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, rec)))
+	})
+}
+
+// Record kaydı isteğin IP adresi, tarayıcı bilgisi ve oturumdaki kullanıcıyla tamamlayıp yazar.
+// ActorID verilmemişse oturumdaki kullanıcı işlemi yapan kabul edilir. Kayıt yazılamazsa işlem
+// engellenmez, hata loglanır; istekte recorder yoksa hiçbir şey yapılmaz.
+func Record(r *http.Request, event Event) {
+	rec, ok := r.Context().Value(contextKey{}).(*Recorder)
+	if !ok {
+		return
+	}
+
+	event.Service = rec.service
+	event.IP = middlewares.ClientIP(r)
+	event.UserAgent = r.UserAgent()
+	if userData, ok := middlewares.GetUserData(r); ok {
+		if event.ActorID == "" {
+			event.ActorID = userData["id"]
+		}
+		event.ImpersonatorID = userData[redisrepo.ImpersonatorIDKey]
+	}
+	if event.Result == "" {
+		event.Result = ResultSuccess
+	}
+	event.CreatedAt = time.Now()
+
+	if err := rec.store.Append(&event); err != nil {
+		log.Printf("Denetim kaydı yazılamadı (%s): %v", event.Type, err)
+	}
+}
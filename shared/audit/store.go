@@ -0,0 +1,153 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// Denetim kaydı tüm servislerin ortak kullandığı ayrı bir veritabanında tutulur
+	auditDB          = "auditDB"
+	eventsCollection = "events"
+
+	DefaultLimit int64 = 100
+	MaxLimit     int64 = 1000
+)
+
+// Store denetim kayıtlarını MongoDB'de saklar. Kayıtlar yalnızca eklenebilir; Store kayıtları
+// güncelleyen veya silen bir işlem sunmaz.
+type Store struct {
+	collection *mongo.Collection
+}
+
+func NewStore() *Store {
+	collection, _ := database.GetCollection(auditDB, eventsCollection)
+	return &Store{collection: collection}
+}
+
+// EnsureIndexes sorgularda kullanılan alanlar için indeksleri oluşturur
+func (s *Store) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "actorId", Value: 1}, {Key: "_id", Value: -1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "targetId", Value: 1}, {Key: "_id", Value: -1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+// Append kaydı denetim kaydının sonuna ekler
+func (s *Store) Append(event *Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	result, err := s.collection.InsertOne(ctx, event)
+	if err != nil {
+		return err
+	}
+	event.ID, _ = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Find filtreye uyan kayıtları en yeniden eskiye döner
+func (s *Store) Find(filter Filter) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := s.collection.Find(ctx, filter.query(), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []Event{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Export filtreye uyan kayıtları eskiden yeniye JSON Lines biçiminde (her satırda bir kayıt) w'ye yazar
+// ve yazılan kayıt sayısını döner. Limit verilmezse tüm kayıtlar yazılır.
+func (s *Store) Export(ctx context.Context, filter Filter, w io.Writer) (int, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+	cursor, err := s.collection.Find(ctx, filter.query(), findOptions)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	encoder := json.NewEncoder(w)
+	count := 0
+	for cursor.Next(ctx) {
+		var event Event
+		if err := cursor.Decode(&event); err != nil {
+			return count, err
+		}
+		if err := encoder.Encode(event); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, cursor.Err()
+}
+
+func (f Filter) query() bson.M {
+	query := bson.M{}
+	if f.ActorID != "" {
+		query["actorId"] = f.ActorID
+	}
+	if f.TargetID != "" {
+		query["targetId"] = f.TargetID
+	}
+	if len(f.Types) > 0 {
+		query["type"] = bson.M{"$in": f.Types}
+	}
+	if f.Result != "" {
+		query["result"] = f.Result
+	}
+	if f.Service != "" {
+		query["service"] = f.Service
+	}
+
+	createdAt := bson.M{}
+	if !f.From.IsZero() {
+		createdAt["$gte"] = f.From
+	}
+	if !f.To.IsZero() {
+		createdAt["$lt"] = f.To
+	}
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+	if !f.Before.IsZero() {
+		query["_id"] = bson.M{"$lt": f.Before}
+	}
+	return query
+}
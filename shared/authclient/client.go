@@ -0,0 +1,307 @@
+// Package authclient istekteki oturum kimliğini veya API tokenini doğrular. Servisler oturumları
+// Redis'ten doğrudan okuyabilir ya da auth-service'in introspection endpointine sorabilir;
+// böylece oturumların saklanma biçimi değiştiğinde tüm servisleri yeniden dağıtmak gerekmez.
+package authclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-redis/redis"
+)
+
+type Mode string
+
+const (
+	// ModeRedis oturumları auth-service'in Redis anahtarlarından doğrudan okur
+	ModeRedis Mode = "redis"
+	// ModeIntrospection her doğrulamayı auth-service'e sorar
+	ModeIntrospection Mode = "introspection"
+)
+
+const (
+	CredentialSession  = "session"
+	CredentialAPIToken = redisrepo.AuthMethodAPIToken
+
+	// SecretHeader introspection isteklerinde servisler arası gizli anahtarın gönderildiği başlıktır
+	SecretHeader = "X-Internal-Secret"
+
+	maxCacheEntries = 10000
+)
+
+var ErrUnavailable = errors.New("kimlik doğrulama servisine ulaşılamadı")
+
+// Identity bir kimlik bilgisinin doğrulama sonucudur. UserData, middleware'in context'e koyduğu
+// ve handlerların GetUserData ile okuduğu veridir.
+type Identity struct {
+	Active     bool              `json:"active"`
+	UserID     string            `json:"userId,omitempty"`
+	Roles      []models.UserRole `json:"roles,omitempty"`
+	SessionID  string            `json:"sessionId,omitempty"`
+	AuthMethod string            `json:"authMethod,omitempty"`
+	UserData   map[string]string `json:"userData,omitempty"`
+}
+
+// clone önbellekteki kimliğin eşzamanlı isteklerde paylaşılmaması için kopyasını döner;
+// bir handler'ın UserData'yı değiştirmesi diğer isteklerin kimliğini etkilememelidir
+func (i *Identity) clone() *Identity {
+	copied := *i
+	if i.Roles != nil {
+		copied.Roles = append([]models.UserRole(nil), i.Roles...)
+	}
+	if i.UserData != nil {
+		copied.UserData = make(map[string]string, len(i.UserData))
+		for key, value := range i.UserData {
+			copied.UserData[key] = value
+		}
+	}
+	return &copied
+}
+
+// IntrospectRequest auth-service'in introspection endpointine gönderilen gövdedir
+type IntrospectRequest struct {
+	Type  string `json:"type"`
+	Token string `json:"token"`
+}
+
+type Config struct {
+	Mode             Mode
+	IntrospectionURL string
+	Secret           string
+	// CacheTTL sonuçların bellekte tutulacağı süredir; sıfırsa (varsayılan) önbellek kullanılmaz.
+	// İptal edilen bir oturum diğer servislerde en fazla bu süre kadar geçerli kalabilir; bu yüzden
+	// yalnızca çıkış ve yasaklamanın gecikmeli uygulanması kabul edilebiliyorsa açılmalıdır.
+	CacheTTL time.Duration
+	Timeout  time.Duration
+}
+
+// ConfigFromEnv AUTH_MODE, AUTH_INTROSPECTION_URL, INTERNAL_API_SECRET ve AUTH_CACHE_TTL
+// ortam değişkenlerinden yapılandırmayı okur
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Mode:             ModeRedis,
+		IntrospectionURL: "http://localhost:8080/internal/introspect",
+		Secret:           os.Getenv("INTERNAL_API_SECRET"),
+		Timeout:          3 * time.Second,
+	}
+	if mode := os.Getenv("AUTH_MODE"); mode != "" {
+		cfg.Mode = Mode(mode)
+	}
+	if url := os.Getenv("AUTH_INTROSPECTION_URL"); url != "" {
+		cfg.IntrospectionURL = url
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("AUTH_CACHE_TTL")); err == nil {
+		cfg.CacheTTL = ttl
+	}
+	return cfg
+}
+
+type cacheEntry struct {
+	identity  *Identity
+	expiresAt time.Time
+}
+
+// Client kimlik bilgilerini yapılandırılan yöntemle doğrular ve sonuçları kısa süre önbellekte tutar
+type Client struct {
+	config     Config
+	redisRepo  *redisrepo.RedisRepository
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewClient yeni bir istemci oluşturur; redisRepo yalnızca ModeRedis için gereklidir
+func NewClient(redisRepo *redisrepo.RedisRepository, cfg Config) *Client {
+	if cfg.Mode == "" {
+		cfg.Mode = ModeRedis
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+	return &Client{
+		config:     cfg,
+		redisRepo:  redisRepo,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		cache:      make(map[string]cacheEntry),
+	}
+}
+
+// WithHTTPClient özel bir HTTP istemcisi kullanır
+func (c *Client) WithHTTPClient(client *http.Client) *Client {
+	c.httpClient = client
+	return c
+}
+
+// Introspect kimlik bilgisini doğrular. Geçersiz bilgiler için hata değil Active=false döner;
+// hata yalnızca doğrulama yapılamadığında (Redis veya auth-service erişilemezse) döner.
+func (c *Client) Introspect(credentialType, token string) (*Identity, error) {
+	if token == "" {
+		return &Identity{}, nil
+	}
+
+	key := cacheKey(credentialType, token)
+	if identity, ok := c.cached(key); ok {
+		return identity, nil
+	}
+
+	var (
+		identity *Identity
+		err      error
+	)
+	switch c.config.Mode {
+	case ModeIntrospection:
+		identity, err = c.introspectRemote(credentialType, token)
+	case ModeRedis:
+		identity, err = IntrospectRedis(c.redisRepo, credentialType, token)
+	default:
+		err = fmt.Errorf("bilinmeyen doğrulama modu: %s", c.config.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(key, identity)
+	return identity, nil
+}
+
+func (c *Client) introspectRemote(credentialType, token string) (*Identity, error) {
+	body, err := json.Marshal(IntrospectRequest{Type: credentialType, Token: token})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.config.IntrospectionURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SecretHeader, c.config.Secret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var identity Identity
+	if err := json.NewDecoder(resp.Body).Decode(&identity); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return &identity, nil
+}
+
+// IntrospectRedis kimlik bilgisini auth-service'in Redis'teki kayıtlarından doğrular ve kullanım
+// zamanını günceller. Oturum anahtarlarının biçimini bilen tek yer burası olmalıdır.
+func IntrospectRedis(redisRepo *redisrepo.RedisRepository, credentialType, token string) (*Identity, error) {
+	var (
+		userData map[string]string
+		err      error
+	)
+	switch credentialType {
+	case CredentialSession:
+		userData, err = redisRepo.GetSession(redisrepo.SessionKey(token))
+		// Eski formattaki (kullanıcı ID'si ile açılmış) oturumlar kabul edilmez
+		if err == nil && userData["session_id"] != token {
+			return &Identity{}, nil
+		}
+	case CredentialAPIToken:
+		userData, err = redisRepo.GetAPIToken(redisrepo.APITokenHash(token))
+		if err == nil && userData["auth_method"] != redisrepo.AuthMethodAPIToken {
+			return &Identity{}, nil
+		}
+	default:
+		return &Identity{}, nil
+	}
+	if err != nil {
+		if err == redis.Nil {
+			return &Identity{}, nil
+		}
+		return nil, err
+	}
+
+	if credentialType == CredentialSession {
+		err = redisRepo.TouchSession(token)
+	} else {
+		err = redisRepo.TouchAPIToken(redisrepo.APITokenHash(token), userData["token_id"])
+	}
+	if err != nil {
+		// Kullanım zamanı güncellenemese de doğrulama geçerlidir
+		log.Printf("Kullanım zamanı güncellenemedi: %v", err)
+	}
+
+	return identityFromUserData(credentialType, userData), nil
+}
+
+func identityFromUserData(credentialType string, userData map[string]string) *Identity {
+	var roles []models.UserRole
+	json.Unmarshal([]byte(userData["roles"]), &roles)
+
+	authMethod := userData["auth_method"]
+	if authMethod == "" {
+		authMethod = credentialType
+	}
+	return &Identity{
+		Active:     true,
+		UserID:     userData["id"],
+		Roles:      roles,
+		SessionID:  userData["session_id"],
+		AuthMethod: authMethod,
+		UserData:   userData,
+	}
+}
+
+// cacheKey önbellekte düz metin kimlik bilgisi tutulmaması için özet kullanır
+func cacheKey(credentialType, token string) string {
+	sum := sha256.Sum256([]byte(credentialType + ":" + token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Client) cached(key string) (*Identity, bool) {
+	if c.config.CacheTTL <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.identity.clone(), true
+}
+
+func (c *Client) store(key string, identity *Identity) {
+	if c.config.CacheTTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.cache) >= maxCacheEntries {
+		for k, entry := range c.cache {
+			if now.After(entry.expiresAt) {
+				delete(c.cache, k)
+			}
+		}
+		// Hepsi hâlâ geçerliyse önbellek sıfırlanır; en kötü ihtimalle sonraki istekler yeniden doğrulanır
+		if len(c.cache) >= maxCacheEntries {
+			c.cache = make(map[string]cacheEntry)
+		}
+	}
+	c.cache[key] = cacheEntry{identity: identity.clone(), expiresAt: now.Add(c.config.CacheTTL)}
+}
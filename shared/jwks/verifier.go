@@ -0,0 +1,194 @@
+// Package jwks auth-service'in (veya herhangi bir OIDC sağlayıcısının) yayınladığı
+// açık anahtarlarla JWT doğrulaması yapar; servisler arasında gizli anahtar paylaşmaya gerek kalmaz.
+package jwks
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Anahtar listesinin en fazla bu sıklıkta yeniden çekilmesine izin verilir
+const minRefreshInterval = 30 * time.Second
+
+var ErrUnknownKey = errors.New("bilinmeyen imzalama anahtarı")
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+type verificationKey struct {
+	algorithm string
+	public    interface{}
+}
+
+// Verifier JWKS adresinden çektiği anahtarları önbellekte tutar
+type Verifier struct {
+	url        string
+	httpClient *http.Client
+	cacheTTL   time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]verificationKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewVerifier verilen JWKS adresi için bir doğrulayıcı oluşturur
+func NewVerifier(jwksURL string, cacheTTL time.Duration) *Verifier {
+	return &Verifier{
+		url:        jwksURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		cacheTTL:   cacheTTL,
+		keys:       make(map[string]verificationKey),
+	}
+}
+
+// WithHTTPClient özel bir HTTP istemcisi kullanır (testlerde sahte sağlayıcı için)
+func (v *Verifier) WithHTTPClient(client *http.Client) *Verifier {
+	v.httpClient = client
+	return v
+}
+
+// Verify tokenin imzasını ve süresini doğrular; issuer ve audience kontrolü çağırana bırakılır
+func (v *Verifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := v.key(kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.algorithm != "" && token.Method.Alg() != key.algorithm {
+			return nil, errors.New("geçersiz imzalama yöntemi")
+		}
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		default:
+			return nil, errors.New("geçersiz imzalama yöntemi")
+		}
+		return key.public, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("geçersiz token")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("token süresi (exp) eksik")
+	}
+	return claims, nil
+}
+
+func (v *Verifier) key(kid string) (verificationKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	fresh := time.Since(v.fetchedAt) < v.cacheTTL
+	v.mu.RUnlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+
+	// Anahtar bulunamadıysa sağlayıcı anahtar döndürmüş olabilir, listeyi yenile
+	if err := v.refresh(); err != nil && !ok {
+		return verificationKey{}, err
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	return verificationKey{}, ErrUnknownKey
+}
+
+func (v *Verifier) refresh() error {
+	v.mu.Lock()
+	if time.Since(v.lastAttempt) < minRefreshInterval {
+		v.mu.Unlock()
+		return nil
+	}
+	v.lastAttempt = time.Now()
+	v.mu.Unlock()
+
+	resp, err := v.httpClient.Get(v.url)
+	if err != nil {
+		return fmt.Errorf("JWKS alınamadı: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS alınamadı: HTTP %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("JWKS çözümlenemedi: %w", err)
+	}
+
+	keys := make(map[string]verificationKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		public, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = verificationKey{algorithm: jwk.Alg, public: public}
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, errors.New("desteklenmeyen eğri")
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("geçersiz Ed25519 anahtarı")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errors.New("desteklenmeyen anahtar tipi")
+	}
+}
@@ -28,6 +28,37 @@ type Message struct {
 	Headers     Headers     `json:"headers"`      // Custom message headers
 }
 
+// SensitiveDataKeys are payload keys carrying bearer secrets (login links, one-time codes)
+// or personal data (data export parts). Their values are never written to logs; see Message.Redacted.
+var SensitiveDataKeys = map[string]bool{
+	"login_url":       true,
+	"login_code":      true,
+	"download_url":    true,
+	"reset_url":       true,
+	"invite_url":      true,
+	"revoke_url":      true,
+	"data":            true,
+	"activation_code": true,
+}
+
+// Redacted returns a copy of the message that is safe to log: values of SensitiveDataKeys
+// in a map payload are replaced with a placeholder
+func (m Message) Redacted() Message {
+	data, ok := m.Data.(map[string]interface{})
+	if !ok {
+		return m
+	}
+	redacted := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if SensitiveDataKeys[key] {
+			value = "[REDACTED]"
+		}
+		redacted[key] = value
+	}
+	m.Data = redacted
+	return m
+}
+
 // Headers contains custom message metadata
 type Headers map[string]interface{}
 
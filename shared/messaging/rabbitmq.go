@@ -87,7 +87,7 @@ func (r *RabbitMQ) PublishMessage(ctx context.Context, msg Message) error {
 	if msg.Created.IsZero() {
 		msg.Created = time.Now()
 	}
-	fmt.Println(msg)
+	fmt.Println(msg.Redacted())
 
 	// Set source service
 	msg.FromService = r.service
@@ -3,9 +3,11 @@ package middlewares
 import (
 	"context"
 	"encoding/json"
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/MKMuhammetKaradag/go-microservice/shared/authclient"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
 )
 
@@ -16,11 +18,16 @@ func respondWithError(w http.ResponseWriter, code int, message string) {
 }
 
 type AuthMiddleware struct {
-	redisRepo *redisrepo.RedisRepository
+	authClient *authclient.Client
+
+	auditRepo    *redisrepo.RedisRepository
+	auditService string
 }
 
-func NewAuthMiddleware(redisRepo *redisrepo.RedisRepository) *AuthMiddleware {
-	return &AuthMiddleware{redisRepo: redisRepo}
+// NewAuthMiddleware kimlik bilgilerini authClient ile doğrulayan bir middleware oluşturur;
+// oturumların Redis'ten mi yoksa auth-service'ten mi okunacağı istemcinin yapılandırmasına bağlıdır
+func NewAuthMiddleware(authClient *authclient.Client) *AuthMiddleware {
+	return &AuthMiddleware{authClient: authClient}
 }
 
 // AuthMiddleware is the JWT validation middleware
@@ -36,16 +43,25 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 				next.ServeHTTP(w, r)
 				return
 			}
-			var token string
+			var sessionID string
 
 			// WebSocket isteği mi?
 			if strings.Contains(r.Header.Get("Connection"), "Upgrade") && r.Header.Get("Upgrade") == "websocket" {
-				// Token’ı URL parametresinden veya `Sec-WebSocket-Protocol` başlığından al
-				token = r.URL.Query().Get("token")
-				if token == "" {
-					token = r.Header.Get("session_id")
-					// fmt.Println("geldi", token)
+				// Oturum kimliğini URL parametresinden, `session_id` başlığından veya çerezden al
+				sessionID = r.URL.Query().Get("token")
+				if sessionID == "" {
+					sessionID = r.Header.Get("session_id")
+				}
+				if sessionID == "" {
+					if cookieSessionId, err := r.Cookie("session_id"); err == nil {
+						sessionID = cookieSessionId.Value
+					}
 				}
+				sessionID = strings.TrimPrefix(sessionID, "session:")
+			} else if token, ok := bearerAPIToken(r); ok {
+				// Script ve botlar çerez yerine kişisel erişim tokeni kullanır
+				m.authenticate(w, r, next, authclient.CredentialAPIToken, token)
+				return
 			} else {
 				// Normal HTTP istekleri için `session_id` çerezini kontrol et
 				cookieSessionId, err := r.Cookie("session_id")
@@ -53,33 +69,79 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 					respondWithError(w, http.StatusUnauthorized, "Unauthorized: missing session")
 					return
 				}
-				token = "session:" + cookieSessionId.Value
+				sessionID = cookieSessionId.Value
 			}
 
-			// Construct the session key for Redis
-			// sessionKey := "session:" + cookieSessionId.Value
-
-			// Try to fetch session data from Redis
-
-			userData, err := m.redisRepo.GetSession(token)
-			// database.RedisClient.Get(sessionKey).Result()
-			if err != nil {
-				// Handle case where session data is not found in Redis
-				respondWithError(w, http.StatusUnauthorized, "geçersiz oturum")
+			if sessionID == "" {
+				respondWithError(w, http.StatusUnauthorized, "Unauthorized: missing session")
 				return
 			}
 
-			// var userData map[string]string
-			// err = json.Unmarshal([]byte(tokenRedis), &userData)
-			// if err != nil {
-			// 	respondWithError(w, http.StatusInternalServerError, "Veri çözümleme hatası")
-			// 	return
-			// }
+			m.authenticate(w, r, next, authclient.CredentialSession, sessionID)
+		})
+}
 
-			ctx := context.WithValue(r.Context(), "userData", userData)
-			next.ServeHTTP(w, r.WithContext(ctx))
+// bearerAPIToken "Authorization: Bearer pat_..." başlığındaki kişisel erişim tokenini döner
+func bearerAPIToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(header[7:])
+	return token, strings.HasPrefix(token, redisrepo.APITokenPrefix)
+}
 
-		})
+// authenticate kimlik bilgisini doğrular ve oturumdaki userData'yı context'e ekler
+func (m *AuthMiddleware) authenticate(w http.ResponseWriter, r *http.Request, next http.Handler, credentialType, token string) {
+	identity, err := m.authClient.Introspect(credentialType, token)
+	if err != nil {
+		log.Printf("Kimlik doğrulanamadı: %v", err)
+		respondWithError(w, http.StatusServiceUnavailable, authclient.ErrUnavailable.Error())
+		return
+	}
+	if !identity.Active {
+		if credentialType == authclient.CredentialAPIToken {
+			respondWithError(w, http.StatusUnauthorized, "geçersiz veya süresi dolmuş token")
+		} else {
+			respondWithError(w, http.StatusUnauthorized, "geçersiz oturum")
+		}
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "userData", identity.UserData)
+	m.serveAudited(w, r.WithContext(ctx), next, identity.UserData)
+}
+
+// RequireSession API tokeniyle yapılan istekleri reddeder; şifre, e-posta, 2FA ve token yönetimi
+// gibi hesap işlemleri yalnızca tarayıcı oturumuyla yapılabilir
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsAPITokenRequest(r) {
+			respondWithError(w, http.StatusForbidden, "Bu işlem API tokeni ile yapılamaz")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsAPITokenRequest isteğin oturum yerine API tokeniyle doğrulanıp doğrulanmadığını döner
+func IsAPITokenRequest(r *http.Request) bool {
+	userData, ok := GetUserData(r)
+	return ok && userData["auth_method"] == redisrepo.AuthMethodAPIToken
+}
+
+// OptionalAuthenticate geçerli bir oturum çerezi varsa kullanıcı bilgisini context'e ekler,
+// yoksa isteği reddetmeden geçirir (ör. oturum yoksa giriş sayfasına yönlendiren endpointler için)
+func (m *AuthMiddleware) OptionalAuthenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("session_id"); err == nil && cookie.Value != "" {
+			identity, err := m.authClient.Introspect(authclient.CredentialSession, cookie.Value)
+			if err == nil && identity.Active {
+				r = r.WithContext(context.WithValue(r.Context(), "userData", identity.UserData))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 func GetUserData(r *http.Request) (map[string]string, bool) {
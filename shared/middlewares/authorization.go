@@ -0,0 +1,244 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+)
+
+type authorizerContextKey struct{}
+
+// Authorizer rol-yetki eşlemesini tutar ve yapılandırma dosyası değiştiğinde yeniden yükler
+type Authorizer struct {
+	mu              sync.RWMutex
+	path            string
+	modTime         time.Time
+	rolePermissions map[models.UserRole][]models.Permission
+}
+
+// NewAuthorizer eşlemeyi verilen JSON dosyasından yükler; dosya yoksa varsayılan eşleme kullanılır
+func NewAuthorizer(path string) *Authorizer {
+	a := &Authorizer{
+		path:            path,
+		rolePermissions: models.DefaultRolePermissions(),
+	}
+	if err := a.Reload(); err != nil {
+		log.Printf("Yetki dosyası yüklenemedi, varsayılan eşleme kullanılıyor: %v", err)
+	}
+	return a
+}
+
+// Reload yapılandırma dosyasını yeniden okur
+func (a *Authorizer) Reload() error {
+	if a.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		return err
+	}
+
+	var rolePermissions map[models.UserRole][]models.Permission
+	if err := json.Unmarshal(data, &rolePermissions); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.rolePermissions = rolePermissions
+	a.modTime = info.ModTime()
+	a.mu.Unlock()
+	return nil
+}
+
+// WatchConfig dosyanın değişip değişmediğini periyodik olarak kontrol eder ve değiştiyse yeniden yükler
+func (a *Authorizer) WatchConfig(interval time.Duration, stop <-chan struct{}) {
+	if a.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			info, err := os.Stat(a.path)
+			if err != nil {
+				continue
+			}
+			a.mu.RLock()
+			changed := info.ModTime().After(a.modTime)
+			a.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := a.Reload(); err != nil {
+				log.Printf("Yetki dosyası yeniden yüklenemedi: %v", err)
+			} else {
+				log.Println("Yetki dosyası yeniden yüklendi")
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// RolePermissions mevcut eşlemenin bir kopyasını döner
+func (a *Authorizer) RolePermissions() map[models.UserRole][]models.Permission {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	result := make(map[models.UserRole][]models.Permission, len(a.rolePermissions))
+	for role, permissions := range a.rolePermissions {
+		result[role] = append([]models.Permission(nil), permissions...)
+	}
+	return result
+}
+
+// HasPermission rollerden herhangi biri yetkiye sahipse true döner.
+// "*" tüm yetkileri, "chat:*" gibi değerler kaynağın tüm eylemlerini kapsar.
+func (a *Authorizer) HasPermission(roles []models.UserRole, permission models.Permission) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, role := range roles {
+		if grantsAny(a.rolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// grantsAny verilen yetkilerden biri istenen yetkiyi kapsıyorsa true döner
+func grantsAny(granted []models.Permission, permission models.Permission) bool {
+	for _, grant := range granted {
+		if grant == models.PermAll || grant == permission {
+			return true
+		}
+		if strings.HasSuffix(string(grant), ":*") &&
+			strings.HasPrefix(string(permission), strings.TrimSuffix(string(grant), "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware Authorizer'ı istek bağlamına ekler; RequireRole, RequirePermission ve HasPermission bunu kullanır
+func (a *Authorizer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), authorizerContextKey{}, a)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetUserRoles oturumdaki JSON formatındaki rolleri çözümler
+func GetUserRoles(r *http.Request) []models.UserRole {
+	userData, ok := GetUserData(r)
+	if !ok {
+		return nil
+	}
+
+	var roles []models.UserRole
+	if err := json.Unmarshal([]byte(userData["roles"]), &roles); err != nil {
+		return nil
+	}
+	return roles
+}
+
+// HasPermission handler içinde oturumdaki kullanıcının yetkisini kontrol etmek için kullanılır
+func HasPermission(r *http.Request, permission models.Permission) bool {
+	authorizer, ok := r.Context().Value(authorizerContextKey{}).(*Authorizer)
+	if !ok {
+		log.Println("Authorizer middleware'i tanımlı değil, yetki reddedildi")
+		return false
+	}
+	if !authorizer.HasPermission(GetUserRoles(r), permission) {
+		return false
+	}
+	// API tokenleri kullanıcının yetkilerini yalnızca daraltabilir; token kapsamında olmayan yetki kullanılamaz
+	if IsAPITokenRequest(r) {
+		return grantsAny(GetTokenScopes(r), permission)
+	}
+	return true
+}
+
+// GetTokenScopes API tokeniyle yapılan istekte tokenin kapsamlarını döner
+func GetTokenScopes(r *http.Request) []models.Permission {
+	userData, ok := GetUserData(r)
+	if !ok {
+		return nil
+	}
+
+	var scopes []models.Permission
+	if err := json.Unmarshal([]byte(userData["scopes"]), &scopes); err != nil {
+		return nil
+	}
+	return scopes
+}
+
+// HasRole oturumdaki kullanıcının verilen rollerden birine sahip olup olmadığını döner
+func HasRole(r *http.Request, roles ...models.UserRole) bool {
+	for _, userRole := range GetUserRoles(r) {
+		for _, role := range roles {
+			if userRole == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RequireRole kullanıcının verilen rollerden en az birine sahip olmasını zorunlu kılar
+func RequireRole(roles ...models.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := GetUserData(r); !ok {
+				respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+				return
+			}
+			if !HasRole(r, roles...) {
+				respondWithError(w, http.StatusForbidden, "Bu işlem için yetkiniz yok")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission kullanıcının verilen yetkilerin tümüne sahip olmasını zorunlu kılar
+func RequirePermission(permissions ...models.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := GetUserData(r); !ok {
+				respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+				return
+			}
+			for _, permission := range permissions {
+				if !HasPermission(r, permission) {
+					respondWithError(w, http.StatusForbidden, "Bu işlem için yetkiniz yok")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PermissionsFilePath yetki dosyasının yolunu ortam değişkeninden veya varsayılandan döner
+func PermissionsFilePath() string {
+	if path := os.Getenv("PERMISSIONS_FILE"); path != "" {
+		return path
+	}
+	return "../permissions.json"
+}
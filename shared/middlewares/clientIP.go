@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP isteği yapan istemcinin IP adresini döner.
+// Proxy başlıklarına yalnızca istek yerel ağdaki bir proxy'den (nginx) geldiğinde güvenilir.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	remoteIP := net.ParseIP(host)
+	if remoteIP == nil || !(remoteIP.IsLoopback() || remoteIP.IsPrivate()) {
+		return host
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		return strings.TrimSpace(strings.Split(forwardedFor, ",")[0])
+	}
+	return host
+}
@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// IsImpersonating isteğin bir yöneticinin kullanıcı adına açtığı oturumla yapılıp yapılmadığını döner
+func IsImpersonating(r *http.Request) bool {
+	userData, ok := GetUserData(r)
+	return ok && userData[redisrepo.ImpersonatorIDKey] != ""
+}
+
+// BlockImpersonation kullanıcı adına açılmış oturumlarda şifre değişikliği, hesap silme gibi
+// geri alınamaz veya hesabın güvenliğini etkileyen işlemleri engeller
+func BlockImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsImpersonating(r) {
+			respondWithError(w, http.StatusForbidden, "Bu işlem kullanıcı adına açılmış oturumda yapılamaz")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithImpersonationAudit kullanıcı adına açılmış oturumlarla yapılan her isteğin Redis'teki
+// denetim akışına yazılmasını sağlar; service kaydın hangi servisten geldiğini belirtir
+func (m *AuthMiddleware) WithImpersonationAudit(repo *redisrepo.RedisRepository, service string) *AuthMiddleware {
+	m.auditRepo = repo
+	m.auditService = service
+	return m
+}
+
+// serveAudited isteği işler ve kullanıcı adına yapılmışsa sonucuyla birlikte denetim kaydı yazar
+func (m *AuthMiddleware) serveAudited(w http.ResponseWriter, r *http.Request, next http.Handler, userData map[string]string) {
+	if m.auditRepo == nil || userData[redisrepo.ImpersonatorIDKey] == "" {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	next.ServeHTTP(ww, r)
+
+	entry := &redisrepo.ImpersonationAuditEntry{
+		ImpersonatorID: userData[redisrepo.ImpersonatorIDKey],
+		UserID:         userData["id"],
+		SessionID:      userData["session_id"],
+		Service:        m.auditService,
+		Method:         r.Method,
+		Path:           r.URL.Path,
+		Status:         ww.Status(),
+		IP:             ClientIP(r),
+		UserAgent:      r.UserAgent(),
+		CreatedAt:      time.Now(),
+	}
+	if err := m.auditRepo.AppendImpersonationAudit(entry); err != nil {
+		log.Printf("Kullanıcı adına yapılan istek kaydedilemedi: %v", err)
+	}
+}
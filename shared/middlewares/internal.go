@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/authclient"
+)
+
+// InternalSecretHeader servisler arası iç isteklerde ortak gizli anahtarın gönderildiği başlıktır
+const InternalSecretHeader = authclient.SecretHeader
+
+// RequireInternalSecret yalnızca diğer servislerin çağırdığı endpointleri korur; secret boşsa endpoint kapalıdır
+func RequireInternalSecret(secret string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if secret == "" {
+				respondWithError(w, http.StatusNotFound, "Endpoint kapalı")
+				return
+			}
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get(InternalSecretHeader)), []byte(secret)) != 1 {
+				respondWithError(w, http.StatusUnauthorized, "Yetkisiz erişim")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RateLimitAlgorithm istek sınırının hangi algoritmayla uygulanacağını belirler
+type RateLimitAlgorithm string
+
+const (
+	// TokenBucket kısa süreli patlamalara Limit kadar izin verir, jetonlar Window boyunca eşit aralıklarla dolar
+	TokenBucket RateLimitAlgorithm = "token_bucket"
+	// SlidingWindow son Window süresi içinde en fazla Limit isteğe izin verir
+	SlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+// RateLimitKeyFunc isteğin hangi sayaca yazılacağını belirler
+type RateLimitKeyFunc func(r *http.Request) string
+
+// RateLimitRule bir route desenine uygulanacak sınırı tanımlar
+type RateLimitRule struct {
+	Name      string // Metrik etiketi ve Redis anahtarında kullanılır
+	Method    string // Boşsa tüm HTTP metodlarına uygulanır
+	Pattern   string // "/chat/{chatID}" veya "/auth/*" gibi chi tarzı desen
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+	KeyFunc   RateLimitKeyFunc // Boşsa KeyByUserOrIP kullanılır
+}
+
+var rateLimitRejectedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "rate_limit_rejected_total",
+		Help: "Total number of requests rejected by the rate limiter",
+	},
+	[]string{"service", "rule"},
+)
+
+func init() {
+	prometheus.MustRegister(rateLimitRejectedTotal)
+}
+
+// KeyByIP istekleri istemci IP adresine göre sayar
+func KeyByIP(r *http.Request) string {
+	return "ip:" + ClientIP(r)
+}
+
+// KeyByUserOrIP oturum açmış kullanıcıları kullanıcı ID'sine, diğerlerini IP adresine göre sayar
+func KeyByUserOrIP(r *http.Request) string {
+	if userData, ok := GetUserData(r); ok && userData["id"] != "" {
+		return "user:" + userData["id"]
+	}
+	return KeyByIP(r)
+}
+
+// KeyByAPIKey X-API-Key başlığı varsa anahtarın özetine, yoksa kullanıcıya veya IP adresine göre sayar
+func KeyByAPIKey(r *http.Request) string {
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		return "apikey:" + hex.EncodeToString(sum[:])
+	}
+	return KeyByUserOrIP(r)
+}
+
+// RateLimiter Redis üzerinde tutulan sayaçlarla tüm servis örnekleri arasında ortak istek sınırı uygular
+type RateLimiter struct {
+	redisRepo *redisrepo.RedisRepository
+	service   string
+	rules     []RateLimitRule
+}
+
+// NewRateLimiter verilen servis için kurallarla bir RateLimiter oluşturur
+func NewRateLimiter(redisRepo *redisrepo.RedisRepository, service string, rules ...RateLimitRule) *RateLimiter {
+	return &RateLimiter{redisRepo: redisRepo, service: service, rules: rules}
+}
+
+// Middleware isteğin yolu ile eşleşen ilk kuralı uygular; eşleşen kural yoksa istek olduğu gibi geçer
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, rule := range l.rules {
+			if rule.matches(r) {
+				if !l.allow(w, r, rule) {
+					return
+				}
+				break
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Limit kuralı desene bakmadan doğrudan bir route'a uygular (ör. router.With(limiter.Limit(rule)))
+func (l *RateLimiter) Limit(rule RateLimitRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !l.allow(w, r, rule) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allow sınırı kontrol eder ve RateLimit-* başlıklarını yazar; istek reddedildiyse yanıtı da yazar.
+// Redis'e ulaşılamazsa servis kesintiye uğramasın diye isteğe izin verilir.
+func (l *RateLimiter) allow(w http.ResponseWriter, r *http.Request, rule RateLimitRule) bool {
+	keyFunc := rule.KeyFunc
+	if keyFunc == nil {
+		keyFunc = KeyByUserOrIP
+	}
+	key := fmt.Sprintf("rate_limit:%s:%s:%s", l.service, rule.Name, keyFunc(r))
+
+	var (
+		result *redisrepo.RateLimitResult
+		err    error
+	)
+	switch rule.Algorithm {
+	case TokenBucket:
+		refillEvery := rule.Window / time.Duration(rule.Limit)
+		if refillEvery < time.Millisecond {
+			refillEvery = time.Millisecond
+		}
+		result, err = l.redisRepo.TokenBucketAllow(key, rule.Limit, refillEvery)
+	default:
+		result, err = l.redisRepo.SlidingWindowAllow(key, rule.Limit, rule.Window)
+	}
+	if err != nil {
+		log.Printf("Rate limit kontrol edilemedi (%s): %v", rule.Name, err)
+		return true
+	}
+
+	w.Header().Set("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+	w.Header().Set("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+	w.Header().Set("RateLimit-Reset", ceilSeconds(result.ResetIn))
+	w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", rule.Limit, ceilSeconds(rule.Window)))
+
+	if result.Allowed {
+		return true
+	}
+
+	rateLimitRejectedTotal.WithLabelValues(l.service, rule.Name).Inc()
+	w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+	respondWithError(w, http.StatusTooManyRequests, "Çok fazla istek gönderildi, lütfen daha sonra tekrar deneyin")
+	return false
+}
+
+func ceilSeconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// matches isteğin metodunu ve yolunu kuralın deseniyle karşılaştırır
+func (rule RateLimitRule) matches(r *http.Request) bool {
+	if rule.Method != "" && !strings.EqualFold(rule.Method, r.Method) {
+		return false
+	}
+	return matchRoutePattern(rule.Pattern, r.URL.Path)
+}
+
+// matchRoutePattern "{param}" parçalarını tek bir segmentle, sondaki "*" karakterini yolun geri kalanıyla eşleştirir
+func matchRoutePattern(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, part := range patternParts {
+		if part == "*" && i == len(patternParts)-1 {
+			return true
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			continue
+		}
+		if part != pathParts[i] {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
@@ -0,0 +1,34 @@
+// models/account_deletion.go
+package models
+
+import "time"
+
+type DeletionStatus string
+
+const (
+	// DeletionScheduled hesabın bekleme süresinde olduğunu belirtir; kullanıcı bu sürede hesabını geri alabilir
+	DeletionScheduled DeletionStatus = "scheduled"
+	// DeletionErasing kişisel verilerin silindiğini ve diğer servislerin onayının beklendiğini belirtir
+	DeletionErasing DeletionStatus = "erasing"
+	// DeletionCompleted tüm servislerin kullanıcının verilerini sildiğini onayladığını belirtir
+	DeletionCompleted DeletionStatus = "completed"
+)
+
+// AccountDeletion kullanıcının hesabını silme isteğidir. Bekleme süresi dolunca hesap anonimleştirilir ve
+// diğer servislere "user_deleted" mesajı gönderilir; her servis kendi verisini silince onay gönderir.
+type AccountDeletion struct {
+	Status       DeletionStatus `bson:"status" json:"status"`
+	RequestedAt  time.Time      `bson:"requestedAt" json:"requestedAt"`
+	ScheduledFor time.Time      `bson:"scheduledFor" json:"scheduledFor"`
+	ErasedAt     *time.Time     `bson:"erasedAt,omitempty" json:"erasedAt,omitempty"`
+	// AnnouncedAt "user_deleted" mesajının son gönderildiği zamandır; onay gelmezse mesaj yeniden gönderilir
+	AnnouncedAt *time.Time `bson:"announcedAt,omitempty" json:"announcedAt,omitempty"`
+	// Acknowledgements servis adından verisini sildiğini onayladığı zamana eşlemedir
+	Acknowledgements map[string]time.Time `bson:"acknowledgements,omitempty" json:"acknowledgements,omitempty"`
+	CompletedAt      *time.Time           `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// Pending hesabın bekleme süresinde, yani henüz geri alınabilir durumda olup olmadığını döner
+func (d *AccountDeletion) Pending() bool {
+	return d != nil && d.Status == DeletionScheduled
+}
@@ -0,0 +1,28 @@
+// models/account_restriction.go
+package models
+
+import "time"
+
+type RestrictionStatus string
+
+const (
+	// RestrictionSuspended hesabın geçici olarak durdurulduğunu belirtir; kullanıcının içerikleri görünür kalır
+	RestrictionSuspended RestrictionStatus = "suspended"
+	// RestrictionBanned hesabın yasaklandığını belirtir; diğer servisler kullanıcının içeriklerini gizler
+	RestrictionBanned RestrictionStatus = "banned"
+)
+
+// AccountRestriction yöneticinin hesaba uyguladığı askıya alma veya yasaklama kaydıdır.
+// ExpiresAt boşsa kısıtlama yönetici kaldırana kadar sürer.
+type AccountRestriction struct {
+	Status    RestrictionStatus `bson:"status" json:"status"`
+	Reason    string            `bson:"reason" json:"reason"`
+	ExpiresAt *time.Time        `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	CreatedBy string            `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	CreatedAt time.Time         `bson:"createdAt" json:"createdAt"`
+}
+
+// Active kısıtlamanın verilen anda geçerli olup olmadığını döner
+func (r *AccountRestriction) Active(now time.Time) bool {
+	return r != nil && (r.ExpiresAt == nil || now.Before(*r.ExpiresAt))
+}
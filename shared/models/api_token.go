@@ -0,0 +1,24 @@
+// models/api_token.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIToken script ve botların çerez yerine kullandığı kişisel erişim tokenidir.
+// Tokenin kendisi değil yalnızca SHA-256 özeti saklanır; düz metin yalnızca oluşturulurken bir kez gösterilir.
+type APIToken struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"userId" bson:"userId"`
+	Name       string             `json:"name" bson:"name"`
+	TokenHash  string             `json:"-" bson:"tokenHash"`
+	Prefix     string             `json:"prefix" bson:"prefix"` // Tokeni listede tanımak için ilk karakterler
+	Scopes     []Permission       `json:"scopes" bson:"scopes"`
+	ExpiresAt  time.Time          `json:"expiresAt" bson:"expiresAt"`
+	CreatedBy  primitive.ObjectID `json:"createdBy" bson:"createdBy"` // Servis hesapları için tokeni oluşturan yönetici
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	LastUsedAt *time.Time         `json:"lastUsedAt,omitempty" bson:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time         `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`
+}
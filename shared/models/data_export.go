@@ -0,0 +1,41 @@
+// models/data_export.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DataExportStatus string
+
+const (
+	// DataExportPending servislerin kendi verilerini göndermesinin beklendiğini belirtir
+	DataExportPending DataExportStatus = "pending"
+	// DataExportAssembling tüm parçaların geldiğini ve arşivin hazırlandığını belirtir
+	DataExportAssembling DataExportStatus = "assembling"
+	// DataExportReady arşivin indirme bağlantısıyla indirilebileceğini belirtir
+	DataExportReady DataExportStatus = "ready"
+	// DataExportFailed bir servisin zamanında yanıt vermediğini veya arşivin oluşturulamadığını belirtir
+	DataExportFailed DataExportStatus = "failed"
+	// DataExportExpired indirme bağlantısının süresinin dolduğunu ve arşivin silindiğini belirtir
+	DataExportExpired DataExportStatus = "expired"
+)
+
+// DataExport kullanıcının tüm servislerde tutulan verilerinin dışa aktarma isteğidir. Her servis kendi
+// parçasını gönderir; tüm parçalar gelince zip arşivi oluşturulur ve süreli bir bağlantıyla indirilebilir.
+// Bağlantı tokeninin kendisi değil yalnızca SHA-256 özeti saklanır.
+type DataExport struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID primitive.ObjectID `json:"-" bson:"userId"`
+	Status DataExportStatus   `json:"status" bson:"status"`
+	// Parts servis adından parçasının geldiği zamana eşlemedir
+	Parts       map[string]time.Time `json:"parts,omitempty" bson:"parts,omitempty"`
+	FileID      *primitive.ObjectID  `json:"-" bson:"fileId,omitempty"`
+	Size        int64                `json:"size,omitempty" bson:"size,omitempty"`
+	TokenHash   string               `json:"-" bson:"tokenHash,omitempty"`
+	Error       string               `json:"error,omitempty" bson:"error,omitempty"`
+	RequestedAt time.Time            `json:"requestedAt" bson:"requestedAt"`
+	CompletedAt *time.Time           `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+	ExpiresAt   *time.Time           `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+}
@@ -0,0 +1,19 @@
+// models/federated_identity.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FederatedIdentity harici bir kimlik sağlayıcısındaki hesabı (provider + subject) yerel kullanıcıya bağlar
+type FederatedIdentity struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID `bson:"userId" json:"userId"`
+	Provider    string             `bson:"provider" json:"provider"`
+	Subject     string             `bson:"subject" json:"subject"`
+	Email       string             `bson:"email" json:"email"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	LastLoginAt time.Time          `bson:"lastLoginAt" json:"lastLoginAt"`
+}
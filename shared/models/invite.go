@@ -0,0 +1,24 @@
+// models/invite.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Invite davetle kayıt modunda yeni hesap açmaya izin veren davettir. Kodun kendisi değil yalnızca
+// SHA-256 özeti saklanır; e-posta adresi verilmiş davetler yalnızca o adresle kullanılabilir.
+type Invite struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"userId" bson:"userId,omitempty"` // Daveti oluşturan kullanıcı; hesabı silinmişse boştur
+	CodeHash  string             `json:"-" bson:"codeHash"`
+	Prefix    string             `json:"prefix" bson:"prefix"` // Daveti listede tanımak için kodun ilk karakterleri
+	Email     string             `json:"email,omitempty" bson:"email,omitempty"`
+	Roles     []UserRole         `json:"roles" bson:"roles"` // Davetle açılan hesaba atanacak roller
+	MaxUses   int                `json:"maxUses" bson:"maxUses"`
+	Uses      int                `json:"uses" bson:"uses"`
+	ExpiresAt time.Time          `json:"expiresAt" bson:"expiresAt"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	RevokedAt *time.Time         `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`
+}
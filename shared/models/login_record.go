@@ -0,0 +1,24 @@
+// models/login_record.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginRecord kullanıcının başarılı bir girişidir. Fingerprint tarayıcının device_id çerezi, tarayıcı
+// bilgisi ve IP ağından üretilen özettir; daha önce görülmemiş bir özetle yapılan giriş yeni cihaz sayılır.
+type LoginRecord struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"-" bson:"userId"`
+	Fingerprint string             `json:"-" bson:"fingerprint"`
+	Method      string             `json:"method" bson:"method"`
+	Device      string             `json:"device" bson:"device"`
+	IP          string             `json:"ip" bson:"ip"`
+	NewDevice   bool               `json:"newDevice" bson:"newDevice"`
+	// ReportedAt kullanıcının uyarı e-postasındaki "bu ben değilim" bağlantısıyla oturumu kapattığı zamandır
+	ReportedAt *time.Time `json:"reportedAt,omitempty" bson:"reportedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" bson:"createdAt"`
+	ExpiresAt  time.Time  `json:"-" bson:"expiresAt"`
+}
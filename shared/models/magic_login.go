@@ -0,0 +1,14 @@
+// models/magic_login.go
+package models
+
+import "time"
+
+// MagicLoginRequest şifresiz giriş isteğini tutar; bağlantı tokeninin ve kodun yalnızca SHA-256 özetleri saklanır.
+// Kayıt, isteği başlatan tarayıcıya verilen tokenin özetiyle anahtarlanır.
+type MagicLoginRequest struct {
+	UserID    string    `json:"userId"`
+	Email     string    `json:"email"`
+	LinkHash  string    `json:"linkHash"`
+	CodeHash  string    `json:"codeHash"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,98 @@
+// models/oauth.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OAuth2 grant tipleri
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+)
+
+// OpenID Connect kapsamları
+const (
+	ScopeOpenID        = "openid"
+	ScopeProfile       = "profile"
+	ScopeEmail         = "email"
+	ScopeOfflineAccess = "offline_access"
+)
+
+// OAuthClient authDB'de kayıtlı bir OAuth2 istemcisidir
+type OAuthClient struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ClientID         string             `bson:"clientId" json:"clientId"`
+	ClientSecretHash string             `bson:"clientSecretHash,omitempty" json:"-"`
+	Name             string             `bson:"name" json:"name"`
+	RedirectURIs     []string           `bson:"redirectUris" json:"redirectUris"`
+	GrantTypes       []string           `bson:"grantTypes" json:"grantTypes"`
+	Scopes           []string           `bson:"scopes" json:"scopes"`
+	Public           bool               `bson:"public" json:"public"`         // Gizli anahtarı olmayan (SPA, mobil) istemci; PKCE zorunludur
+	FirstParty       bool               `bson:"firstParty" json:"firstParty"` // Kendi uygulamalarımız için onay ekranı gösterilmez
+	CreatedBy        primitive.ObjectID `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	CreatedAt        time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt        time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// AllowsGrant istemcinin verilen grant tipini kullanıp kullanamayacağını döner
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	for _, allowed := range c.GrantTypes {
+		if allowed == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRedirectURI adresin kayıtlı adreslerden biriyle birebir aynı olup olmadığını döner
+func (c *OAuthClient) AllowsRedirectURI(redirectURI string) bool {
+	for _, allowed := range c.RedirectURIs {
+		if allowed == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuthConsent kullanıcının bir istemciye verdiği kapsam iznini tutar
+type OAuthConsent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	ClientID  string             `bson:"clientId" json:"clientId"`
+	Scopes    []string           `bson:"scopes" json:"scopes"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// OAuthRefreshToken veritabanında yalnızca özeti tutulan yenileme tokenidir.
+// Her kullanımda yenisiyle değiştirilir; aynı aileden kullanılmış bir token tekrar gelirse aile iptal edilir.
+type OAuthRefreshToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TokenHash string             `bson:"tokenHash" json:"-"`
+	FamilyID  string             `bson:"familyId" json:"familyId"`
+	ClientID  string             `bson:"clientId" json:"clientId"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Scopes    []string           `bson:"scopes" json:"scopes"`
+	AuthTime  time.Time          `bson:"authTime" json:"authTime"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
+	Used      bool               `bson:"used" json:"used"`
+	Revoked   bool               `bson:"revoked" json:"revoked"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// OAuthAuthorizationRequest onay bekleyen veya koda dönüştürülmüş yetkilendirme isteğidir
+type OAuthAuthorizationRequest struct {
+	ClientID            string    `json:"clientId"`
+	UserID              string    `json:"userId"`
+	RedirectURI         string    `json:"redirectUri"`
+	Scopes              []string  `json:"scopes"`
+	State               string    `json:"state,omitempty"`
+	Nonce               string    `json:"nonce,omitempty"`
+	CodeChallenge       string    `json:"codeChallenge,omitempty"`
+	CodeChallengeMethod string    `json:"codeChallengeMethod,omitempty"`
+	AuthTime            time.Time `json:"authTime"`
+}
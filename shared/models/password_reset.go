@@ -7,10 +7,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// PasswordReset şifre sıfırlama isteğini tutar; tokenin kendisi değil yalnızca SHA-256 özeti saklanır
 type PasswordReset struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	UserID    primitive.ObjectID `json:"userId" bson:"userId" validate:"required"`
-	Token     string             `json:"token" bson:"token" validate:"required"`
+	TokenHash string             `json:"-" bson:"tokenHash" validate:"required"`
 	ExpiresAt time.Time          `json:"expiresAt" bson:"expiresAt" validate:"required"`
 	Used      bool               `json:"used" bson:"used" validate:"boolean"`
+	UsedAt    *time.Time         `json:"usedAt,omitempty" bson:"usedAt,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
 }
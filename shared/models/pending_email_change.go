@@ -0,0 +1,12 @@
+// models/pending_email_change.go
+package models
+
+import "time"
+
+// PendingEmailChange yeni e-posta adresine gönderilen kod doğrulanana kadar bekleyen değişikliği tutar
+type PendingEmailChange struct {
+	UserID    string    `json:"userId"`
+	NewEmail  string    `json:"newEmail"`
+	CodeHash  string    `json:"codeHash"`
+	CreatedAt time.Time `json:"createdAt"`
+}
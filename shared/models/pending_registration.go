@@ -0,0 +1,21 @@
+// models/pending_registration.go
+package models
+
+import "time"
+
+// PendingRegistration aktivasyonu bekleyen kayıt isteğini tutar.
+// Şifre her zaman bcrypt ile hashlenmiş olarak saklanır.
+type PendingRegistration struct {
+	Username     string     `json:"username"`
+	Email        string     `json:"email"`
+	PasswordHash string     `json:"passwordHash"`
+	FirstName    string     `json:"firstName"`
+	LastName     string     `json:"lastName"`
+	Age          *int       `json:"age,omitempty"`
+	Roles        []UserRole `json:"roles"`
+	InviteID     string     `json:"inviteId,omitempty"` // Aktivasyonda kullanılacak davet
+	CodeHash     string     `json:"codeHash"`
+	ResendCount  int        `json:"resendCount"`
+	LastSentAt   time.Time  `json:"lastSentAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,31 @@
+// models/permission.go
+package models
+
+// Permission rollere atanabilen, "kaynak:eylem" biçimindeki yetki adıdır
+type Permission string
+
+const (
+	PermAll                Permission = "*"
+	PermUserReadAny        Permission = "user:read-any"
+	PermUserBan            Permission = "user:ban"
+	PermUserUnlock         Permission = "user:unlock"
+	PermUserManageRoles    Permission = "user:manage-roles"
+	PermUserRevokeSessions Permission = "user:revoke-sessions"
+	PermUserImpersonate    Permission = "user:impersonate"
+	PermChatManageAny      Permission = "chat:manage-any"
+	PermChatDeleteAny      Permission = "chat:delete-any"
+	PermPermissionsManage  Permission = "permissions:manage"
+	PermOAuthClientsManage Permission = "oauth:manage-clients"
+	PermAPITokensManage    Permission = "apitoken:manage"
+	PermAuditRead          Permission = "audit:read"
+	PermInvitesManage      Permission = "invite:manage"
+)
+
+// DefaultRolePermissions yapılandırma dosyası bulunamadığında kullanılan rol-yetki eşlemesidir
+func DefaultRolePermissions() map[UserRole][]Permission {
+	return map[UserRole][]Permission{
+		ADMIN: {PermAll},
+		TEST:  {PermUserReadAny},
+		USER:  {},
+	}
+}
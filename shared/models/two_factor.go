@@ -0,0 +1,14 @@
+// models/two_factor.go
+package models
+
+import "time"
+
+// TwoFactorSettings kullanıcının TOTP tabanlı iki adımlı doğrulama ayarlarını tutar
+type TwoFactorSettings struct {
+	Enabled       bool       `bson:"enabled" json:"enabled"`
+	Secret        string     `bson:"secret,omitempty" json:"-"`
+	PendingSecret string     `bson:"pendingSecret,omitempty" json:"-"`
+	RecoveryCodes []string   `bson:"recoveryCodes,omitempty" json:"-"` // Kurtarma kodlarının SHA-256 hash'leri
+	LastUsedStep  int64      `bson:"lastUsedStep,omitempty" json:"-"`  // Aynı kodun tekrar kullanılmasını engeller
+	EnabledAt     *time.Time `bson:"enabledAt,omitempty" json:"enabledAt,omitempty"`
+}
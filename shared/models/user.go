@@ -16,20 +16,24 @@ const (
 )
 
 type User struct {
-	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Username     string             `json:"username" bson:"username" validate:"required,min=3,max=30"`
-	Email        string             `json:"email" bson:"email" validate:"required,email"`
-	Password     string             `json:"password" bson:"password" validate:"required,min=8"`
-	FirstName    string             `json:"firstName" bson:"firstName" validate:"required,min=3,max=50"`
-	LastName     string             `json:"lastName" bson:"lastName" validate:"required,min=3,max=50"`
-	Age          *int               `json:"age,omitempty" bson:"age,omitempty" validate:"omitempty,min=13,max=150"`
-	ProfilePhoto *string            `json:"profilePhoto,omitempty" bson:"profilePhoto,omitempty"  validate:"omitempty" `
-	Roles        []UserRole         `json:"roles" bson:"roles" `
-	IsDeleted    bool               `bson:"isDeleted" json:"isDeleted"`
-	DeletedAt    *time.Time         `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
-	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
-	Status       string             `bson:"status" json:"status"`
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Username     string              `json:"username" bson:"username" validate:"required,min=3,max=30"`
+	Email        string              `json:"email" bson:"email" validate:"required,email"`
+	Password     string              `json:"password" bson:"password" validate:"required"`
+	FirstName    string              `json:"firstName" bson:"firstName" validate:"required,min=3,max=50"`
+	LastName     string              `json:"lastName" bson:"lastName" validate:"required,min=3,max=50"`
+	Age          *int                `json:"age,omitempty" bson:"age,omitempty" validate:"omitempty,min=13,max=150"`
+	ProfilePhoto *string             `json:"profilePhoto,omitempty" bson:"profilePhoto,omitempty"  validate:"omitempty" `
+	Roles        []UserRole          `json:"roles" bson:"roles" `
+	IsDeleted    bool                `bson:"isDeleted" json:"isDeleted"`
+	DeletedAt    *time.Time          `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+	CreatedAt    time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time           `bson:"updatedAt" json:"updatedAt"`
+	Status       string              `bson:"status" json:"status"`
+	LastSeenAt   *time.Time          `bson:"lastSeenAt,omitempty" json:"lastSeenAt,omitempty"`
+	TwoFactor    *TwoFactorSettings  `bson:"twoFactor,omitempty" json:"twoFactor,omitempty"`
+	Restriction  *AccountRestriction `bson:"restriction,omitempty" json:"restriction,omitempty"`
+	Deletion     *AccountDeletion    `bson:"deletion,omitempty" json:"deletion,omitempty"`
 }
 
 func NewUser() User {
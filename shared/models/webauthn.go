@@ -0,0 +1,34 @@
+// models/webauthn.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebAuthnCredential kullanıcının kaydettiği bir passkey'i tutar; açık anahtar COSE_Key biçiminde saklanır
+type WebAuthnCredential struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID         primitive.ObjectID `json:"-" bson:"userId"`
+	CredentialID   string             `json:"credentialId" bson:"credentialId"` // base64url
+	PublicKey      []byte             `json:"-" bson:"publicKey"`
+	Algorithm      int64              `json:"algorithm" bson:"algorithm"`
+	SignCount      int64              `json:"-" bson:"signCount"`
+	Transports     []string           `json:"transports,omitempty" bson:"transports,omitempty"`
+	AAGUID         string             `json:"aaguid,omitempty" bson:"aaguid,omitempty"` // Kimlik doğrulayıcı modelinin hex kimliği
+	Name           string             `json:"name" bson:"name"`
+	BackupEligible bool               `json:"backupEligible" bson:"backupEligible"`
+	BackupState    bool               `json:"backupState" bson:"backupState"` // true ise passkey cihazlar arasında eşitleniyor
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	LastUsedAt     *time.Time         `json:"lastUsedAt,omitempty" bson:"lastUsedAt,omitempty"`
+}
+
+// WebAuthnSession başlatılmış bir kayıt veya giriş töreninin challenge'ını saklar
+type WebAuthnSession struct {
+	Challenge            string   `json:"challenge"` // base64url
+	Ceremony             string   `json:"ceremony"`
+	UserID               string   `json:"userId,omitempty"`
+	UserVerification     string   `json:"userVerification"`
+	AllowedCredentialIDs []string `json:"allowedCredentialIds,omitempty"`
+}
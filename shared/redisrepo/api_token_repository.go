@@ -0,0 +1,138 @@
+package redisrepo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	apiTokenKeyPrefix         = "api_token:"
+	apiTokenLastUsedKeyPrefix = "api_token_last_used:"
+	userAPITokensKeyPrefix    = "user_api_tokens:"
+
+	// APITokenPrefix kişisel erişim tokenlerini oturum kimliklerinden ve JWT'lerden ayırır
+	APITokenPrefix = "pat_"
+	// AuthMethodAPIToken API tokeni ile doğrulanan isteklerde userData["auth_method"] değeridir
+	AuthMethodAPIToken = "api_token"
+)
+
+// APITokenHash tokenin Redis ve veritabanında saklanan SHA-256 özetini döner
+func APITokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiTokenKey(tokenHash string) string {
+	return apiTokenKeyPrefix + tokenHash
+}
+
+func apiTokenLastUsedKey(tokenID string) string {
+	return apiTokenLastUsedKeyPrefix + tokenID
+}
+
+func userAPITokensKey(userID string) string {
+	return userAPITokensKeyPrefix + userID
+}
+
+// SetAPIToken tokenin kimlik bilgisini tüm servislerin okuyabileceği şekilde önbelleğe yazar.
+// Kalıcı kayıt auth-service'in veritabanındadır; bu anahtar yalnızca doğrulama içindir.
+func (r *RedisRepository) SetAPIToken(tokenHash string, userData map[string]string, expiration time.Duration) error {
+	if err := r.SetSession(apiTokenKey(tokenHash), userData, expiration); err != nil {
+		return err
+	}
+	pipe := r.Client.TxPipeline()
+	pipe.SAdd(userAPITokensKey(userData["id"]), tokenHash)
+	pipe.Persist(userAPITokensKey(userData["id"]))
+	_, err := pipe.Exec()
+	return err
+}
+
+// GetAPIToken tokenin özetinden userData'yı döner; token yoksa veya süresi dolduysa redis.Nil döner
+func (r *RedisRepository) GetAPIToken(tokenHash string) (map[string]string, error) {
+	return r.GetSession(apiTokenKey(tokenHash))
+}
+
+// DeleteAPIToken tokeni önbellekten siler; token bir sonraki istekte reddedilir
+func (r *RedisRepository) DeleteAPIToken(userID, tokenHash, tokenID string) error {
+	_, err := r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Del(apiTokenKey(tokenHash), apiTokenLastUsedKey(tokenID))
+		pipe.SRem(userAPITokensKey(userID), tokenHash)
+		return nil
+	})
+	return err
+}
+
+// TouchAPIToken tokenin son kullanılma zamanını tokenle aynı sürede silinecek şekilde kaydeder
+func (r *RedisRepository) TouchAPIToken(tokenHash, tokenID string) error {
+	ttl, err := r.Client.TTL(apiTokenKey(tokenHash)).Result()
+	if err != nil || ttl <= 0 {
+		return err
+	}
+	return r.Client.Set(apiTokenLastUsedKey(tokenID), time.Now().Format(time.RFC3339), ttl).Err()
+}
+
+// APITokenLastUsed tokenin son kullanılma zamanını döner; hiç kullanılmadıysa sıfır değer döner
+func (r *RedisRepository) APITokenLastUsed(tokenID string) (time.Time, error) {
+	value, err := r.Client.Get(apiTokenLastUsedKey(tokenID)).Result()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// UpdateUserAPITokens kullanıcının tüm tokenlerindeki verileri kalan süreyi koruyarak günceller
+// (ör. e-posta değiştiğinde tokenlerde eski adresin kalmaması için)
+func (r *RedisRepository) UpdateUserAPITokens(userID string, fields map[string]string) error {
+	tokenHashes, err := r.Client.SMembers(userAPITokensKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, tokenHash := range tokenHashes {
+		// Tokenlerin her zaman bir bitiş süresi vardır; süresi dolmuş olanlar listeden temizlenir
+		ttl, err := r.Client.TTL(apiTokenKey(tokenHash)).Result()
+		if err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			r.Client.SRem(userAPITokensKey(userID), tokenHash)
+			continue
+		}
+		userData, err := r.GetAPIToken(tokenHash)
+		if err != nil {
+			if err == redis.Nil {
+				continue
+			}
+			return err
+		}
+		for key, value := range fields {
+			userData[key] = value
+		}
+		if err := r.SetSession(apiTokenKey(tokenHash), userData, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteUserAPITokens kullanıcının tüm tokenlerini önbellekten siler (ör. hesap askıya alındığında);
+// veritabanındaki kayıtlar korunur, kısıtlama kalkınca eşitleme tokenleri yeniden önbelleğe yazar
+func (r *RedisRepository) DeleteUserAPITokens(userID string) error {
+	tokenHashes, err := r.Client.SMembers(userAPITokensKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(tokenHashes)+1)
+	for _, tokenHash := range tokenHashes {
+		keys = append(keys, apiTokenKey(tokenHash))
+	}
+	keys = append(keys, userAPITokensKey(userID))
+	return r.Client.Del(keys...).Err()
+}
@@ -0,0 +1,112 @@
+package redisrepo
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	// ImpersonationAuditStream yöneticilerin kullanıcı adına yaptığı isteklerin tüm servislerden yazıldığı akıştır
+	ImpersonationAuditStream = "impersonation_audit"
+	// impersonationAuditMaxLen akışta tutulan yaklaşık en fazla kayıt sayısıdır; eski kayıtlar silinir
+	impersonationAuditMaxLen = 100000
+
+	// Kullanıcı adına açılan oturumlarda userData'ya eklenen alanlar
+	ImpersonatorIDKey        = "impersonator_id"
+	ImpersonatorEmailKey     = "impersonator_email"
+	ImpersonatorSessionIDKey = "impersonator_session_id"
+	ImpersonationReasonKey   = "impersonation_reason"
+	ImpersonationExpiresKey  = "impersonation_expires_at"
+)
+
+// ImpersonationAuditEntry kullanıcı adına yapılan tek bir isteğin kaydıdır
+type ImpersonationAuditEntry struct {
+	ID             string    `json:"id"`
+	ImpersonatorID string    `json:"impersonatorId"`
+	UserID         string    `json:"userId"`
+	SessionID      string    `json:"sessionId"`
+	Service        string    `json:"service"`
+	Method         string    `json:"method"`
+	Path           string    `json:"path"`
+	Status         int       `json:"status"`
+	IP             string    `json:"ip"`
+	UserAgent      string    `json:"userAgent"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// AppendImpersonationAudit kaydı akışın sonuna ekler
+func (r *RedisRepository) AppendImpersonationAudit(entry *ImpersonationAuditEntry) error {
+	return r.Client.XAdd(&redis.XAddArgs{
+		Stream:       ImpersonationAuditStream,
+		MaxLenApprox: impersonationAuditMaxLen,
+		Values: map[string]interface{}{
+			"impersonatorId": entry.ImpersonatorID,
+			"userId":         entry.UserID,
+			"sessionId":      entry.SessionID,
+			"service":        entry.Service,
+			"method":         entry.Method,
+			"path":           entry.Path,
+			"status":         entry.Status,
+			"ip":             entry.IP,
+			"userAgent":      entry.UserAgent,
+			"createdAt":      entry.CreatedAt.Format(time.RFC3339),
+		},
+	}).Err()
+}
+
+// ListImpersonationAudit en yeni kayıtlardan başlayarak verilen yöneticiye ve/veya kullanıcıya ait
+// en fazla limit kadar kaydı döner; filtreler boşsa tüm kayıtlar döner
+func (r *RedisRepository) ListImpersonationAudit(impersonatorID, userID string, limit int) ([]ImpersonationAuditEntry, error) {
+	entries := []ImpersonationAuditEntry{}
+	end := "+"
+	// Sonraki sayfalar bir önceki sayfanın son kaydıyla başladığı için bir fazla kayıt istenir
+	count := limit + 1
+	for len(entries) < limit {
+		messages, err := r.Client.XRevRangeN(ImpersonationAuditStream, end, "-", int64(count)).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			if message.ID == end {
+				continue
+			}
+			entry := impersonationAuditEntry(message)
+			if (impersonatorID == "" || entry.ImpersonatorID == impersonatorID) && (userID == "" || entry.UserID == userID) {
+				entries = append(entries, entry)
+				if len(entries) == limit {
+					break
+				}
+			}
+		}
+		// Akışın başına gelindi
+		if len(messages) < count {
+			break
+		}
+		end = messages[len(messages)-1].ID
+	}
+	return entries, nil
+}
+
+func impersonationAuditEntry(message redis.XMessage) ImpersonationAuditEntry {
+	value := func(key string) string {
+		s, _ := message.Values[key].(string)
+		return s
+	}
+	status, _ := strconv.Atoi(value("status"))
+	createdAt, _ := time.Parse(time.RFC3339, value("createdAt"))
+	return ImpersonationAuditEntry{
+		ID:             message.ID,
+		ImpersonatorID: value("impersonatorId"),
+		UserID:         value("userId"),
+		SessionID:      value("sessionId"),
+		Service:        value("service"),
+		Method:         value("method"),
+		Path:           value("path"),
+		Status:         status,
+		IP:             value("ip"),
+		UserAgent:      value("userAgent"),
+		CreatedAt:      createdAt,
+	}
+}
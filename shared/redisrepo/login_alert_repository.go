@@ -0,0 +1,34 @@
+package redisrepo
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const loginAlertKeyPrefix = "login_alert:"
+
+func loginAlertKey(tokenHash string) string {
+	return loginAlertKeyPrefix + tokenHash
+}
+
+// SetLoginAlert yeni cihaz uyarısındaki "bu ben değilim" tokeninin kapatacağı oturumu saklar
+func (r *RedisRepository) SetLoginAlert(tokenHash string, data map[string]string, expiration time.Duration) error {
+	return r.SetSession(loginAlertKey(tokenHash), data, expiration)
+}
+
+// TakeLoginAlert tokenin verisini okur ve tokeni siler, böylece bağlantı yalnızca bir kez kullanılabilir;
+// token yoksa veya süresi dolduysa redis.Nil döner
+func (r *RedisRepository) TakeLoginAlert(tokenHash string) (map[string]string, error) {
+	pipe := r.Client.TxPipeline()
+	get := pipe.Get(loginAlertKey(tokenHash))
+	pipe.Del(loginAlertKey(tokenHash))
+	if _, err := pipe.Exec(); err != nil {
+		return nil, err
+	}
+
+	var data map[string]string
+	if err := json.Unmarshal([]byte(get.Val()), &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
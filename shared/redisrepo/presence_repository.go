@@ -0,0 +1,264 @@
+package redisrepo
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	presenceKeyPrefix      = "presence:"
+	presenceConnsKeyPrefix = "presence_conns:"
+	presenceOnlineKey      = "presence_online"
+
+	// PresenceChannel durum değişikliklerinin tüm servis örneklerine yayınlandığı kanaldır
+	PresenceChannel = "presence_updates"
+
+	// Bir temizlik turunda en fazla bu kadar kullanıcı işlenir
+	presenceSweepBatch = 500
+)
+
+const (
+	PresenceOnline    = "online"
+	PresenceAway      = "away"
+	PresenceDND       = "dnd"
+	PresenceInvisible = "invisible"
+	PresenceOffline   = "offline"
+)
+
+// Presence bir kullanıcının çevrimiçi durumudur. Status kullanıcının seçtiği durumdur; hiç bağlantısı
+// yoksa "offline" olur. Diğer kullanıcılara gösterilmeden önce Public ile gizlilik uygulanmalıdır.
+type Presence struct {
+	UserID              string     `json:"userId"`
+	Status              string     `json:"status"`
+	StatusText          string     `json:"statusText,omitempty"`
+	StatusTextExpiresAt *time.Time `json:"statusTextExpiresAt,omitempty"`
+	LastSeenAt          *time.Time `json:"lastSeenAt,omitempty"`
+	Connections         int64      `json:"connections"`
+	// Invisible kullanıcı görünmez modu seçtiyse çevrimdışıyken de true'dur
+	Invisible bool `json:"invisible,omitempty"`
+}
+
+// PresenceEvent PresenceChannel'da yayınlanan mesajdır. PublicChanged, diğer kullanıcılara görünen
+// durumun değişip değişmediğini belirtir; görünmez kullanıcının bağlanıp ayrılması abonelere iletilmez.
+type PresenceEvent struct {
+	Presence      *Presence `json:"presence"`
+	PublicChanged bool      `json:"publicChanged"`
+}
+
+// Public presence'ın diğer kullanıcılara görünen halini döner; görünmez kullanıcılar çevrimdışı görünür
+func (p *Presence) Public() *Presence {
+	public := *p
+	public.Connections = 0
+	public.Invisible = false
+	if p.Invisible {
+		public.Status = PresenceOffline
+		public.StatusText = ""
+		public.StatusTextExpiresAt = nil
+	}
+	return &public
+}
+
+func presenceKey(userID string) string {
+	return presenceKeyPrefix + userID
+}
+
+func presenceConnsKey(userID string) string {
+	return presenceConnsKeyPrefix + userID
+}
+
+func unixMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromMillis(value string) *time.Time {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms == 0 {
+		return nil
+	}
+	t := time.Unix(0, ms*int64(time.Millisecond))
+	return &t
+}
+
+// Bağlantılar bitiş zamanı skoruyla tutulur; kalp atışı gelmeyen bağlantılar kendiliğinden düşer.
+// presence_online kullanıcının en geç biten bağlantısını tutar; kullanıcıyı bu kümeye ekleyen veya
+// buradan çıkaran örnek durum değişikliğini yayınlar, böylece aynı değişiklik iki kez yayınlanmaz.
+var presenceConnectScript = redis.NewScript(`
+local now = tonumber(ARGV[2])
+local expiry = now + tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+redis.call('ZADD', KEYS[1], expiry, ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+local added = redis.call('ZADD', KEYS[2], expiry, ARGV[4])
+if redis.call('HGET', KEYS[3], 'status') ~= 'invisible' then
+	redis.call('HSET', KEYS[3], 'lastSeenAt', now)
+end
+return added
+`)
+
+var presenceDisconnectScript = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[2])
+if redis.call('ZCARD', KEYS[1]) > 0 then
+	return 0
+end
+local removed = redis.call('ZREM', KEYS[2], ARGV[3])
+if removed == 1 and redis.call('HGET', KEYS[3], 'status') ~= 'invisible' then
+	redis.call('HSET', KEYS[3], 'lastSeenAt', ARGV[2])
+end
+return removed
+`)
+
+var presenceSweepScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+if last[2] then
+	redis.call('ZADD', KEYS[2], last[2], ARGV[2])
+	return 0
+end
+return redis.call('ZREM', KEYS[2], ARGV[2])
+`)
+
+// TouchPresenceConnection bağlantıyı ekler veya kalp atışıyla süresini uzatır.
+// Kullanıcının başka canlı bağlantısı yoksa (çevrimdışıyken bağlandıysa) true döner.
+func (r *RedisRepository) TouchPresenceConnection(userID, connectionID string, ttl time.Duration) (bool, error) {
+	added, err := presenceConnectScript.Run(r.Client,
+		[]string{presenceConnsKey(userID), presenceOnlineKey, presenceKey(userID)},
+		connectionID, unixMillis(time.Now()), ttl.Milliseconds(), userID).Int64()
+	return added == 1, err
+}
+
+// RemovePresenceConnection bağlantıyı siler; kullanıcının son bağlantısıysa true döner
+func (r *RedisRepository) RemovePresenceConnection(userID, connectionID string) (bool, error) {
+	removed, err := presenceDisconnectScript.Run(r.Client,
+		[]string{presenceConnsKey(userID), presenceOnlineKey, presenceKey(userID)},
+		connectionID, unixMillis(time.Now()), userID).Int64()
+	return removed == 1, err
+}
+
+// ExpireStalePresence kalp atışı kesilmiş (ör. servis örneği çökmüş) bağlantıları temizler
+// ve bu yüzden çevrimdışı olan kullanıcıları döner
+func (r *RedisRepository) ExpireStalePresence() ([]string, error) {
+	now := unixMillis(time.Now())
+	userIDs, err := r.Client.ZRangeByScore(presenceOnlineKey, redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now, 10),
+		Count: presenceSweepBatch,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var offline []string
+	for _, userID := range userIDs {
+		removed, err := presenceSweepScript.Run(r.Client,
+			[]string{presenceConnsKey(userID), presenceOnlineKey}, now, userID).Int64()
+		if err != nil {
+			return offline, err
+		}
+		if removed == 1 {
+			offline = append(offline, userID)
+		}
+	}
+	return offline, nil
+}
+
+// SetPresenceStatus kullanıcının seçtiği durumu ve özel durum metnini kaydeder.
+// textExpiresAt sıfırsa metin süresizdir; text boşsa metin silinir.
+func (r *RedisRepository) SetPresenceStatus(userID, status, text string, textExpiresAt time.Time) error {
+	key := presenceKey(userID)
+	fields := map[string]interface{}{"status": status}
+	// Görünmez olan kullanıcının son görülme zamanı bu anda sabitlenir
+	if status == PresenceInvisible {
+		fields["lastSeenAt"] = unixMillis(time.Now())
+	}
+
+	pipe := r.Client.TxPipeline()
+	if text == "" {
+		pipe.HDel(key, "statusText", "statusTextExpiresAt")
+	} else {
+		fields["statusText"] = text
+		if textExpiresAt.IsZero() {
+			pipe.HDel(key, "statusTextExpiresAt")
+		} else {
+			fields["statusTextExpiresAt"] = unixMillis(textExpiresAt)
+		}
+	}
+	pipe.HMSet(key, fields)
+	_, err := pipe.Exec()
+	return err
+}
+
+// GetPresence kullanıcının güncel durumunu döner; süresi dolmuş durum metni dahil edilmez
+func (r *RedisRepository) GetPresence(userID string) (*Presence, error) {
+	now := time.Now()
+
+	pipe := r.Client.Pipeline()
+	fields := pipe.HGetAll(presenceKey(userID))
+	connections := pipe.ZCount(presenceConnsKey(userID), "("+strconv.FormatInt(unixMillis(now), 10), "+inf")
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	values := fields.Val()
+	presence := &Presence{
+		UserID:      userID,
+		Status:      values["status"],
+		LastSeenAt:  fromMillis(values["lastSeenAt"]),
+		Connections: connections.Val(),
+	}
+	if presence.Status == "" {
+		presence.Status = PresenceOnline
+	}
+	presence.Invisible = presence.Status == PresenceInvisible
+	if presence.Connections == 0 {
+		presence.Status = PresenceOffline
+	}
+
+	expiresAt := fromMillis(values["statusTextExpiresAt"])
+	if expiresAt == nil || expiresAt.After(now) {
+		presence.StatusText = values["statusText"]
+		presence.StatusTextExpiresAt = expiresAt
+	}
+	return presence, nil
+}
+
+// GetPresences birden fazla kullanıcının durumunu tek seferde döner
+func (r *RedisRepository) GetPresences(userIDs []string) ([]*Presence, error) {
+	presences := make([]*Presence, 0, len(userIDs))
+	for _, userID := range userIDs {
+		presence, err := r.GetPresence(userID)
+		if err != nil {
+			return nil, err
+		}
+		presences = append(presences, presence)
+	}
+	return presences, nil
+}
+
+// SwapPublicPresence diğer kullanıcılara en son gösterilen durumu kaydeder; önceki durumdan farklıysa true döner
+func (r *RedisRepository) SwapPublicPresence(public *Presence) (bool, error) {
+	payload, err := json.Marshal(public)
+	if err != nil {
+		return false, err
+	}
+	previous, err := r.Client.HGet(presenceKey(public.UserID), "public").Result()
+	if err != nil && err != redis.Nil {
+		return false, err
+	}
+	if previous == string(payload) {
+		return false, nil
+	}
+	return true, r.Client.HSet(presenceKey(public.UserID), "public", payload).Err()
+}
+
+// PublishPresence durum değişikliğini tüm servis örneklerine yayınlar
+func (r *RedisRepository) PublishPresence(presence *Presence, publicChanged bool) error {
+	payload, err := json.Marshal(PresenceEvent{Presence: presence, PublicChanged: publicChanged})
+	if err != nil {
+		return err
+	}
+	return r.Client.Publish(PresenceChannel, payload).Err()
+}
@@ -0,0 +1,167 @@
+package redisrepo
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// Aynı nanosaniyede gelen denemelerin sorted set içinde çakışmaması için kullanılır
+var slidingWindowSeq uint64
+
+// SlidingWindowReset penceredeki tüm denemeleri siler
+func (r *RedisRepository) SlidingWindowReset(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.Client.Del(keys...).Err()
+}
+
+// RateLimitResult bir istek sınırı kontrolünün sonucudur
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	ResetIn    time.Duration // Sınırın tamamen sıfırlanmasına kalan süre
+	RetryAfter time.Duration // İstek reddedildiyse tekrar denemeden önce beklenecek süre
+}
+
+// Kontrol ve ekleme tek adımda yapılır, böylece aynı anda gelen istekler sınırı aşamaz
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+var tokenBucketScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local refill = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / refill)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) * refill)
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity * refill))
+return {allowed, math.floor(tokens), wait, math.ceil((capacity - tokens) * refill)}
+`)
+
+// SlidingWindowAllow pencere içinde limit kadar isteğe izin verir; izin verilen istek pencereye eklenir
+func (r *RedisRepository) SlidingWindowAllow(key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	result, _, err := r.SlidingWindowReserve(key, limit, window)
+	return result, err
+}
+
+// SlidingWindowReserve SlidingWindowAllow gibi çalışır ve izin verilen isteğin pencereye eklenen üyesini de döner;
+// sonucu daha sonra belli olan denemeler bu üyeyle SlidingWindowRelease çağrılarak geri alınabilir
+func (r *RedisRepository) SlidingWindowReserve(key string, limit int, window time.Duration) (*RateLimitResult, string, error) {
+	now := time.Now()
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), atomic.AddUint64(&slidingWindowSeq, 1))
+
+	values, err := slidingWindowScript.Run(r.Client, []string{key},
+		now.UnixNano()/int64(time.Millisecond), window.Milliseconds(), limit, member).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	reply, err := scriptIntegers(values, 3)
+	if err != nil {
+		return nil, "", err
+	}
+
+	allowed := reply[0] == 1
+	count := reply[1]
+	resetIn := time.Duration(reply[2]) * time.Millisecond
+
+	result := &RateLimitResult{
+		Allowed:   allowed,
+		Limit:     int64(limit),
+		Remaining: int64(limit) - count,
+		ResetIn:   resetIn,
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if !allowed {
+		result.RetryAfter = resetIn
+		member = ""
+	}
+	return result, member, nil
+}
+
+// SlidingWindowRelease SlidingWindowReserve ile eklenen üyeyi pencereden çıkarır
+func (r *RedisRepository) SlidingWindowRelease(key, member string) error {
+	if member == "" {
+		return nil
+	}
+	return r.Client.ZRem(key, member).Err()
+}
+
+// TokenBucketAllow kapasitesi capacity olan, refillEvery sürede bir jeton dolan bir kovadan jeton harcar
+func (r *RedisRepository) TokenBucketAllow(key string, capacity int, refillEvery time.Duration) (*RateLimitResult, error) {
+	now := time.Now()
+
+	values, err := tokenBucketScript.Run(r.Client, []string{key},
+		now.UnixNano()/int64(time.Millisecond), capacity, refillEvery.Milliseconds()).Result()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := scriptIntegers(values, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RateLimitResult{
+		Allowed:    reply[0] == 1,
+		Limit:      int64(capacity),
+		Remaining:  reply[1],
+		RetryAfter: time.Duration(reply[2]) * time.Millisecond,
+		ResetIn:    time.Duration(reply[3]) * time.Millisecond,
+	}, nil
+}
+
+// scriptIntegers Lua betiğinin döndüğü tamsayı listesini çözümler
+func scriptIntegers(values interface{}, expected int) ([]int64, error) {
+	reply, ok := values.([]interface{})
+	if !ok || len(reply) != expected {
+		return nil, fmt.Errorf("beklenmeyen rate limit yanıtı: %v", values)
+	}
+
+	result := make([]int64, len(reply))
+	for i, value := range reply {
+		number, ok := value.(int64)
+		if !ok {
+			return nil, fmt.Errorf("beklenmeyen rate limit yanıtı: %v", values)
+		}
+		result[i] = number
+	}
+	return result, nil
+}
@@ -42,10 +42,6 @@ func (r *RedisRepository) DeleteSession(key string) error {
 	return r.Client.Del(key).Err()
 }
 
-func (r *RedisRepository) PublishStatus(userID string, status string) error {
-	return r.Client.Publish("user_status", userID+":"+status).Err()
-}
-
 func (r *RedisRepository) PublishChatMessage(chatID string, content string, senderID string) error {
 	return r.Client.Publish("send_Message", chatID+":"+content+":"+senderID).Err()
 }
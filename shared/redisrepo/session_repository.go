@@ -0,0 +1,216 @@
+package redisrepo
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	sessionKeyPrefix      = "session:"
+	sessionMetaKeyPrefix  = "session_meta:"
+	userSessionsKeyPrefix = "user_sessions:"
+)
+
+var ErrSessionNotFound = errors.New("oturum bulunamadı")
+
+// SessionMeta bir oturumun cihaz ve kullanım bilgilerini tutar
+type SessionMeta struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"userId"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	Current    bool      `json:"current"`
+	// ImpersonatorID oturum bir yönetici tarafından kullanıcı adına açıldıysa yöneticinin ID'sidir
+	ImpersonatorID string `json:"impersonatorId,omitempty"`
+}
+
+// NewSessionID tahmin edilemeyen, rastgele bir oturum kimliği üretir
+func NewSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// SessionKey oturum kimliğinden Redis anahtarını üretir
+func SessionKey(sessionID string) string {
+	return sessionKeyPrefix + sessionID
+}
+
+func sessionMetaKey(sessionID string) string {
+	return sessionMetaKeyPrefix + sessionID
+}
+
+func userSessionsKey(userID string) string {
+	return userSessionsKeyPrefix + userID
+}
+
+// CreateSession yeni bir oturum oluşturur ve kullanıcının oturum listesine ekler
+func (r *RedisRepository) CreateSession(userData map[string]string, meta SessionMeta, expiration time.Duration) (string, error) {
+	sessionID, err := NewSessionID()
+	if err != nil {
+		return "", err
+	}
+	userData["session_id"] = sessionID
+
+	now := time.Now()
+	if err := r.SetSession(SessionKey(sessionID), userData, expiration); err != nil {
+		return "", err
+	}
+
+	pipe := r.Client.TxPipeline()
+	fields := map[string]interface{}{
+		"userId":     meta.UserID,
+		"device":     meta.Device,
+		"ip":         meta.IP,
+		"createdAt":  now.Format(time.RFC3339),
+		"lastUsedAt": now.Format(time.RFC3339),
+	}
+	if meta.ImpersonatorID != "" {
+		fields["impersonatorId"] = meta.ImpersonatorID
+	}
+	pipe.HMSet(sessionMetaKey(sessionID), fields)
+	pipe.Expire(sessionMetaKey(sessionID), expiration)
+	pipe.SAdd(userSessionsKey(meta.UserID), sessionID)
+	pipe.Expire(userSessionsKey(meta.UserID), expiration)
+	if _, err := pipe.Exec(); err != nil {
+		r.Client.Del(SessionKey(sessionID))
+		return "", err
+	}
+
+	return sessionID, nil
+}
+
+// TouchSession oturumun son kullanılma zamanını günceller
+func (r *RedisRepository) TouchSession(sessionID string) error {
+	exists, err := r.Client.Exists(sessionMetaKey(sessionID)).Result()
+	if err != nil || exists == 0 {
+		return err
+	}
+	return r.Client.HSet(sessionMetaKey(sessionID), "lastUsedAt", time.Now().Format(time.RFC3339)).Err()
+}
+
+// ListUserSessions kullanıcının aktif oturumlarını döner, süresi dolmuş olanları listeden temizler
+func (r *RedisRepository) ListUserSessions(userID string) ([]SessionMeta, error) {
+	sessionIDs, err := r.Client.SMembers(userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]SessionMeta, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		fields, err := r.Client.HGetAll(sessionMetaKey(sessionID)).Result()
+		if err != nil {
+			return nil, err
+		}
+		exists, err := r.Client.Exists(SessionKey(sessionID)).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 || exists == 0 {
+			r.Client.SRem(userSessionsKey(userID), sessionID)
+			continue
+		}
+
+		createdAt, _ := time.Parse(time.RFC3339, fields["createdAt"])
+		lastUsedAt, _ := time.Parse(time.RFC3339, fields["lastUsedAt"])
+		sessions = append(sessions, SessionMeta{
+			ID:         sessionID,
+			UserID:     userID,
+			Device:     fields["device"],
+			IP:         fields["ip"],
+			CreatedAt:  createdAt,
+			LastUsedAt: lastUsedAt,
+
+			ImpersonatorID: fields["impersonatorId"],
+		})
+	}
+	return sessions, nil
+}
+
+// UpdateUserSessions kullanıcının tüm oturumlarındaki verileri kalan süreyi koruyarak günceller
+// (ör. e-posta değiştiğinde oturumlardaki eski adresin kalmaması için)
+func (r *RedisRepository) UpdateUserSessions(userID string, fields map[string]string) error {
+	sessionIDs, err := r.Client.SMembers(userSessionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		ttl, err := r.Client.TTL(SessionKey(sessionID)).Result()
+		if err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			continue
+		}
+		userData, err := r.GetSession(SessionKey(sessionID))
+		if err != nil {
+			if err == redis.Nil {
+				continue
+			}
+			return err
+		}
+		for key, value := range fields {
+			userData[key] = value
+		}
+		if err := r.SetSession(SessionKey(sessionID), userData, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeSession kullanıcıya ait tek bir oturumu sonlandırır
+func (r *RedisRepository) RevokeSession(userID, sessionID string) error {
+	isMember, err := r.Client.SIsMember(userSessionsKey(userID), sessionID).Result()
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrSessionNotFound
+	}
+	return r.deleteSessions(userID, sessionID)
+}
+
+// RevokeUserSessions kullanıcının exceptSessionID dışındaki tüm oturumlarını sonlandırır
+func (r *RedisRepository) RevokeUserSessions(userID, exceptSessionID string) (int, error) {
+	sessionIDs, err := r.Client.SMembers(userSessionsKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var toRevoke []string
+	for _, sessionID := range sessionIDs {
+		if sessionID != exceptSessionID {
+			toRevoke = append(toRevoke, sessionID)
+		}
+	}
+	if len(toRevoke) == 0 {
+		return 0, nil
+	}
+	return len(toRevoke), r.deleteSessions(userID, toRevoke...)
+}
+
+func (r *RedisRepository) deleteSessions(userID string, sessionIDs ...string) error {
+	keys := make([]string, 0, len(sessionIDs)*2)
+	members := make([]interface{}, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, SessionKey(sessionID), sessionMetaKey(sessionID))
+		members = append(members, sessionID)
+	}
+
+	_, err := r.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Del(keys...)
+		pipe.SRem(userSessionsKey(userID), members...)
+		return nil
+	})
+	return err
+}
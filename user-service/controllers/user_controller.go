@@ -3,6 +3,7 @@ package controllers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
 	"github.com/MKMuhammetKaradag/go-microservice/user-service/services"
@@ -57,3 +58,28 @@ func (ctrl *UserController) User(w http.ResponseWriter, r *http.Request) {
 	})
 
 }
+
+// ListUsers yalnızca user:read-any yetkisine sahip kullanıcılar için kullanıcı listesini döner
+func (ctrl *UserController) ListUsers(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 64)
+	limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	users, err := ctrl.userService.ListUsers(page, limit)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Kullanıcılar alınamadı")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	render.JSON(w, r, map[string]interface{}{
+		"users": users,
+		"page":  page,
+		"limit": limit,
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -24,7 +25,7 @@ func main() {
 	// database.ConnectRedis()
 	database.ConnectRedis("localhost:6379", 0)
 	config := messaging.NewDefaultConfig()
-	config.RetryTypes = []string{"user_created"}
+	config.RetryTypes = []string{"user_created", "user_email_changed", "user_deleted", "data_export_requested"}
 	redisRepo := redisrepo.NewRedisRepository(database.RedisClient) // Redis repository oluşturuldu
 	rabbit, err := messaging.NewRabbitMQ(config, messaging.UserService)
 	if err != nil {
@@ -40,6 +41,15 @@ func main() {
 			fmt.Println(msg)
 			return handleUserCreated(msg)
 		}
+		if msg.Type == "user_email_changed" {
+			return handleUserEmailChanged(msg)
+		}
+		if msg.Type == "user_deleted" {
+			return handleUserDeleted(rabbit, msg)
+		}
+		if msg.Type == "data_export_requested" {
+			return handleDataExportRequested(rabbit, msg)
+		}
 		return nil
 	})
 	if err != nil {
@@ -89,3 +99,97 @@ func handleUserCreated(msg messaging.Message) error {
 	log.Printf("Yeni kullanıcı oluşturuldu: %s", user.Email)
 	return nil
 }
+
+func handleUserEmailChanged(msg messaging.Message) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("geçersiz mesaj formatı")
+	}
+
+	userID, _ := data["user_id"].(string)
+	email, emailOk := data["email"].(string)
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil || !emailOk {
+		return fmt.Errorf("geçersiz e-posta değişikliği mesajı: %+v", data)
+	}
+
+	if err := repository.UpdateUserEmail(objectID, email); err != nil {
+		return fmt.Errorf("kullanıcı e-postası güncellenemedi: %v", err)
+	}
+
+	log.Printf("Kullanıcı e-postası güncellendi: %s", userID)
+	return nil
+}
+
+// handleUserDeleted silinen hesabın userDB'deki verisini temizler ve auth-service'e onay gönderir;
+// mesaj tekrar gelirse de aynı onay gönderilir
+func handleUserDeleted(rabbit *messaging.RabbitMQ, msg messaging.Message) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("geçersiz mesaj formatı")
+	}
+
+	userID, _ := data["user_id"].(string)
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("geçersiz kullanıcı silme mesajı: %+v", data)
+	}
+
+	if err := repository.DeleteUser(objectID); err != nil {
+		return fmt.Errorf("kullanıcı silinemedi: %v", err)
+	}
+
+	ack := messaging.Message{
+		Type:      "user_deletion_acknowledged",
+		ToService: messaging.AuthService,
+		Data: map[string]interface{}{
+			"user_id": userID,
+			"service": string(messaging.UserService),
+		},
+	}
+	if err := rabbit.PublishMessage(context.Background(), ack); err != nil {
+		return fmt.Errorf("silme onayı gönderilemedi: %v", err)
+	}
+
+	log.Printf("Kullanıcı verisi silindi: %s", userID)
+	return nil
+}
+
+// handleDataExportRequested kullanıcının userDB'deki verisini dışa aktarma parçası olarak auth-service'e gönderir
+func handleDataExportRequested(rabbit *messaging.RabbitMQ, msg messaging.Message) error {
+	data, ok := msg.Data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("geçersiz mesaj formatı")
+	}
+
+	exportID, _ := data["export_id"].(string)
+	userID, _ := data["user_id"].(string)
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil || exportID == "" {
+		return fmt.Errorf("geçersiz dışa aktarma mesajı: %+v", data)
+	}
+
+	profile, err := repository.ExportUser(objectID)
+	if err != nil {
+		return fmt.Errorf("kullanıcı verisi okunamadı: %v", err)
+	}
+
+	part := messaging.Message{
+		Type:      "data_export_part",
+		ToService: messaging.AuthService,
+		Data: map[string]interface{}{
+			"export_id": exportID,
+			"user_id":   userID,
+			"service":   string(messaging.UserService),
+			"data": map[string]interface{}{
+				"profile": profile,
+			},
+		},
+	}
+	if err := rabbit.PublishMessage(context.Background(), part); err != nil {
+		return fmt.Errorf("dışa aktarma parçası gönderilemedi: %v", err)
+	}
+
+	log.Printf("Dışa aktarma parçası gönderildi: %s", exportID)
+	return nil
+}
@@ -14,7 +14,10 @@ import (
 const userDB = "userDB"
 
 func CreateUniqueIndexes(databaseName string, collectionName string) error {
-	collection := database.GetCollection(userDB, "users")
+	collection, err := database.GetCollection(userDB, "users")
+	if err != nil {
+		return err
+	}
 
 	// Email için unique index
 	emailIndex := mongo.IndexModel{
@@ -28,7 +31,7 @@ func CreateUniqueIndexes(databaseName string, collectionName string) error {
 		Options: options.Index().SetUnique(true),
 	}
 
-	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{emailIndex, usernameIndex})
+	_, err = collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{emailIndex, usernameIndex})
 	return err
 }
 func InitUserDatabase() {
@@ -37,7 +40,11 @@ func InitUserDatabase() {
 	fmt.Println("user servisinin koleksiyonları oluşturuldu.")
 }
 func CreateUserCollectionWithSchema() {
-	db := database.GetDatabase(userDB)
+	db, err := database.GetDatabase(userDB)
+	if err != nil {
+		fmt.Println("userDB alınamadı:", err)
+		return
+	}
 
 	userSchema := bson.M{
 		"bsonType": "object",
@@ -6,6 +6,8 @@ import (
 	"time"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/database"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -20,3 +22,31 @@ func CreateUser(user *models.User) error {
 	_, err := userCollection.InsertOne(context.Background(), user)
 	return err
 }
+
+// UpdateUserEmail auth-service'te değişen e-posta adresini kullanıcı kopyasına yansıtır
+func UpdateUserEmail(userID primitive.ObjectID, email string) error {
+	userCollection = database.MongoClient.Database("userDB").Collection("users")
+	_, err := userCollection.UpdateOne(context.Background(),
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"email": email, "updatedAt": time.Now()}},
+	)
+	return err
+}
+
+// DeleteUser auth-service'te silinen hesabın kullanıcı kopyasını kalıcı olarak siler
+func DeleteUser(userID primitive.ObjectID) error {
+	userCollection = database.MongoClient.Database("userDB").Collection("users")
+	_, err := userCollection.DeleteOne(context.Background(), bson.M{"_id": userID})
+	return err
+}
+
+// ExportUser kişisel veri dışa aktarması için kullanıcı kopyasını olduğu gibi döner; kopya yoksa nil döner
+func ExportUser(userID primitive.ObjectID) (bson.M, error) {
+	userCollection = database.MongoClient.Database("userDB").Collection("users")
+	var user bson.M
+	err := userCollection.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return user, err
+}
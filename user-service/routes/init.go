@@ -1,31 +1,32 @@
 package routes
 
 import (
-	"net/http"
+	"time"
 
+	"github.com/MKMuhammetKaradag/go-microservice/shared/authclient"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/middlewares"
+	"github.com/MKMuhammetKaradag/go-microservice/shared/models"
 	"github.com/MKMuhammetKaradag/go-microservice/shared/redisrepo"
 	"github.com/MKMuhammetKaradag/go-microservice/user-service/controllers"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
 )
 
 func CreateServer(sessionRepo *redisrepo.RedisRepository) *chi.Mux {
 	userController := controllers.NewUserController()
-	authMiddleware := middlewares.NewAuthMiddleware(sessionRepo)
+	// AUTH_MODE=introspection ile oturumlar Redis yerine auth-service'e sorulur
+	authMiddleware := middlewares.NewAuthMiddleware(authclient.NewClient(sessionRepo, authclient.ConfigFromEnv())).
+		WithImpersonationAudit(sessionRepo, "user")
+	authorizer := middlewares.NewAuthorizer(middlewares.PermissionsFilePath())
+	go authorizer.WatchConfig(10*time.Second, nil)
+
 	r := chi.NewRouter()
+	r.Use(authorizer.Middleware)
 
 	r.Route("/auth", func(r chi.Router) {
-		r.Get("/users", func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-			render.JSON(w, r, map[string]string{
-				"message": "user get",
-			})
-		})
-
 		r.Group(func(protectedRouter chi.Router) {
 			protectedRouter.Use(authMiddleware.Authenticate)
 			protectedRouter.Post("/user", userController.User)
+			protectedRouter.With(middlewares.RequirePermission(models.PermUserReadAny)).Get("/users", userController.ListUsers)
 		})
 	})
 	return r
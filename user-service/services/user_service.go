@@ -13,6 +13,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -78,3 +79,41 @@ func (s *UserService) Register(user *models.User) (*dto.UserResponse, error) {
 	}
 	return response, nil
 }
+
+// ListUsers kullanıcıları sayfalı olarak döner
+func (s *UserService) ListUsers(page, limit int64) ([]dto.UserResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().
+		SetSort(bson.M{"createdAt": -1}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+
+	cursor, err := s.collection.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.UserResponse, 0, len(users))
+	for _, user := range users {
+		item := dto.UserResponse{
+			ID:        user.ID.Hex(),
+			Username:  user.Username,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			CreatedAt: user.CreatedAt,
+		}
+		if user.Age != nil {
+			item.Age = *user.Age
+		}
+		response = append(response, item)
+	}
+	return response, nil
+}